@@ -37,6 +37,39 @@ const (
 	REDIS_COMMAND_GETSET           string = "GETSET"
 	REDIS_COMMAND_GETRANGE         string = "GETRANGE"
 	REDIS_COMMAND_STRLEN           string = "STRLEN"
+	REDIS_COMMAND_MGET             string = "MGET"
+	REDIS_COMMAND_MSET             string = "MSET"
+	REDIS_COMMAND_MSETNX           string = "MSETNX"
+	REDIS_COMMAND_GETEX            string = "GETEX"
+	REDIS_COMMAND_GETDEL           string = "GETDEL"
+	REDIS_COMMAND_LCS              string = "LCS"
+	REDIS_COMMAND_SETBIT           string = "SETBIT"
+	REDIS_COMMAND_GETBIT           string = "GETBIT"
+	REDIS_COMMAND_BITCOUNT         string = "BITCOUNT"
+	REDIS_COMMAND_BITPOS           string = "BITPOS"
+	REDIS_COMMAND_BITOP            string = "BITOP"
+	REDIS_COMMAND_BITFIELD         string = "BITFIELD"
+	REDIS_COMMAND_BITFIELD_RO      string = "BITFIELD_RO"
+	REDIS_COMMAND_PFADD            string = "PFADD"
+	REDIS_COMMAND_PFCOUNT          string = "PFCOUNT"
+	REDIS_COMMAND_PFMERGE          string = "PFMERGE"
+	REDIS_COMMAND_GEOADD           string = "GEOADD"
+	REDIS_COMMAND_GEOPOS           string = "GEOPOS"
+	REDIS_COMMAND_GEODIST          string = "GEODIST"
+	REDIS_COMMAND_GEOHASH          string = "GEOHASH"
+	REDIS_COMMAND_GEOSEARCH        string = "GEOSEARCH"
+	REDIS_COMMAND_GEOSEARCHSTORE   string = "GEOSEARCHSTORE"
+	REDIS_COMMAND_JSON_SET         string = "JSON.SET"
+	REDIS_COMMAND_JSON_GET         string = "JSON.GET"
+	REDIS_COMMAND_JSON_MGET        string = "JSON.MGET"
+	REDIS_COMMAND_JSON_DEL         string = "JSON.DEL"
+	REDIS_COMMAND_JSON_NUMINCRBY   string = "JSON.NUMINCRBY"
+	REDIS_COMMAND_JSON_ARRAPPEND   string = "JSON.ARRAPPEND"
+	REDIS_COMMAND_FT_CREATE        string = "FT.CREATE"
+	REDIS_COMMAND_FT_SEARCH        string = "FT.SEARCH"
+	REDIS_COMMAND_FT_AGGREGATE     string = "FT.AGGREGATE"
+	REDIS_COMMAND_FT_DROPINDEX     string = "FT.DROPINDEX"
+	REDIS_COMMAND_FT_INFO          string = "FT.INFO"
 	REDIS_COMMAND_LPUSH            string = "LPUSH"
 	REDIS_COMMAND_RPUSH            string = "RPUSH"
 	REDIS_COMMAND_LPOP             string = "LPOP"
@@ -46,6 +79,19 @@ const (
 	REDIS_COMMAND_LREM             string = "LREM"
 	REDIS_COMMAND_LTRIM            string = "LTRIM"
 	REDIS_COMMAND_LLEN             string = "LLEN"
+	REDIS_COMMAND_RPOP             string = "RPOP"
+	REDIS_COMMAND_LPUSHX           string = "LPUSHX"
+	REDIS_COMMAND_RPUSHX           string = "RPUSHX"
+	REDIS_COMMAND_LINSERT          string = "LINSERT"
+	REDIS_COMMAND_LPOS             string = "LPOS"
+	REDIS_COMMAND_LMOVE            string = "LMOVE"
+	REDIS_COMMAND_BLMOVE           string = "BLMOVE"
+	REDIS_COMMAND_RPOPLPUSH        string = "RPOPLPUSH"
+	REDIS_COMMAND_BRPOPLPUSH       string = "BRPOPLPUSH"
+	REDIS_COMMAND_BLPOP            string = "BLPOP"
+	REDIS_COMMAND_BRPOP            string = "BRPOP"
+	REDIS_COMMAND_LMPOP            string = "LMPOP"
+	REDIS_COMMAND_BLMPOP           string = "BLMPOP"
 	REDIS_COMMAND_HMSET            string = "HMSET"
 	REDIS_COMMAND_HGETALL          string = "HGETALL"
 	REDIS_COMMAND_HSET             string = "HSET"
@@ -60,6 +106,16 @@ const (
 	REDIS_COMMAND_HSTRLEN          string = "HSTRLEN"
 	REDIS_COMMAND_HEXISTS          string = "HEXISTS"
 	REDIS_COMMAND_HDEL             string = "HDEL"
+	REDIS_COMMAND_HRANDFIELD       string = "HRANDFIELD"
+	REDIS_COMMAND_HSCAN            string = "HSCAN"
+	REDIS_COMMAND_HEXPIRE          string = "HEXPIRE"
+	REDIS_COMMAND_HPEXPIRE         string = "HPEXPIRE"
+	REDIS_COMMAND_HTTL             string = "HTTL"
+	REDIS_COMMAND_HPTTL            string = "HPTTL"
+	REDIS_COMMAND_HPERSIST         string = "HPERSIST"
+	REDIS_COMMAND_HGETDEL          string = "HGETDEL"
+	REDIS_COMMAND_HGETEX           string = "HGETEX"
+	REDIS_COMMAND_HSETEX           string = "HSETEX"
 	REDIS_COMMAND_SADD             string = "SADD"
 	REDIS_COMMAND_SMOVE            string = "SMOVE"
 	REDIS_COMMAND_SPOP             string = "SPOP"
@@ -71,6 +127,12 @@ const (
 	REDIS_COMMAND_SUNION           string = "SUNION"
 	REDIS_COMMAND_SINTER           string = "SINTER"
 	REDIS_COMMAND_SDIFF            string = "SDIFF"
+	REDIS_COMMAND_SUNIONSTORE      string = "SUNIONSTORE"
+	REDIS_COMMAND_SINTERSTORE      string = "SINTERSTORE"
+	REDIS_COMMAND_SDIFFSTORE       string = "SDIFFSTORE"
+	REDIS_COMMAND_SINTERCARD       string = "SINTERCARD"
+	REDIS_COMMAND_SMISMEMBER       string = "SMISMEMBER"
+	REDIS_COMMAND_SSCAN            string = "SSCAN"
 	REDIS_COMMAND_ZADD             string = "ZADD"
 	REDIS_COMMAND_ZRANGE           string = "ZRANGE"
 	REDIS_COMMAND_ZRANGEBYSCORE    string = "ZRANGEBYSCORE"
@@ -84,6 +146,19 @@ const (
 	REDIS_COMMAND_ZRANK            string = "ZRANK"
 	REDIS_COMMAND_ZREVRANK         string = "ZREVRANK"
 	REDIS_COMMAND_ZCOUNT           string = "ZCOUNT"
+	REDIS_COMMAND_ZINCRBY          string = "ZINCRBY"
+	REDIS_COMMAND_ZRANGEBYLEX      string = "ZRANGEBYLEX"
+	REDIS_COMMAND_ZREVRANGEBYLEX   string = "ZREVRANGEBYLEX"
+	REDIS_COMMAND_ZPOPMIN          string = "ZPOPMIN"
+	REDIS_COMMAND_ZPOPMAX          string = "ZPOPMAX"
+	REDIS_COMMAND_BZPOPMIN         string = "BZPOPMIN"
+	REDIS_COMMAND_BZPOPMAX         string = "BZPOPMAX"
+	REDIS_COMMAND_ZUNIONSTORE      string = "ZUNIONSTORE"
+	REDIS_COMMAND_ZINTERSTORE      string = "ZINTERSTORE"
+	REDIS_COMMAND_ZDIFF            string = "ZDIFF"
+	REDIS_COMMAND_ZDIFFSTORE       string = "ZDIFFSTORE"
+	REDIS_COMMAND_ZMSCORE          string = "ZMSCORE"
+	REDIS_COMMAND_ZRANDMEMBER      string = "ZRANDMEMBER"
 	REDIS_COMMAND_WATCH            string = "WATCH"
 	REDIS_COMMAND_MULTI            string = "MULTI"
 	REDIS_COMMAND_EXEC             string = "EXEC"
@@ -93,4 +168,112 @@ const (
 	REDIS_COMMAND_FLUSHALL         string = "FLUSHALL"
 	REDIS_COMMAND_PING             string = "PING"
 	REDIS_COMMAND_AUTH             string = "AUTH"
+	REDIS_COMMAND_CLIENT           string = "CLIENT"
+	REDIS_COMMAND_SUBSCRIBE        string = "SUBSCRIBE"
+	REDIS_COMMAND_HELLO            string = "HELLO"
+	REDIS_COMMAND_SELECT           string = "SELECT"
+	REDIS_COMMAND_EVAL             string = "EVAL"
+	REDIS_COMMAND_EVALSHA          string = "EVALSHA"
+)
+
+/* ================================================================================
+ * List direction const
+ * LMOVE | BLMOVE | LMPOP | BLMPOP 的方向参数
+ * ================================================================================ */
+const (
+	REDIS_LIST_LEFT  string = "LEFT"
+	REDIS_LIST_RIGHT string = "RIGHT"
+)
+
+/* ================================================================================
+ * Bitmap const
+ * BITOP 的位运算 | BITFIELD 的溢出策略：回绕 | 饱和 | 失败（返回 nil 且不修改）
+ * ================================================================================ */
+const (
+	REDIS_BITOP_AND string = "AND"
+	REDIS_BITOP_OR  string = "OR"
+	REDIS_BITOP_XOR string = "XOR"
+	REDIS_BITOP_NOT string = "NOT"
+
+	REDIS_BITFIELD_OVERFLOW_WRAP string = "WRAP"
+	REDIS_BITFIELD_OVERFLOW_SAT  string = "SAT"
+	REDIS_BITFIELD_OVERFLOW_FAIL string = "FAIL"
+)
+
+/* ================================================================================
+ * Sorted set aggregate const
+ * ZUNIONSTORE | ZINTERSTORE 的分数聚合方式
+ * ================================================================================ */
+const (
+	REDIS_ZSET_AGGREGATE_SUM string = "SUM"
+	REDIS_ZSET_AGGREGATE_MIN string = "MIN"
+	REDIS_ZSET_AGGREGATE_MAX string = "MAX"
+)
+
+/* ================================================================================
+ * Geo const
+ * 距离单位：米 | 千米 | 英里 | 英尺
+ * GEOSEARCH 结果排序：按距离升序 | 降序，默认不排序
+ * ================================================================================ */
+const (
+	REDIS_GEO_UNIT_M  string = "m"
+	REDIS_GEO_UNIT_KM string = "km"
+	REDIS_GEO_UNIT_MI string = "mi"
+	REDIS_GEO_UNIT_FT string = "ft"
+
+	REDIS_GEO_SORT_ASC  string = "ASC"
+	REDIS_GEO_SORT_DESC string = "DESC"
+)
+
+/* ================================================================================
+ * JSON const
+ * 文档根节点路径：JSONPath | 旧式路径
+ * ================================================================================ */
+const (
+	REDIS_JSON_ROOT_PATH        string = "$"
+	REDIS_JSON_LEGACY_ROOT_PATH string = "."
+)
+
+/* ================================================================================
+ * Search const
+ * 索引字段类型：全文 | 标签 | 数值 | 地理位置 | 向量
+ * 向量索引算法：暴力检索 | HNSW 近似检索
+ * 向量距离：欧氏距离的平方 | 1 - 内积 | 1 - 余弦相似度
+ * 结果排序：升序 | 降序
+ * ================================================================================ */
+const (
+	REDIS_SEARCH_FIELD_TEXT    string = "TEXT"
+	REDIS_SEARCH_FIELD_TAG     string = "TAG"
+	REDIS_SEARCH_FIELD_NUMERIC string = "NUMERIC"
+	REDIS_SEARCH_FIELD_GEO     string = "GEO"
+	REDIS_SEARCH_FIELD_VECTOR  string = "VECTOR"
+
+	REDIS_SEARCH_VECTOR_FLAT string = "FLAT"
+	REDIS_SEARCH_VECTOR_HNSW string = "HNSW"
+
+	REDIS_SEARCH_DISTANCE_L2     string = "L2"
+	REDIS_SEARCH_DISTANCE_IP     string = "IP"
+	REDIS_SEARCH_DISTANCE_COSINE string = "COSINE"
+
+	REDIS_SEARCH_SORT_ASC  string = "ASC"
+	REDIS_SEARCH_SORT_DESC string = "DESC"
+)
+
+/* ================================================================================
+ * Leaderboard const
+ * 提交分数策略：保留最高分 | 保留最新分 | 累加
+ * 并列排名方式：竞争排名（1, 2, 2, 4） | 密集排名（1, 2, 2, 3）
+ * 时间窗口：不分窗口 | 按天 | 按周（ISO 周，周一开始）
+ * ================================================================================ */
+const (
+	REDIS_LEADERBOARD_POLICY_BEST   string = "best"
+	REDIS_LEADERBOARD_POLICY_LATEST string = "latest"
+	REDIS_LEADERBOARD_POLICY_SUM    string = "sum"
+
+	REDIS_LEADERBOARD_RANK_COMPETITION string = "competition"
+	REDIS_LEADERBOARD_RANK_DENSE       string = "dense"
+
+	REDIS_LEADERBOARD_WINDOW_NONE   string = ""
+	REDIS_LEADERBOARD_WINDOW_DAILY  string = "daily"
+	REDIS_LEADERBOARD_WINDOW_WEEKLY string = "weekly"
 )
@@ -1,8 +1,15 @@
 module github.com/sanxia/gredis
 
-go 1.14
+go 1.21
 
 require (
 	github.com/garyburd/redigo v1.6.0
 	github.com/sanxia/glib v1.0.1
 )
+
+require (
+	github.com/bitly/go-simplejson v0.5.0 // indirect
+	github.com/boombuler/barcode v1.0.0 // indirect
+	github.com/mozillazg/request v0.8.0 // indirect
+	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e // indirect
+)
@@ -1,5 +1,11 @@
 package gredis
 
+import (
+	"context"
+	"net"
+	"time"
+)
+
 /* ================================================================================
  * redis client interface
  * qq group: 582452342
@@ -31,13 +37,34 @@ type (
 		IncrByFloat(key string, value float64) (float64, error)
 		Decr(key string, stepArgs ...int) (int, error)
 		Set(key string, value interface{}, args ...int) error
-		SetNx(key string, value interface{}) error
+		SetNx(key string, value interface{}) (bool, error)
+		SetArgs(key string, value interface{}, args ...SetOption) (bool, error)
+		SetGet(key string, value interface{}, args ...SetOption) ([]byte, error)
+		MSet(values map[string]interface{}) error
+		MSetNx(values map[string]interface{}) (bool, error)
 		SetRange(key string, index int, value interface{}) error
 		Append(key string, value interface{}) error
 		Get(key string) ([]byte, error)
 		GetSet(key string, value interface{}) ([]byte, error)
+		GetEx(key string, ttl time.Duration) ([]byte, error)
+		GetDel(key string) ([]byte, error)
+		MGet(keys ...string) ([][]byte, error)
 		GetRange(key string, start, end int) ([]byte, error)
 		StrLen(key string) (int, error)
+		LCS(key1, key2 string) (string, error)
+		LCSLen(key1, key2 string) (int, error)
+
+		SetBit(key string, offset int, value int) (int, error)
+		GetBit(key string, offset int) (int, error)
+		BitCount(key string, args ...BitRangeOption) (int, error)
+		BitPos(key string, bit int, args ...BitRangeOption) (int, error)
+		BitOp(operation, destKey string, keys ...string) (int, error)
+		BitField(key string, operations ...BitFieldOperation) ([]*int64, error)
+		BitFieldRo(key string, operations ...BitFieldOperation) ([]int64, error)
+
+		PfAdd(key string, elements ...interface{}) (bool, error)
+		PfCount(keys ...string) (int, error)
+		PfMerge(destKey string, keys ...string) error
 
 		LPush(key string, value ...interface{}) error
 		RPush(key string, value ...interface{}) error
@@ -48,6 +75,23 @@ type (
 		LRem(key string, value interface{}, countArgs ...int) error
 		LTrim(key string, start, end int) error
 		LLen(key string) (int, error)
+		RPop(key string) (string, error)
+		LPopCount(key string, count int) ([]string, error)
+		RPopCount(key string, count int) ([]string, error)
+		LPushX(key string, value ...interface{}) (int, error)
+		RPushX(key string, value ...interface{}) (int, error)
+		LInsertBefore(key string, pivot, value interface{}) (int, error)
+		LInsertAfter(key string, pivot, value interface{}) (int, error)
+		LPos(key string, value interface{}, args ...LPosOption) (int, error)
+		LPosCount(key string, value interface{}, count int, args ...LPosOption) ([]int, error)
+		LMove(srcKey, destKey, srcDirection, destDirection string) (string, error)
+		BLMove(srcKey, destKey, srcDirection, destDirection string, timeout time.Duration) (string, error)
+		RPopLPush(srcKey, destKey string) (string, error)
+		BRPopLPush(srcKey, destKey string, timeout time.Duration) (string, error)
+		BLPop(timeout time.Duration, keys ...string) (string, string, error)
+		BRPop(timeout time.Duration, keys ...string) (string, string, error)
+		LMPop(direction string, count int, keys ...string) (string, []string, error)
+		BLMPop(timeout time.Duration, direction string, count int, keys ...string) (string, []string, error)
 
 		HSetData(structData interface{}, args ...interface{}) error
 		HGetData(structData interface{}, args ...interface{}) error
@@ -55,7 +99,9 @@ type (
 		HSetNx(key, field string, value interface{}) error
 		HMSet(key string, fields ...interface{}) error
 		HGet(key string, field string) (string, error)
+		HGetAll(key string) (map[string]string, error)
 		HMGet(key string, fields ...interface{}) ([]string, error)
+		HMGetValues(key string, fields ...interface{}) ([]*string, error)
 		HKeys(key string) ([]string, error)
 		HVals(key string) ([]string, error)
 		HIncrBy(key string, field string, value int) (int, error)
@@ -64,13 +110,25 @@ type (
 		HStrLen(key, field string) (int, error)
 		HExists(key, field string) (bool, error)
 		HDel(key string, fields ...interface{}) error
+		HRandField(key string, count int) ([]string, error)
+		HRandFieldWithValues(key string, count int) (map[string]string, error)
+		HScan(key string, cursor uint64, args ...ScanOption) (uint64, map[string]string, error)
+		HExpire(key string, ttl time.Duration, fields ...string) ([]int, error)
+		HPexpire(key string, ttl time.Duration, fields ...string) ([]int, error)
+		HTtl(key string, fields ...string) ([]int, error)
+		HPttl(key string, fields ...string) ([]int, error)
+		HPersist(key string, fields ...string) ([]int, error)
+		HGetDel(key string, fields ...string) ([]*string, error)
+		HGetEx(key string, ttl time.Duration, fields ...string) ([]*string, error)
+		HSetEx(key string, ttl time.Duration, fields ...interface{}) (bool, error)
 
-		SAdd(key string, values ...interface{}) error
-		SMove(srcKkey, desKey string, values ...interface{}) error
+		SAdd(key string, values ...interface{}) (int, error)
+		SMove(srcKey, destKey string, value interface{}) (bool, error)
 		SPop(key string, countArgs ...int) ([]string, error)
-		SRem(key string, values ...interface{}) error
+		SRem(key string, values ...interface{}) (int, error)
 		SCard(key string) (int, error)
 		SIsMemeber(key string, value interface{}) (bool, error)
+		SMIsMember(key string, values ...interface{}) ([]bool, error)
 		SMembers(key string) ([]string, error)
 		SMembersInt(key string) ([]int, error)
 		SMembersInt64(key string) ([]int64, error)
@@ -80,14 +138,19 @@ type (
 		SRandMembersInt64(key string, countArgs ...int) ([]int64, error)
 		SUnion(keys ...string) ([]string, error)
 		SUnionInt(keys ...string) ([]int, error)
+		SUnionStore(destKey string, keys ...string) (int, error)
 		SInter(keys ...string) ([]string, error)
 		SInterInt(keys ...string) ([]int, error)
+		SInterStore(destKey string, keys ...string) (int, error)
+		SInterCard(limit int, keys ...string) (int, error)
 		SDiff(keys ...string) ([]string, error)
 		SDiffInt(keys ...string) ([]int, error)
+		SDiffStore(destKey string, keys ...string) (int, error)
+		SScan(key string, cursor uint64, args ...ScanOption) (uint64, []string, error)
 
 		ZAdd(key string, members ...interface{}) error
 		ZRange(key string, start, end int) ([]string, error)
-		ZRangeWithScore(key string, start, end int) (map[string]int, error)
+		ZRangeWithScore(key string, start, end int) ([]ZMember, error)
 		ZRangeByScore(key string, min, max interface{}, limitArgs ...int) ([]string, error)
 		ZRevRange(key string, start, end int) ([]string, error)
 		ZRevRangeByScore(key string, min, max interface{}, limitArgs ...int) ([]string, error)
@@ -95,16 +158,327 @@ type (
 		ZRemRangeByScore(key string, min, max interface{}) error
 		ZRemRangeByRank(key string, start, end int) error
 		ZCard(key string) (int, error)
-		ZScore(key string, member interface{}) (int, error)
+		ZScore(key string, member interface{}) (float64, error)
 		ZRank(key string, member interface{}) (int, error)
 		ZRevRank(key string, member interface{}) (int, error)
 		ZCount(key string, min, max interface{}) (int, error)
+		ZAddMembers(key string, members []ZMember, args ...ZAddOption) (int, error)
+		ZAddIncr(key string, member interface{}, increment float64, args ...ZAddOption) (float64, error)
+		ZIncrBy(key string, member interface{}, increment float64) (float64, error)
+		ZMScore(key string, members ...interface{}) ([]float64, error)
+		ZRangeArgs(key string, start, stop interface{}, args ...ZRangeOption) ([]string, error)
+		ZRangeArgsWithScores(key string, start, stop interface{}, args ...ZRangeOption) ([]ZMember, error)
+		ZRangeByLex(key string, min, max string, limitArgs ...int) ([]string, error)
+		ZRevRangeByLex(key string, min, max string, limitArgs ...int) ([]string, error)
+		ZPopMin(key string, countArgs ...int) ([]ZMember, error)
+		ZPopMax(key string, countArgs ...int) ([]ZMember, error)
+		BZPopMin(timeout time.Duration, keys ...string) (string, ZMember, error)
+		BZPopMax(timeout time.Duration, keys ...string) (string, ZMember, error)
+		ZUnionStore(destKey string, keys []string, args ...ZStoreOption) (int, error)
+		ZInterStore(destKey string, keys []string, args ...ZStoreOption) (int, error)
+		ZDiffStore(destKey string, keys ...string) (int, error)
+		ZDiff(keys ...string) ([]string, error)
+		ZDiffWithScores(keys ...string) ([]ZMember, error)
+		ZRandMember(key string, count int) ([]string, error)
+		ZRandMemberWithScores(key string, count int) ([]ZMember, error)
+
+		GeoAdd(key string, locations []GeoLocation, args ...GeoAddOption) (int, error)
+		GeoPos(key string, members ...string) ([]*GeoLocation, error)
+		GeoDist(key, member1, member2 string, unitArgs ...string) (float64, error)
+		GeoHash(key string, members ...string) ([]string, error)
+		GeoSearch(key string, query GeoSearchQuery) ([]GeoLocation, error)
+		GeoSearchStore(destKey, key string, query GeoSearchQuery) (int, error)
+
+		JSONSet(key, path string, value interface{}, args ...JSONSetOption) (bool, error)
+		JSONGet(key string, paths ...string) ([]byte, error)
+		JSONGetData(key, path string, structData interface{}) error
+		JSONMGet(path string, keys ...string) ([][]byte, error)
+		JSONDel(key string, pathArgs ...string) (int, error)
+		JSONNumIncrBy(key, path string, value float64) ([]*float64, error)
+		JSONArrAppend(key, path string, values ...interface{}) ([]*int64, error)
+
+		FTCreate(index string, fields []SearchField, args ...SearchIndexOption) error
+		FTDropIndex(index string, deleteArgs ...bool) error
+		FTInfo(index string) (SearchIndexInfo, error)
+		FTSearch(index string, query *SearchQuery) (SearchResult, error)
+		FTAggregate(index string, query *AggregateQuery) ([]map[string]string, error)
 
 		Pipeline(commands []map[string][]interface{}, watchKeys ...interface{}) (interface{}, error)
+		Do(commandName string, args ...interface{}) (interface{}, error)
+		Eval(script *Script, keys []string, args ...interface{}) (interface{}, error)
+		AddHook(hook Hook)
+		WithContext(ctx context.Context) IRedis
 
 		SelectDb(index int) error
 		BgSave() error
 		FlushDb(index int) error
 		FlushAll() error
+
+		CacheStats() CacheStats
+		PoolStats() PoolStats
+		Option() RedisOption
+		Close() error
+	}
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * Redis客户端选项
+	 * Timeout: 连接、读、写超时（单位秒）
+	 * MaxIdle | MaxActive | IdleTimeout | Wait: 连接池选项，MaxActive为0时不限制连接数，
+	 * 达到上限时Wait为true则等待空闲连接，否则返回 ErrPoolExhausted
+	 * Protocol: 2 | 3，为3时通过 HELLO 3 协商，服务器不支持时回退到 RESP2
+	 * PushHandler: RESP3 推送消息处理函数
+	 * Retry: 瞬时故障重试策略，为nil时不重试
+	 * Breaker: 熔断器选项，为nil时不启用熔断
+	 * Hooks: 命令、管道及拨号钩子
+	 * SlowLog: 慢命令日志选项，为nil时不记录
+	 * Cache: 本地缓存选项，为nil时不启用本地缓存
+	 * NetDial: 自定义网络连接函数，为nil时使用 TCP 连接 Ip:Port
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	RedisOption struct {
+		Ip          string
+		Port        int
+		Username    string
+		Password    string
+		ClientName  string
+		Db          int
+		Timeout     int
+		Prefix      string
+		MaxIdle     int
+		MaxActive   int
+		IdleTimeout int
+		Wait        bool
+		Protocol    int
+		PushHandler func(message PushMessage)
+		Retry       *RetryPolicy
+		Breaker     *BreakerOption
+		Hooks       []Hook
+		SlowLog     *SlowLogOption
+		Cache       *CacheOption
+		NetDial     func(network, address string) (net.Conn, error)
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * LPOS 选项
+	 * Rank: 返回第 Rank 个匹配，负数时从尾部查找，0 时使用默认值 1
+	 * MaxLen: 最多比较的元素个数，0 表示不限制
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	LPosOption struct {
+		Rank   int
+		MaxLen int
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * SET 选项
+	 * Nx: 仅当键不存在时设置 | Xx: 仅当键存在时设置
+	 * KeepTtl: 保留键原有的过期时间
+	 * Ttl: 过期时长，整秒时使用 EX，否则使用 PX，大于0且不足1毫秒时返回 ErrInvalidTtl
+	 * ExpireAt: 过期时间点，整秒时使用 EXAT，否则使用 PXAT，优先于 Ttl
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SetOption struct {
+		Nx       bool
+		Xx       bool
+		KeepTtl  bool
+		Ttl      time.Duration
+		ExpireAt time.Time
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * BITCOUNT | BITPOS 区间选项
+	 * Start | End: 起止位置（闭区间），支持负数索引，-1 表示末尾
+	 * Bit: 按位计算区间（Redis 7.0+），默认按字节
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	BitRangeOption struct {
+		Start int
+		End   int
+		Bit   bool
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * BITFIELD 子命令，通过 BitFieldGet | BitFieldSet | BitFieldIncrBy | BitFieldOverflow 构造
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	BitFieldOperation struct {
+		args []interface{}
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * SCAN 系列命令选项
+	 * Match: glob 风格的匹配模式，为空时不过滤
+	 * Count: 每次迭代的参考数量，为0时使用服务器默认值
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ScanOption struct {
+		Match string
+		Count int
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 有序集合成员
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ZMember struct {
+		Member string
+		Score  float64
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * ZADD 选项
+	 * Nx: 仅添加新成员 | Xx: 仅更新已有成员
+	 * Gt | Lt: 仅当新分数大于 | 小于当前分数时更新，不影响新成员的添加
+	 * Ch: 返回新增及分数变化的成员数，默认仅返回新增成员数
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ZAddOption struct {
+		Nx bool
+		Xx bool
+		Gt bool
+		Lt bool
+		Ch bool
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * ZRANGE 选项
+	 * ByScore: 按分数区间（-inf | +inf | (score 开区间） | ByLex: 按字典序区间（- | + | [member | (member）
+	 * 默认按排名区间，Rev 为 true 时逆序，且按区间查询时 start 为最大值、stop 为最小值
+	 * Offset | Count: 仅用于 ByScore | ByLex，Count 为0时不限制数量
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ZRangeOption struct {
+		ByScore bool
+		ByLex   bool
+		Rev     bool
+		Offset  int
+		Count   int
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * ZUNIONSTORE | ZINTERSTORE 选项
+	 * Weights: 各 Key 分数的乘数，为空时均为1
+	 * Aggregate: REDIS_ZSET_AGGREGATE_SUM | MIN | MAX，为空时为 SUM
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ZStoreOption struct {
+		Weights   []float64
+		Aggregate string
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 地理位置，既是 GeoAdd 的输入，也是 GeoPos | GeoSearch 的结果
+	 * Dist: 与查询中心的距离，单位同查询，仅 GeoSearch 指定 WithDist 时有值
+	 * Hash: 52 位 geohash 整数，仅 GeoSearch 指定 WithHash 时有值
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	GeoLocation struct {
+		Name      string
+		Longitude float64
+		Latitude  float64
+		Dist      float64
+		Hash      int64
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * GEOADD 选项
+	 * Nx: 仅添加新成员 | Xx: 仅更新已有成员
+	 * Ch: 返回新增及坐标变化的成员数，默认仅返回新增成员数
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	GeoAddOption struct {
+		Nx bool
+		Xx bool
+		Ch bool
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * GEOSEARCH | GEOSEARCHSTORE 查询
+	 * Member: 以成员位置为中心，为空时以 Longitude | Latitude 为中心
+	 * Radius: 按半径查询，为0时按 Width | Height 矩形查询
+	 * Unit: 距离单位 REDIS_GEO_UNIT_*，默认米
+	 * Sort: 按距离排序 REDIS_GEO_SORT_*，默认不排序（指定 Count 且非 Any 时升序）
+	 * Count: 最多返回的成员数，为0时不限制 | Any: 找到 Count 个即返回，不保证最近
+	 * WithCoord | WithDist | WithHash: 结果附带坐标 | 距离 | geohash，仅用于 GeoSearch
+	 * StoreDist: 以距离作为分数存储，默认存储 geohash，仅用于 GeoSearchStore
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	GeoSearchQuery struct {
+		Member    string
+		Longitude float64
+		Latitude  float64
+		Radius    float64
+		Width     float64
+		Height    float64
+		Unit      string
+		Sort      string
+		Count     int
+		Any       bool
+		WithCoord bool
+		WithDist  bool
+		WithHash  bool
+		StoreDist bool
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * JSON.SET 选项
+	 * Nx: 仅路径不存在时设置 | Xx: 仅路径已存在时设置
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	JSONSetOption struct {
+		Nx bool
+		Xx bool
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 搜索索引字段，可由 SearchSchema 从结构体标签推导
+	 * Type: REDIS_SEARCH_FIELD_*
+	 * Weight: 文本字段的相关度权重，默认1 | NoStem: 文本字段不做词干提取
+	 * Separator: 标签分隔符，默认逗号 | CaseSensitive: 标签区分大小写
+	 * Algorithm: 向量索引算法 REDIS_SEARCH_VECTOR_* | Dim: 向量维度（FLOAT32）
+	 * Distance: 向量距离 REDIS_SEARCH_DISTANCE_*
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SearchField struct {
+		Name          string
+		Type          string
+		Sortable      bool
+		NoIndex       bool
+		NoStem        bool
+		Weight        float64
+		Separator     string
+		CaseSensitive bool
+		Algorithm     string
+		Dim           int
+		Distance      string
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * FT.CREATE 选项，索引哈希文档
+	 * Prefixes: 文档Key前缀（不含客户端前缀），为空时索引客户端前缀下的全部哈希
+	 * Language: 词干提取使用的语言，默认英语
+	 * Stopwords: 停用词，为 nil 时使用默认停用词，为空切片时不使用停用词
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SearchIndexOption struct {
+		Prefixes  []string
+		Language  string
+		Stopwords []string
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * FT.INFO 结果，Name | Prefixes 不含客户端前缀
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SearchIndexInfo struct {
+		Name     string
+		Prefixes []string
+		Fields   []SearchField
+		NumDocs  int
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * FT.SEARCH 结果，Total 为匹配的文档总数（不受分页限制）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SearchResult struct {
+		Total     int
+		Documents []SearchDocument
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 搜索结果文档
+	 * Id: 文档Key（不含客户端前缀）
+	 * Score: 相关度分数，仅查询指定 WithScores 时有值
+	 * Fields: 文档字段，KNN 查询时包含距离字段
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SearchDocument struct {
+		Id     string
+		Score  float64
+		Fields map[string]string
 	}
 )
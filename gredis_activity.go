@@ -0,0 +1,144 @@
+package gredis
+
+import (
+	"time"
+)
+
+/* ================================================================================
+ * Activity
+ * 基于位图的按天活跃统计，用户 id 作为位偏移，适用于日活、留存等场景
+ * 键（均在客户端前缀之下）：
+ * {name}:{yyyyMMdd} 当日活跃位图 | {name}:tmp 区间统计使用的临时位图，脚本内即删
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+var (
+	activityMarkScript = NewScript(`
+local previous = redis.call('SETBIT', KEYS[1], ARGV[1], 1)
+if ARGV[2] ~= '0' then
+	redis.call('PEXPIREAT', KEYS[1], ARGV[2])
+end
+return previous
+`)
+
+	// KEYS[1] 为临时位图，KEYS[2..] 为参与运算的日位图
+	activityRangeScript = NewScript(`
+redis.call('BITOP', ARGV[1], KEYS[1], unpack(KEYS, 2))
+local count = redis.call('BITCOUNT', KEYS[1])
+redis.call('DEL', KEYS[1])
+return count
+`)
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 活跃统计选项
+	 * Retention: 日位图在当天结束后保留的天数，为0时不过期
+	 * Location: 划分日期使用的时区，默认 time.Local
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ActivityOption struct {
+		Retention int
+		Location  *time.Location
+	}
+
+	Activity struct {
+		redis  IRedis
+		name   string
+		option ActivityOption
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化活跃统计
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewActivity(redis IRedis, name string, args ...ActivityOption) *Activity {
+	option := ActivityOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	if option.Location == nil {
+		option.Location = time.Local
+	}
+
+	return &Activity{
+		redis:  redis,
+		name:   name,
+		option: option,
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取 t 所在日期位图的Key（不含客户端前缀）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *Activity) Key(t time.Time) string {
+	return a.name + ":" + a.day(t).Format("20060102")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 标记用户在 t 所在日期活跃，返回当日此前是否已活跃
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *Activity) Mark(id int, t time.Time) (bool, error) {
+	expireAtMilli := int64(0)
+	if a.option.Retention > 0 {
+		expireAtMilli = a.day(t).AddDate(0, 0, 1+a.option.Retention).UnixMilli()
+	}
+
+	return replyBool(a.redis.Eval(activityMarkScript, []string{a.Key(t)}, id, expireAtMilli))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 判断用户在 t 所在日期是否活跃
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *Activity) IsActive(id int, t time.Time) (bool, error) {
+	bit, err := a.redis.GetBit(a.Key(t), id)
+	return bit == 1, err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取 t 所在日期的活跃用户数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *Activity) Count(t time.Time) (int, error) {
+	return a.redis.BitCount(a.Key(t))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取 from 至 to（含）期间至少活跃一天的用户数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *Activity) CountAny(from, to time.Time) (int, error) {
+	return a.countRange(REDIS_BITOP_OR, from, to)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取 from 至 to（含）期间每天都活跃的用户数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *Activity) CountAll(from, to time.Time) (int, error) {
+	return a.countRange(REDIS_BITOP_AND, from, to)
+}
+
+func (a *Activity) countRange(operation string, from, to time.Time) (int, error) {
+	first, last := a.day(from), a.day(to)
+	if first.After(last) {
+		return 0, nil
+	}
+
+	keys := []string{a.name + ":tmp"}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		keys = append(keys, a.Key(day))
+	}
+
+	return replyInt(a.redis.Eval(activityRangeScript, keys, operation))
+}
+
+func (a *Activity) day(t time.Time) time.Time {
+	return startOfDay(t, a.option.Location)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * t 在 location 时区中当天的零点
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func startOfDay(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
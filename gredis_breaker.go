@@ -0,0 +1,237 @@
+package gredis
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+/* ================================================================================
+ * Redis circuit breaker
+ * 连续失败或失败率超限时熔断，熔断期间快速失败，超时后以 PING 探测恢复
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	BREAKER_STATE_CLOSED BreakerState = iota
+	BREAKER_STATE_OPEN
+	BREAKER_STATE_HALF_OPEN
+)
+
+const (
+	breakerDefaultConsecutiveFailures int           = 5
+	breakerDefaultMinRequests         int           = 20
+	breakerDefaultWindow              time.Duration = 10 * time.Second
+	breakerDefaultOpenTimeout         time.Duration = 5 * time.Second
+)
+
+var (
+	ErrCircuitOpen = errors.New("gredis: circuit breaker is open")
+)
+
+type (
+	BreakerState int
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 熔断器选项
+	 * ConsecutiveFailures: 连续失败次数阈值
+	 * FailureRatio: 统计窗口内的失败率阈值，0表示不按失败率熔断
+	 * MinRequests | Window: 失败率统计的最小请求数及窗口时长
+	 * OpenTimeout: 熔断持续时间，到期后进入半开状态并发送 PING 探测
+	 * OnStateChange: 状态变化回调
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	BreakerOption struct {
+		ConsecutiveFailures int
+		FailureRatio        float64
+		MinRequests         int
+		Window              time.Duration
+		OpenTimeout         time.Duration
+		OnStateChange       func(from, to BreakerState)
+	}
+
+	circuitBreaker struct {
+		option BreakerOption
+
+		mu                  sync.Mutex
+		state               BreakerState
+		openedAt            time.Time
+		windowStart         time.Time
+		requests            int
+		failures            int
+		consecutiveFailures int
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化熔断器
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func newCircuitBreaker(option BreakerOption) *circuitBreaker {
+	if option.ConsecutiveFailures <= 0 {
+		option.ConsecutiveFailures = breakerDefaultConsecutiveFailures
+	}
+
+	if option.MinRequests <= 0 {
+		option.MinRequests = breakerDefaultMinRequests
+	}
+
+	if option.Window <= 0 {
+		option.Window = breakerDefaultWindow
+	}
+
+	if option.OpenTimeout <= 0 {
+		option.OpenTimeout = breakerDefaultOpenTimeout
+	}
+
+	return &circuitBreaker{
+		option:      option,
+		windowStart: time.Now(),
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 判断是否允许执行命令
+ * 熔断超时后由首个调用方执行探测，探测期间其余调用快速失败
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (b *circuitBreaker) allow(probe func() error) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+
+	if b.state == BREAKER_STATE_CLOSED {
+		b.mu.Unlock()
+		return nil
+	}
+
+	if b.state == BREAKER_STATE_HALF_OPEN || time.Since(b.openedAt) < b.option.OpenTimeout {
+		b.mu.Unlock()
+		return ErrCircuitOpen
+	}
+
+	from := b.setState(BREAKER_STATE_HALF_OPEN)
+	b.mu.Unlock()
+	b.notify(from, BREAKER_STATE_HALF_OPEN)
+
+	if err := probe(); isBreakerFailure(err) {
+		b.mu.Lock()
+		from = b.trip()
+		b.mu.Unlock()
+		b.notify(from, BREAKER_STATE_OPEN)
+
+		return ErrCircuitOpen
+	}
+
+	b.mu.Lock()
+	from = b.setState(BREAKER_STATE_CLOSED)
+	b.mu.Unlock()
+	b.notify(from, BREAKER_STATE_CLOSED)
+
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 记录命令执行结果，仅连接类及服务不可用类错误计为失败，
+ * 客户端本地错误及槽迁移错误既不计为失败也不计为成功
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (b *circuitBreaker) report(err error) {
+	if b == nil || isBreakerIgnored(err) {
+		return
+	}
+
+	isFailure := isBreakerFailure(err)
+
+	b.mu.Lock()
+
+	if b.state != BREAKER_STATE_CLOSED {
+		b.mu.Unlock()
+		return
+	}
+
+	if time.Since(b.windowStart) > b.option.Window {
+		b.windowStart = time.Now()
+		b.requests = 0
+		b.failures = 0
+	}
+
+	b.requests++
+
+	if !isFailure {
+		b.consecutiveFailures = 0
+		b.mu.Unlock()
+		return
+	}
+
+	b.failures++
+	b.consecutiveFailures++
+
+	isTripped := b.consecutiveFailures >= b.option.ConsecutiveFailures
+	if b.option.FailureRatio > 0 && b.requests >= b.option.MinRequests {
+		isTripped = isTripped || float64(b.failures)/float64(b.requests) >= b.option.FailureRatio
+	}
+
+	if !isTripped {
+		b.mu.Unlock()
+		return
+	}
+
+	from := b.trip()
+	b.mu.Unlock()
+	b.notify(from, BREAKER_STATE_OPEN)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 是否计为熔断失败
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func isBreakerFailure(err error) bool {
+	return err != nil && !isBreakerIgnored(err) && isRetryableError(err)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 不反映服务器健康状况的错误：连接池耗尽为客户端本地限制，
+ * TRYAGAIN | MOVED | ASK 为集群槽迁移
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func isBreakerIgnored(err error) bool {
+	return errors.Is(err, ErrPoolExhausted) ||
+		errors.Is(err, ErrTryAgain) ||
+		errors.Is(err, ErrMoved) ||
+		errors.Is(err, ErrAsk)
+}
+
+func (b *circuitBreaker) trip() BreakerState {
+	b.openedAt = time.Now()
+
+	return b.setState(BREAKER_STATE_OPEN)
+}
+
+func (b *circuitBreaker) setState(state BreakerState) BreakerState {
+	from := b.state
+	b.state = state
+
+	b.windowStart = time.Now()
+	b.requests = 0
+	b.failures = 0
+	b.consecutiveFailures = 0
+
+	return from
+}
+
+func (b *circuitBreaker) notify(from, to BreakerState) {
+	if from != to && b.option.OnStateChange != nil {
+		b.option.OnStateChange(from, to)
+	}
+}
+
+func (state BreakerState) String() string {
+	switch state {
+	case BREAKER_STATE_CLOSED:
+		return "closed"
+	case BREAKER_STATE_OPEN:
+		return "open"
+	case BREAKER_STATE_HALF_OPEN:
+		return "half-open"
+	}
+
+	return "unknown"
+}
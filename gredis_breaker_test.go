@@ -0,0 +1,38 @@
+package gredis
+
+import (
+	"io"
+	"testing"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+func TestBreakerFailureClassification(t *testing.T) {
+	breaker := newCircuitBreaker(BreakerOption{ConsecutiveFailures: 2})
+
+	ignored := []error{
+		newRedisError("GET", []interface{}{"key"}, redis_go.ErrPoolExhausted),
+		newRedisError("GET", []interface{}{"key"}, redis_go.Error("TRYAGAIN Multiple keys request during rehashing of slot")),
+		newRedisError("GET", []interface{}{"key"}, redis_go.Error("MOVED 3999 127.0.0.1:6381")),
+	}
+
+	for index := 0; index < 3; index++ {
+		for _, err := range ignored {
+			breaker.report(err)
+		}
+	}
+
+	if breaker.state != BREAKER_STATE_CLOSED || breaker.requests != 0 {
+		t.Fatalf("state after client-side errors = %v, requests = %d", breaker.state, breaker.requests)
+	}
+
+	breaker.report(newRedisError("GET", []interface{}{"key"}, io.EOF))
+	breaker.report(ignored[0])
+	breaker.report(newRedisError("GET", []interface{}{"key"}, io.EOF))
+
+	if breaker.state != BREAKER_STATE_OPEN {
+		t.Errorf("state after connection errors = %v, want open", breaker.state)
+	}
+}
@@ -0,0 +1,623 @@
+package gredis
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ================================================================================
+ * Redis client side cache
+ * 本地缓存层：Get/HGet/GetData 优先读取进程内缓存，
+ * 通过 CLIENT TRACKING BCAST 接收失效通知：RESP3 使用推送消息，
+ * RESP2 使用 REDIRECT 到独立的订阅连接
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	CACHE_POLICY_LRU CachePolicy = iota
+	CACHE_POLICY_LFU
+)
+
+const (
+	cacheDefaultMaxEntries  int           = 10000
+	cacheDefaultPingPeriod  time.Duration = 10 * time.Second
+	cacheEntryOverhead      int64         = 96
+	cacheMinRetryDelay      time.Duration = time.Second
+	cacheMaxRetryDelay      time.Duration = 30 * time.Second
+	cacheInvalidateMessage  string        = "message"
+	cacheInvalidatePush     string        = "invalidate"
+	cacheSubscribeMessage   string        = "subscribe"
+	cacheInvalidateChannel  string        = "__redis__:invalidate"
+	cacheTrackingSubcommand string        = "TRACKING"
+	cacheClientIdSubcommand string        = "ID"
+)
+
+type (
+	CachePolicy int
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 本地缓存选项
+	 * Policy: 淘汰策略 LRU | LFU
+	 * MaxEntries | MaxBytes: 条目数及内存预算，均为0时默认10000条
+	 * Ttl: 条目默认存活时间，KeyTtl 可按Key覆盖，0表示仅依赖失效通知
+	 * Prefixes: 订阅失效通知的Key前缀（自动附加客户端前缀）
+	 * PingPeriod: 失效通知连接的健康检查周期
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	CacheOption struct {
+		Policy     CachePolicy
+		MaxEntries int
+		MaxBytes   int64
+		Ttl        time.Duration
+		KeyTtl     func(key string) time.Duration
+		Prefixes   []string
+		PingPeriod time.Duration
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 本地缓存统计
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	CacheStats struct {
+		Hits          uint64
+		Misses        uint64
+		Evictions     uint64
+		Expirations   uint64
+		Invalidations uint64
+		Entries       int
+		Bytes         int64
+		Tracking      bool
+	}
+
+	localCache struct {
+		option    CacheOption
+		prefixes  []string
+		dialFunc  func() (redis_go.Conn, error)
+		closed    chan struct{}
+		closeOnce sync.Once
+
+		mu       sync.Mutex
+		entries  map[string]map[string]*cacheEntry
+		queue    *cacheQueue
+		bytes    int64
+		tick     uint64
+		fetching map[string]*cacheFetch
+
+		tracking      int32
+		hits          uint64
+		misses        uint64
+		evictions     uint64
+		expirations   uint64
+		invalidations uint64
+	}
+
+	cacheEntry struct {
+		key       string
+		field     string
+		value     []byte
+		size      int64
+		expiresAt time.Time
+		frequency uint64
+		tick      uint64
+		index     int
+	}
+
+	cacheFetch struct {
+		refs  int
+		stale bool
+	}
+
+	cacheQueue struct {
+		policy  CachePolicy
+		entries []*cacheEntry
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化本地缓存并启动失效通知跟踪
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func newLocalCache(option CacheOption, prefixKey string, dialFunc func() (redis_go.Conn, error)) *localCache {
+	if option.MaxEntries <= 0 && option.MaxBytes <= 0 {
+		option.MaxEntries = cacheDefaultMaxEntries
+	}
+
+	if option.PingPeriod <= 0 {
+		option.PingPeriod = cacheDefaultPingPeriod
+	}
+
+	cache := &localCache{
+		option:   option,
+		dialFunc: dialFunc,
+		closed:   make(chan struct{}),
+		entries:  make(map[string]map[string]*cacheEntry),
+		queue:    &cacheQueue{policy: option.Policy},
+		fetching: make(map[string]*cacheFetch),
+	}
+
+	if len(option.Prefixes) > 0 {
+		for _, prefix := range option.Prefixes {
+			cache.prefixes = append(cache.prefixes, prefixKey+prefix)
+		}
+	} else if prefixKey != "" {
+		cache.prefixes = []string{prefixKey}
+	}
+
+	go cache.track()
+
+	return cache
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 读取缓存，未命中时调用fetch并在未失效的前提下回填
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) load(key, fullKey, field string, fetch func() ([]byte, error)) ([]byte, error) {
+	if !c.isTracking() {
+		return fetch()
+	}
+
+	if value, isOk := c.get(fullKey, field); isOk {
+		atomic.AddUint64(&c.hits, 1)
+		return value, nil
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+
+	c.beginFetch(fullKey)
+	value, err := fetch()
+
+	var entry *cacheEntry
+	if err == nil {
+		entry = c.newEntry(key, fullKey, field, value)
+	}
+	c.endFetchAndSet(fullKey, entry)
+
+	return value, err
+}
+
+func (c *localCache) get(fullKey, field string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, isOk := c.entries[fullKey][field]
+	if !isOk {
+		return nil, false
+	}
+
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.remove(entry)
+		c.expirations++
+		return nil, false
+	}
+
+	c.tick++
+	entry.tick = c.tick
+	entry.frequency++
+	heap.Fix(c.queue, entry.index)
+
+	value := make([]byte, len(entry.value))
+	copy(value, entry.value)
+
+	return value, true
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 创建缓存条目，超出内存预算时返回nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) newEntry(key, fullKey, field string, value []byte) *cacheEntry {
+	ttl := c.option.Ttl
+	if c.option.KeyTtl != nil {
+		if keyTtl := c.option.KeyTtl(key); keyTtl > 0 {
+			ttl = keyTtl
+		}
+	}
+
+	entry := &cacheEntry{
+		key:   fullKey,
+		field: field,
+		value: make([]byte, len(value)),
+		size:  int64(len(fullKey)+len(field)+len(value)) + cacheEntryOverhead,
+	}
+	copy(entry.value, value)
+
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	if c.option.MaxBytes > 0 && entry.size > c.option.MaxBytes {
+		return nil
+	}
+
+	return entry
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 写入缓存条目，调用方需持有 c.mu
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) set(entry *cacheEntry) {
+	if oldEntry, isOk := c.entries[entry.key][entry.field]; isOk {
+		c.remove(oldEntry)
+	}
+
+	fields, isOk := c.entries[entry.key]
+	if !isOk {
+		fields = make(map[string]*cacheEntry)
+		c.entries[entry.key] = fields
+	}
+
+	c.tick++
+	entry.tick = c.tick
+	entry.frequency = 1
+	fields[entry.field] = entry
+	heap.Push(c.queue, entry)
+	c.bytes += entry.size
+
+	for c.isOverBudget() {
+		c.remove(c.queue.entries[0])
+		c.evictions++
+	}
+}
+
+func (c *localCache) isOverBudget() bool {
+	if c.queue.Len() == 0 {
+		return false
+	}
+
+	if c.option.MaxEntries > 0 && c.queue.Len() > c.option.MaxEntries {
+		return true
+	}
+
+	return c.option.MaxBytes > 0 && c.bytes > c.option.MaxBytes
+}
+
+func (c *localCache) remove(entry *cacheEntry) {
+	heap.Remove(c.queue, entry.index)
+	c.bytes -= entry.size
+
+	if fields, isOk := c.entries[entry.key]; isOk {
+		delete(fields, entry.field)
+		if len(fields) == 0 {
+			delete(c.entries, entry.key)
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 标记正在回源的Key，期间收到的失效通知会阻止回填
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) beginFetch(fullKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fetch, isOk := c.fetching[fullKey]
+	if !isOk {
+		fetch = &cacheFetch{}
+		c.fetching[fullKey] = fetch
+	}
+	fetch.refs++
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 结束回源并在同一临界区内回填，避免检查与写入之间到达的失效通知被遗漏
+ * entry 为nil时仅结束回源
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) endFetchAndSet(fullKey string, entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fetch, isOk := c.fetching[fullKey]
+	if !isOk {
+		return
+	}
+
+	fetch.refs--
+	if fetch.refs == 0 {
+		delete(c.fetching, fullKey)
+	}
+
+	if entry == nil || fetch.stale || !c.isTracking() {
+		return
+	}
+
+	c.set(entry)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 失效指定Key的全部缓存条目
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) invalidate(fullKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, entry := range c.entries[fullKey] {
+		c.remove(entry)
+	}
+
+	if fetch, isOk := c.fetching[fullKey]; isOk {
+		fetch.stale = true
+	}
+
+	c.invalidations++
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 清空全部缓存条目
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]map[string]*cacheEntry)
+	c.queue.entries = nil
+	c.bytes = 0
+
+	for _, fetch := range c.fetching {
+		fetch.stale = true
+	}
+}
+
+func (c *localCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:          atomic.LoadUint64(&c.hits),
+		Misses:        atomic.LoadUint64(&c.misses),
+		Evictions:     c.evictions,
+		Expirations:   c.expirations,
+		Invalidations: c.invalidations,
+		Entries:       c.queue.Len(),
+		Bytes:         c.bytes,
+		Tracking:      c.isTracking(),
+	}
+}
+
+func (c *localCache) isTracking() bool {
+	return atomic.LoadInt32(&c.tracking) == 1
+}
+
+func (c *localCache) setTracking(isTracking bool) {
+	if isTracking {
+		atomic.StoreInt32(&c.tracking, 1)
+	} else {
+		atomic.StoreInt32(&c.tracking, 0)
+	}
+}
+
+func (c *localCache) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 失效通知跟踪循环，断线后清空缓存并退避重连
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) track() {
+	delay := cacheMinRetryDelay
+
+	for {
+		startTime := time.Now()
+		c.subscribe()
+
+		c.setTracking(false)
+		c.flush()
+
+		if time.Since(startTime) > cacheMaxRetryDelay {
+			delay = cacheMinRetryDelay
+		}
+
+		select {
+		case <-c.closed:
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > cacheMaxRetryDelay {
+			delay = cacheMaxRetryDelay
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 建立订阅连接与跟踪连接，并阻塞接收失效通知
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) subscribe() error {
+	subConn, err := c.dialFunc()
+	if err != nil {
+		return err
+	}
+	defer subConn.Close()
+
+	if pushConn, isOk := subConn.(*resp3Conn); isOk {
+		return c.subscribePush(pushConn)
+	}
+
+	clientId, err := redis_go.Int64(subConn.Do(REDIS_COMMAND_CLIENT, cacheClientIdSubcommand))
+	if err != nil {
+		return err
+	}
+
+	trackConn, err := c.dialFunc()
+	if err != nil {
+		return err
+	}
+	defer trackConn.Close()
+
+	args := redis_go.Args{}.Add(cacheTrackingSubcommand, "ON", "REDIRECT", clientId, "BCAST")
+	for _, prefix := range c.prefixes {
+		args = args.Add("PREFIX", prefix)
+	}
+
+	if _, err := trackConn.Do(REDIS_COMMAND_CLIENT, args...); err != nil {
+		return err
+	}
+
+	if err := subConn.Send(REDIS_COMMAND_SUBSCRIBE, cacheInvalidateChannel); err != nil {
+		return err
+	}
+
+	if err := subConn.Flush(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go c.ping(subConn, trackConn, done)
+
+	for {
+		reply, err := redis_go.ReceiveWithTimeout(subConn, 2*c.option.PingPeriod)
+		if err != nil {
+			return err
+		}
+
+		c.receive(reply)
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * RESP3 连接直接开启跟踪，失效通知以 invalidate 推送消息送达同一连接
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) subscribePush(conn *resp3Conn) error {
+	args := redis_go.Args{}.Add(cacheTrackingSubcommand, "ON", "BCAST")
+	for _, prefix := range c.prefixes {
+		args = args.Add("PREFIX", prefix)
+	}
+
+	if _, err := conn.Do(REDIS_COMMAND_CLIENT, args...); err != nil {
+		return err
+	}
+
+	c.flush()
+	c.setTracking(true)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go c.ping(conn, nil, done)
+
+	for {
+		reply, err := conn.ReceiveWithTimeout(2 * c.option.PingPeriod)
+		if err != nil {
+			return err
+		}
+
+		if message, isOk := reply.(PushMessage); isOk && message.Kind == cacheInvalidatePush && len(message.Data) > 0 {
+			c.receiveInvalidation(message.Data[0])
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 定期检查订阅连接和跟踪连接，任一失败即中断订阅
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) ping(subConn, trackConn redis_go.Conn, done chan struct{}) {
+	ticker := time.NewTicker(c.option.PingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-c.closed:
+			subConn.Close()
+			return
+		case <-ticker.C:
+			if trackConn != nil {
+				if _, err := trackConn.Do(REDIS_COMMAND_PING); err != nil {
+					subConn.Close()
+					return
+				}
+			}
+
+			err := subConn.Send(REDIS_COMMAND_PING)
+			if err == nil {
+				err = subConn.Flush()
+			}
+
+			if err != nil {
+				subConn.Close()
+				return
+			}
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 处理订阅消息，消息体为失效Key数组，nil表示FLUSHDB/FLUSHALL
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *localCache) receive(reply interface{}) {
+	values, isOk := reply.([]interface{})
+	if !isOk || len(values) < 3 {
+		return
+	}
+
+	kind, _ := redis_go.String(values[0], nil)
+
+	switch kind {
+	case cacheSubscribeMessage:
+		c.flush()
+		c.setTracking(true)
+	case cacheInvalidateMessage:
+		c.receiveInvalidation(values[2])
+	}
+}
+
+func (c *localCache) receiveInvalidation(payload interface{}) {
+	switch payload := payload.(type) {
+	case nil:
+		c.flush()
+	case []byte:
+		c.invalidate(string(payload))
+	case []interface{}:
+		for _, key := range payload {
+			if fullKey, err := redis_go.String(key, nil); err == nil {
+				c.invalidate(fullKey)
+			}
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 淘汰队列（最小堆）：LRU按最近访问排序，LFU按访问频次排序
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *cacheQueue) Len() int {
+	return len(q.entries)
+}
+
+func (q *cacheQueue) Less(i, j int) bool {
+	a, b := q.entries[i], q.entries[j]
+
+	if q.policy == CACHE_POLICY_LFU && a.frequency != b.frequency {
+		return a.frequency < b.frequency
+	}
+
+	return a.tick < b.tick
+}
+
+func (q *cacheQueue) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.entries[i].index = i
+	q.entries[j].index = j
+}
+
+func (q *cacheQueue) Push(x interface{}) {
+	entry := x.(*cacheEntry)
+	entry.index = len(q.entries)
+	q.entries = append(q.entries, entry)
+}
+
+func (q *cacheQueue) Pop() interface{} {
+	n := len(q.entries)
+	entry := q.entries[n-1]
+	q.entries[n-1] = nil
+	q.entries = q.entries[:n-1]
+	entry.index = -1
+	return entry
+}
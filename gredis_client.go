@@ -1,7 +1,12 @@
 package gredis
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -21,8 +26,23 @@ import (
  * ================================================================================ */
 type (
 	redisClient struct {
-		prefixKey string
-		pool      *redis_go.Pool
+		prefixKey   string
+		address     string
+		username    string
+		password    string
+		clientName  string
+		db          int
+		timeout     int
+		protocol    int
+		pushHandler func(message PushMessage)
+		option      RedisOption
+		pool        *redis_go.Pool
+		poolCounter *poolCounter
+		retry       *RetryPolicy
+		breaker     *circuitBreaker
+		cache       *localCache
+		hooks       *hookChain
+		ctx         context.Context
 	}
 )
 
@@ -30,28 +50,69 @@ type (
  * 获取Redis实例
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func NewRedis(ip string, port int, password string, db, timeout int, prefixArgs ...string) IRedis {
+	prefix := ""
+	if len(prefixArgs) > 0 {
+		prefix = prefixArgs[0]
+	}
+
+	return NewRedisWithOption(RedisOption{
+		Ip:       ip,
+		Port:     port,
+		Password: password,
+		Db:       db,
+		Timeout:  timeout,
+		Prefix:   prefix,
+	})
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 根据选项获取Redis实例
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewRedisWithOption(option RedisOption) IRedis {
 	client := new(redisClient)
 
+	ip := option.Ip
 	if len(ip) == 0 {
 		ip = "127.0.0.1"
 	}
 
+	port := option.Port
 	if port <= 0 {
 		port = 6379
 	}
 
-	addr := fmt.Sprintf("%s:%d", ip, port)
+	client.address = fmt.Sprintf("%s:%d", ip, port)
+	client.username = option.Username
+	client.password = option.Password
+	client.clientName = option.ClientName
+	client.db = option.Db
+	client.timeout = option.Timeout
+	client.protocol = option.Protocol
+	client.pushHandler = option.PushHandler
+	client.retry = option.Retry
+	client.hooks = newHookChain(option.Hooks)
+
+	if option.SlowLog != nil {
+		client.hooks.add(newSlowLogHook(*option.SlowLog))
+	}
 
-	prefix := ""
-	if len(prefixArgs) > 0 {
-		prefix = prefixArgs[0]
+	if option.Breaker != nil {
+		client.breaker = newCircuitBreaker(*option.Breaker)
 	}
 
-	if prefix != "" {
-		client.prefixKey = prefix
+	if option.Prefix != "" {
+		client.prefixKey = option.Prefix
 	}
 
-	client.pool = newRedisPool(addr, password, db, timeout)
+	client.option = option
+	client.poolCounter = new(poolCounter)
+	client.pool = newRedisPool(client.dial, option.MaxIdle, option.MaxActive, option.IdleTimeout, option.Wait)
+
+	if option.Cache != nil {
+		client.cache = newLocalCache(*option.Cache, client.prefixKey, func() (redis_go.Conn, error) {
+			return client.dialWithPush(nil)
+		})
+	}
 
 	return client
 }
@@ -60,6 +121,18 @@ func NewRedis(ip string, port int, password string, db, timeout int, prefixArgs
  * Run Command
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) command(commandName string, args ...interface{}) (interface{}, error) {
+	reply, err := s.do(commandName, args...)
+	if s.protocol == REDIS_PROTOCOL_RESP3 {
+		reply = resp2Reply(reply, isPairReply(commandName, args))
+	}
+
+	return reply, err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行原始命令，不处理Key前缀，RESP3 下返回原生类型
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) Do(commandName string, args ...interface{}) (interface{}, error) {
 	return s.do(commandName, args...)
 }
 
@@ -67,10 +140,126 @@ func (s *redisClient) command(commandName string, args ...interface{}) (interfac
  * Run Command
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) do(commandName string, args ...interface{}) (interface{}, error) {
+	command := s.newCommand(commandName, args)
+	s.processCommand(command, s.execute)
+
+	return command.Reply, command.Err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行阻塞命令，连接的读超时为客户端超时加阻塞时长，
+ * 阻塞时长为0（永久阻塞）时不设置读超时，避免连接池的读超时提前中断阻塞
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) blockingCommand(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	command := s.newCommand(commandName, args)
+	command.isBlocking = true
+
+	if timeout > 0 && s.timeout > 0 {
+		command.readTimeout = time.Duration(s.timeout)*time.Second + timeout
+	}
+
+	s.processCommand(command, s.execute)
+
+	reply := command.Reply
+	if s.protocol == REDIS_PROTOCOL_RESP3 {
+		reply = resp2Reply(reply, false)
+	}
+
+	return reply, command.Err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行命令，按重试策略重试
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) execute(command *Command) {
+	isIdempotent := isIdempotentCommand(command.Name, command.Args)
+
+	for attempt := 1; ; attempt++ {
+		command.Attempts = attempt
+		command.Reply, command.Err = s.executeOnce(command)
+
+		if !s.retry.shouldRetry(attempt, isIdempotent, command.Err) {
+			return
+		}
+
+		if err := s.retry.wait(s.context(), attempt); err != nil {
+			command.Err = err
+			return
+		}
+	}
+}
+
+func (s *redisClient) executeOnce(command *Command) (interface{}, error) {
+	if err := s.breaker.allow(s.probe); err != nil {
+		return nil, newRedisError(command.Name, command.Args, err)
+	}
+
+	redisPool, poolWait := s.getConn()
+	defer redisPool.Close()
+	command.PoolWait += poolWait
+
+	var reply interface{}
+	var err error
+	if command.isBlocking {
+		reply, err = redis_go.DoWithTimeout(redisPool, command.readTimeout, command.Name, command.Args...)
+	} else {
+		reply, err = redisPool.Do(command.Name, command.Args...)
+	}
+
+	err = newRedisError(command.Name, command.Args, err)
+	s.breaker.report(err)
+
+	return reply, err
+}
+
+func (s *redisClient) newCommand(commandName string, args []interface{}) *Command {
+	return &Command{
+		Name:       commandName,
+		Args:       args,
+		Key:        commandKey(commandName, args),
+		Prefix:     s.prefixKey,
+		ClientName: s.clientName,
+		Address:    s.address,
+		Db:         s.db,
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取创建实例时的选项
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) Option() RedisOption {
+	return s.option
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取绑定上下文的Redis实例，与原实例共享连接池、钩子及缓存
+ * 上下文传递给钩子，用于链路追踪等
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) WithContext(ctx context.Context) IRedis {
+	client := *s
+	client.ctx = ctx
+
+	return &client
+}
+
+func (s *redisClient) context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+
+	return context.Background()
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 熔断器半开状态下的探测命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) probe() error {
 	redisPool := s.pool.Get()
 	defer redisPool.Close()
 
-	return redisPool.Do(commandName, args...)
+	_, err := redisPool.Do(REDIS_COMMAND_PING)
+
+	return err
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -81,14 +270,14 @@ func (s *redisClient) Keys(patternArgs ...string) ([]string, error) {
 	if len(patternArgs) > 0 {
 		keyPattern = patternArgs[0]
 	}
-	return redis_go.Strings(s.command(REDIS_COMMAND_KEYS, keyPattern))
+	return replyStrings(s.command(REDIS_COMMAND_KEYS, keyPattern))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Key是否存在
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) Exists(key string) (bool, error) {
-	return redis_go.Bool(s.command(REDIS_COMMAND_EXISTS, s.GetKey(key)))
+	return replyBool(s.command(REDIS_COMMAND_EXISTS, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -157,42 +346,42 @@ func (s *redisClient) PexpireAt(key string, timestamp int) error {
  * 移除key的过期时间，key将持久保持
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) Persist(key string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_PERSIST, s.GetKey(key)))
+	return replyInt(s.command(REDIS_COMMAND_PERSIST, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Ttl
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) Ttl(key string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_TTL, s.GetKey(key)))
+	return replyInt(s.command(REDIS_COMMAND_TTL, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Pttl
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) Pttl(key string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_PTTL, s.GetKey(key)))
+	return replyInt(s.command(REDIS_COMMAND_PTTL, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * 服务器信息
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) Info() (string, error) {
-	return redis_go.String(s.command(REDIS_COMMAND_INFO))
+	return replyString(s.command(REDIS_COMMAND_INFO))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * 获取指定key的存储类型
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) Type(key string) (string, error) {
-	return redis_go.String(s.command(REDIS_COMMAND_TYPE, s.GetKey(key)))
+	return replyString(s.command(REDIS_COMMAND_TYPE, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Dump指定key的数据
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) Dump(key string) (string, error) {
-	return redis_go.String(s.command(REDIS_COMMAND_DUMP, s.GetKey(key)))
+	return replyString(s.command(REDIS_COMMAND_DUMP, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -271,17 +460,17 @@ func (s *redisClient) Incr(key string, stepArgs ...int) (int, error) {
 	}
 
 	if step == 1 {
-		return redis_go.Int(s.command(REDIS_COMMAND_INCR, s.GetKey(key)))
+		return replyInt(s.command(REDIS_COMMAND_INCR, s.GetKey(key)))
 	}
 
-	return redis_go.Int(s.command(REDIS_COMMAND_INCRBY, s.GetKey(key), step))
+	return replyInt(s.command(REDIS_COMMAND_INCRBY, s.GetKey(key), step))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * INCRBYFLOAT
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) IncrByFloat(key string, value float64) (float64, error) {
-	return redis_go.Float64(s.command(REDIS_COMMAND_INCRBYFLOAT, s.GetKey(key), value))
+	return replyFloat64(s.command(REDIS_COMMAND_INCRBYFLOAT, s.GetKey(key), value))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -294,10 +483,10 @@ func (s *redisClient) Decr(key string, stepArgs ...int) (int, error) {
 	}
 
 	if step == 1 {
-		return redis_go.Int(s.command(REDIS_COMMAND_DECR, s.GetKey(key)))
+		return replyInt(s.command(REDIS_COMMAND_DECR, s.GetKey(key)))
 	}
 
-	return redis_go.Int(s.command(REDIS_COMMAND_DECRBY, s.GetKey(key), step))
+	return replyInt(s.command(REDIS_COMMAND_DECRBY, s.GetKey(key), step))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -318,7 +507,7 @@ func (s *redisClient) Set(key string, value interface{}, args ...int) error {
 		isMilliSecond := false
 
 		if argsCount > 1 {
-			if args[0] == 1 {
+			if args[1] == 1 {
 				isMilliSecond = true
 			}
 		}
@@ -335,12 +524,113 @@ func (s *redisClient) Set(key string, value interface{}, args ...int) error {
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * String SETNX
+ * 返回是否设置成功，键已存在时返回 false
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SetNx(key string, value interface{}) (bool, error) {
+	return replyBool(s.command(REDIS_COMMAND_SETNX, s.GetKey(key), value))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String SET key value [NX | XX] [EX | PX | EXAT | PXAT | KEEPTTL]
+ * 返回是否设置成功，Nx | Xx 条件不满足时返回 false
+ * Ttl 大于0且不足1毫秒时返回 ErrInvalidTtl
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SetArgs(key string, value interface{}, args ...SetOption) (bool, error) {
+	commandArgs, err := setArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(value), args)
+	if err != nil {
+		return false, err
+	}
+
+	reply, err := s.command(REDIS_COMMAND_SET, commandArgs...)
+	if err != nil {
+		return false, err
+	}
+
+	return reply != nil, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String SET key value GET
+ * 设置值并返回原值，原值不存在时返回 ErrNotFound
+ * Nx 时仅在键不存在的情况下设置，键已存在时不设置并返回原值
+ * Ttl 大于0且不足1毫秒时返回 ErrInvalidTtl
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SetGet(key string, value interface{}, args ...SetOption) ([]byte, error) {
+	commandArgs, err := setArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(value), args)
+	if err != nil {
+		return nil, err
+	}
+
+	return replyBytes(s.command(REDIS_COMMAND_SET, commandArgs.Add("GET")...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String MSET
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SetNx(key string, value interface{}) error {
-	_, err := s.command(REDIS_COMMAND_SETNX, s.GetKey(key), value)
+func (s *redisClient) MSet(values map[string]interface{}) error {
+	_, err := s.command(REDIS_COMMAND_MSET, s.pairsArgs(values)...)
 	return err
 }
 
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String MSETNX
+ * 仅当全部键都不存在时设置，返回是否设置成功
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) MSetNx(values map[string]interface{}) (bool, error) {
+	return replyBool(s.command(REDIS_COMMAND_MSETNX, s.pairsArgs(values)...))
+}
+
+func (s *redisClient) pairsArgs(values map[string]interface{}) redis_go.Args {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := redis_go.Args{}
+	for _, key := range keys {
+		args = args.Add(s.GetKey(key)).Add(values[key])
+	}
+
+	return args
+}
+
+func setArgs(args redis_go.Args, options []SetOption) (redis_go.Args, error) {
+	if len(options) == 0 {
+		return args, nil
+	}
+
+	option := options[0]
+	if option.Nx {
+		args = args.Add("NX")
+	} else if option.Xx {
+		args = args.Add("XX")
+	}
+
+	switch {
+	case !option.ExpireAt.IsZero():
+		if milliseconds := option.ExpireAt.UnixMilli(); milliseconds%1000 == 0 {
+			args = args.Add("EXAT").Add(milliseconds / 1000)
+		} else {
+			args = args.Add("PXAT").Add(milliseconds)
+		}
+	case option.Ttl > 0:
+		if option.Ttl < time.Millisecond {
+			return nil, ErrInvalidTtl
+		}
+
+		if option.Ttl%time.Second == 0 {
+			args = args.Add("EX").Add(int64(option.Ttl / time.Second))
+		} else {
+			args = args.Add("PX").Add(option.Ttl.Milliseconds())
+		}
+	case option.KeepTtl:
+		args = args.Add("KEEPTTL")
+	}
+
+	return args, nil
+}
+
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * String SETRANGE
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
@@ -361,43 +651,216 @@ func (s *redisClient) Append(key string, value interface{}) error {
  * String GET
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) Get(key string) ([]byte, error) {
-	value, err := redis_go.String(s.command(REDIS_COMMAND_GET, s.GetKey(key)))
-	if err != nil {
-		return nil, err
+	if s.cache != nil {
+		return s.cache.load(key, s.GetKey(key), "", func() ([]byte, error) {
+			return s.get(key)
+		})
 	}
 
-	return []byte(value), nil
+	return s.get(key)
+}
+
+func (s *redisClient) get(key string) ([]byte, error) {
+	return replyBytes(s.command(REDIS_COMMAND_GET, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * String GETSET
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) GetSet(key string, value interface{}) ([]byte, error) {
-	value, err := redis_go.String(s.command(REDIS_COMMAND_GETSET, s.GetKey(key), value))
-	if err != nil {
-		return nil, err
+	return replyBytes(s.command(REDIS_COMMAND_GETSET, s.GetKey(key), value))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String GETEX
+ * 获取值并设置过期时间，ttl 小于等于0时移除过期时间，不足1毫秒时返回 ErrInvalidTtl
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) GetEx(key string, ttl time.Duration) ([]byte, error) {
+	if ttl > 0 && ttl < time.Millisecond {
+		return nil, ErrInvalidTtl
 	}
 
-	return []byte(value.(string)), err
+	if ttl > 0 {
+		return replyBytes(s.command(REDIS_COMMAND_GETEX, s.GetKey(key), "PX", ttl.Milliseconds()))
+	}
+
+	return replyBytes(s.command(REDIS_COMMAND_GETEX, s.GetKey(key), "PERSIST"))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String GETDEL
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) GetDel(key string) ([]byte, error) {
+	return replyBytes(s.command(REDIS_COMMAND_GETDEL, s.GetKey(key)))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String MGET
+ * 按键顺序返回值，键不存在时对应 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) MGet(keys ...string) ([][]byte, error) {
+	return replyByteSlices(s.command(REDIS_COMMAND_MGET, s.keysArgs(keys)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * String GETRANGE
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) GetRange(key string, start, end int) ([]byte, error) {
-	value, err := redis_go.String(s.command(REDIS_COMMAND_GETRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...))
-	if err != nil {
-		return nil, err
-	}
-
-	return []byte(value), err
+	return replyBytes(s.command(REDIS_COMMAND_GETRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * String STRLEN
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) StrLen(key string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_STRLEN, s.GetKey(key)))
+	return replyInt(s.command(REDIS_COMMAND_STRLEN, s.GetKey(key)))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String LCS
+ * 返回两个键值的最长公共子序列
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LCS(key1, key2 string) (string, error) {
+	return replyString(s.command(REDIS_COMMAND_LCS, s.GetKey(key1), s.GetKey(key2)))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * String LCS LEN
+ * 返回两个键值的最长公共子序列长度
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LCSLen(key1, key2 string) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_LCS, s.GetKey(key1), s.GetKey(key2), "LEN"))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Bitmap SETBIT
+ * 设置指定偏移的位，返回该位原来的值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SetBit(key string, offset int, value int) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_SETBIT, s.GetKey(key), offset, value))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Bitmap GETBIT
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) GetBit(key string, offset int) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_GETBIT, s.GetKey(key), offset))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Bitmap BITCOUNT
+ * 统计值为1的位数，未指定区间时统计整个字符串
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BitCount(key string, args ...BitRangeOption) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_BITCOUNT, bitRangeArgs(redis_go.Args{}.Add(s.GetKey(key)), args)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Bitmap BITPOS
+ * 返回第一个值为 bit 的位偏移，不存在时返回 -1
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BitPos(key string, bit int, args ...BitRangeOption) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_BITPOS, bitRangeArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(bit), args)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Bitmap BITOP
+ * operation: REDIS_BITOP_*，返回目标键的字节长度
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BitOp(operation, destKey string, keys ...string) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_BITOP, redis_go.Args{}.Add(operation).Add(s.GetKey(destKey)).Add(s.keysArgs(keys)...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Bitmap BITFIELD
+ * 按子命令顺序返回结果，OVERFLOW FAIL 且溢出时对应 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BitField(key string, operations ...BitFieldOperation) ([]*int64, error) {
+	return replyNullableInt64s(s.command(REDIS_COMMAND_BITFIELD, bitFieldArgs(redis_go.Args{}.Add(s.GetKey(key)), operations)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Bitmap BITFIELD_RO
+ * 只读版本，仅支持 BitFieldGet 子命令，可在只读副本上执行
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BitFieldRo(key string, operations ...BitFieldOperation) ([]int64, error) {
+	return replyInt64s(s.command(REDIS_COMMAND_BITFIELD_RO, bitFieldArgs(redis_go.Args{}.Add(s.GetKey(key)), operations)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BITFIELD GET 子命令
+ * fieldType: i1..i64 有符号 | u1..u63 无符号，如 u8、i16
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func BitFieldGet(fieldType string, offset int) BitFieldOperation {
+	return BitFieldOperation{args: []interface{}{"GET", fieldType, offset}}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BITFIELD SET 子命令，结果为字段原来的值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func BitFieldSet(fieldType string, offset int, value int64) BitFieldOperation {
+	return BitFieldOperation{args: []interface{}{"SET", fieldType, offset, value}}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BITFIELD INCRBY 子命令，结果为字段增加后的值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func BitFieldIncrBy(fieldType string, offset int, increment int64) BitFieldOperation {
+	return BitFieldOperation{args: []interface{}{"INCRBY", fieldType, offset, increment}}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BITFIELD OVERFLOW 子命令，mode: REDIS_BITFIELD_OVERFLOW_*
+ * 作用于其后的 SET | INCRBY 子命令，默认 WRAP
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func BitFieldOverflow(mode string) BitFieldOperation {
+	return BitFieldOperation{args: []interface{}{"OVERFLOW", mode}}
+}
+
+func bitRangeArgs(args redis_go.Args, options []BitRangeOption) redis_go.Args {
+	if len(options) == 0 {
+		return args
+	}
+
+	args = args.Add(options[0].Start).Add(options[0].End)
+	if options[0].Bit {
+		args = args.Add("BIT")
+	}
+
+	return args
+}
+
+func bitFieldArgs(args redis_go.Args, operations []BitFieldOperation) redis_go.Args {
+	for _, operation := range operations {
+		args = args.Add(operation.args...)
+	}
+
+	return args
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HyperLogLog PFADD
+ * 返回基数估算是否发生变化
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) PfAdd(key string, elements ...interface{}) (bool, error) {
+	return replyBool(s.command(REDIS_COMMAND_PFADD, redis_go.Args{}.Add(s.GetKey(key)).Add(elements...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HyperLogLog PFCOUNT
+ * 多个键时返回并集的基数估算，标准误差约 0.81%
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) PfCount(keys ...string) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_PFCOUNT, s.keysArgs(keys)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HyperLogLog PFMERGE
+ * 合并到目标键，目标键已存在时一并参与合并
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) PfMerge(destKey string, keys ...string) error {
+	_, err := s.command(REDIS_COMMAND_PFMERGE, redis_go.Args{}.Add(s.GetKey(destKey)).Add(s.keysArgs(keys)...)...)
+	return err
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -420,21 +883,21 @@ func (s *redisClient) RPush(key string, value ...interface{}) error {
  * List LPOP
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) LPop(key string) (string, error) {
-	return redis_go.String(s.command(REDIS_COMMAND_LPOP, s.GetKey(key)))
+	return replyString(s.command(REDIS_COMMAND_LPOP, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * List LRANGE
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) LRange(key string, start, end int) ([]string, error) {
-	return redis_go.Strings(s.command(REDIS_COMMAND_LRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...))
+	return replyStrings(s.command(REDIS_COMMAND_LRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * List LINDEX
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) LIndex(key string, index int) (string, error) {
-	return redis_go.String(s.command(REDIS_COMMAND_LINDEX, redis_go.Args{}.Add(s.GetKey(key)).Add(index)...))
+	return replyString(s.command(REDIS_COMMAND_LINDEX, redis_go.Args{}.Add(s.GetKey(key)).Add(index)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -470,42 +933,252 @@ func (s *redisClient) LTrim(key string, start, end int) error {
  * List LLEN
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) LLen(key string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_LLEN, s.GetKey(key)))
+	return replyInt(s.command(REDIS_COMMAND_LLEN, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Hash HMSET
+ * List RPOP
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) HSetData(structData interface{}, args ...interface{}) error {
-	key := ""
-	var time int = 0
+func (s *redisClient) RPop(key string) (string, error) {
+	return replyString(s.command(REDIS_COMMAND_RPOP, s.GetKey(key)))
+}
 
-	argsCount := len(args)
-	if argsCount == 0 {
-		key = s.GetObjectKey(structData)
-	} else if argsCount == 1 {
-		switch args[0].(type) {
-		case string:
-			key = args[0].(string)
-			break
-		case int:
-			key = s.GetObjectKey(structData)
-			time = args[0].(int)
-			break
-		}
-	} else if argsCount == 2 {
-		key = args[0].(string)
-		if timeValue, isOk := args[1].(int); isOk {
-			time = timeValue
-		}
-	}
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List LPOP key count
+ * 从头部弹出最多 count 个元素，列表不存在时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LPopCount(key string, count int) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_LPOP, s.GetKey(key), count))
+}
 
-	if _, err := s.command(REDIS_COMMAND_HMSET, redis_go.Args{}.Add(s.GetKey(key)).AddFlat(structData)...); err != nil {
-		return err
-	}
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List RPOP key count
+ * 从尾部弹出最多 count 个元素，列表不存在时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) RPopCount(key string, count int) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_RPOP, s.GetKey(key), count))
+}
 
-	if time > 0 {
-		if err := s.Expire(key, time); err != nil {
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List LPUSHX
+ * 仅当列表存在时从头部插入，返回插入后的长度，列表不存在时返回0
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LPushX(key string, value ...interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_LPUSHX, redis_go.Args{}.Add(s.GetKey(key)).Add(value...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List RPUSHX
+ * 仅当列表存在时从尾部插入，返回插入后的长度，列表不存在时返回0
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) RPushX(key string, value ...interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_RPUSHX, redis_go.Args{}.Add(s.GetKey(key)).Add(value...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List LINSERT BEFORE
+ * 返回插入后的长度，pivot 不存在时返回-1，列表不存在时返回0
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LInsertBefore(key string, pivot, value interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_LINSERT, s.GetKey(key), "BEFORE", pivot, value))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List LINSERT AFTER
+ * 返回插入后的长度，pivot 不存在时返回-1，列表不存在时返回0
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LInsertAfter(key string, pivot, value interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_LINSERT, s.GetKey(key), "AFTER", pivot, value))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List LPOS
+ * 返回匹配元素的索引，未匹配时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LPos(key string, value interface{}, args ...LPosOption) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_LPOS, lPosArgs(s.GetKey(key), value, args)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List LPOS COUNT
+ * 返回最多 count 个匹配元素的索引，count 为0时返回全部匹配
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LPosCount(key string, value interface{}, count int, args ...LPosOption) ([]int, error) {
+	return replyInts(s.command(REDIS_COMMAND_LPOS, lPosArgs(s.GetKey(key), value, args).Add("COUNT", count)...))
+}
+
+func lPosArgs(key string, value interface{}, args []LPosOption) redis_go.Args {
+	commandArgs := redis_go.Args{}.Add(key).Add(value)
+
+	if len(args) > 0 {
+		if args[0].Rank != 0 {
+			commandArgs = commandArgs.Add("RANK", args[0].Rank)
+		}
+
+		if args[0].MaxLen > 0 {
+			commandArgs = commandArgs.Add("MAXLEN", args[0].MaxLen)
+		}
+	}
+
+	return commandArgs
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List LMOVE
+ * 原子地从 srcKey 的 srcDirection 端弹出元素并插入 destKey 的 destDirection 端，
+ * 方向为 REDIS_LIST_LEFT | REDIS_LIST_RIGHT，srcKey 不存在时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LMove(srcKey, destKey, srcDirection, destDirection string) (string, error) {
+	return replyString(s.command(REDIS_COMMAND_LMOVE, s.GetKey(srcKey), s.GetKey(destKey), srcDirection, destDirection))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List BLMOVE
+ * 阻塞版本的 LMOVE，timeout 为0时永久阻塞，超时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BLMove(srcKey, destKey, srcDirection, destDirection string, timeout time.Duration) (string, error) {
+	return replyString(s.blockingCommand(timeout, REDIS_COMMAND_BLMOVE, s.GetKey(srcKey), s.GetKey(destKey), srcDirection, destDirection, timeout.Seconds()))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List RPOPLPUSH
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) RPopLPush(srcKey, destKey string) (string, error) {
+	return replyString(s.command(REDIS_COMMAND_RPOPLPUSH, s.GetKey(srcKey), s.GetKey(destKey)))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List BRPOPLPUSH
+ * 阻塞版本的 RPOPLPUSH，timeout 为0时永久阻塞，超时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BRPopLPush(srcKey, destKey string, timeout time.Duration) (string, error) {
+	return replyString(s.blockingCommand(timeout, REDIS_COMMAND_BRPOPLPUSH, s.GetKey(srcKey), s.GetKey(destKey), timeout.Seconds()))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List BLPOP
+ * 从第一个非空列表的头部弹出元素，返回（不含前缀的）列表Key及元素，
+ * timeout 为0时永久阻塞，超时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BLPop(timeout time.Duration, keys ...string) (string, string, error) {
+	return s.blockingPop(REDIS_COMMAND_BLPOP, timeout, keys)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List BRPOP
+ * 从第一个非空列表的尾部弹出元素，返回（不含前缀的）列表Key及元素，
+ * timeout 为0时永久阻塞，超时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BRPop(timeout time.Duration, keys ...string) (string, string, error) {
+	return s.blockingPop(REDIS_COMMAND_BRPOP, timeout, keys)
+}
+
+func (s *redisClient) blockingPop(commandName string, timeout time.Duration, keys []string) (string, string, error) {
+	args := redis_go.Args{}
+	for _, key := range keys {
+		args = args.Add(s.GetKey(key))
+	}
+
+	values, err := replyStrings(s.blockingCommand(timeout, commandName, args.Add(timeout.Seconds())...))
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(values) != 2 {
+		return "", "", ErrUnexpectedReply
+	}
+
+	return s.trimKey(values[0]), values[1], nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List LMPOP
+ * 从第一个非空列表的 direction 端弹出最多 count 个元素，返回（不含前缀的）列表Key及元素，
+ * 全部列表为空时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) LMPop(direction string, count int, keys ...string) (string, []string, error) {
+	return s.mPop(s.command(REDIS_COMMAND_LMPOP, s.mPopArgs(direction, count, keys)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * List BLMPOP
+ * 阻塞版本的 LMPOP，timeout 为0时永久阻塞，超时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BLMPop(timeout time.Duration, direction string, count int, keys ...string) (string, []string, error) {
+	args := redis_go.Args{}.Add(timeout.Seconds()).Add(s.mPopArgs(direction, count, keys)...)
+	return s.mPop(s.blockingCommand(timeout, REDIS_COMMAND_BLMPOP, args...))
+}
+
+func (s *redisClient) mPopArgs(direction string, count int, keys []string) redis_go.Args {
+	args := redis_go.Args{}.Add(len(keys))
+	for _, key := range keys {
+		args = args.Add(s.GetKey(key))
+	}
+
+	args = args.Add(direction)
+	if count > 0 {
+		args = args.Add("COUNT", count)
+	}
+
+	return args
+}
+
+func (s *redisClient) mPop(reply interface{}, err error) (string, []string, error) {
+	values, err := replyValues(reply, err)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(values) != 2 {
+		return "", nil, ErrUnexpectedReply
+	}
+
+	key, err := redis_go.String(values[0], nil)
+	if err != nil {
+		return "", nil, replyError(err)
+	}
+
+	items, err := redis_go.Strings(values[1], nil)
+	if err != nil {
+		return "", nil, replyError(err)
+	}
+
+	return s.trimKey(key), items, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HMSET
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HSetData(structData interface{}, args ...interface{}) error {
+	key := ""
+	var time int = 0
+
+	argsCount := len(args)
+	if argsCount == 0 {
+		key = s.GetObjectKey(structData)
+	} else if argsCount == 1 {
+		switch args[0].(type) {
+		case string:
+			key = args[0].(string)
+			break
+		case int:
+			key = s.GetObjectKey(structData)
+			time = args[0].(int)
+			break
+		}
+	} else if argsCount == 2 {
+		key = args[0].(string)
+		if timeValue, isOk := args[1].(int); isOk {
+			time = timeValue
+		}
+	}
+
+	if _, err := s.command(REDIS_COMMAND_HMSET, redis_go.Args{}.Add(s.GetKey(key)).AddFlat(structData)...); err != nil {
+		return err
+	}
+
+	if time > 0 {
+		if err := s.Expire(key, time); err != nil {
 			return err
 		}
 	}
@@ -526,7 +1199,7 @@ func (s *redisClient) HGetData(structData interface{}, args ...interface{}) erro
 		key = args[0].(string)
 	}
 
-	data, err := redis_go.Values(s.command(REDIS_COMMAND_HGETALL, s.GetKey(key)))
+	data, err := replyValues(s.command(REDIS_COMMAND_HGETALL, s.GetKey(key)))
 	if err != nil {
 		return err
 	}
@@ -568,63 +1241,90 @@ func (s *redisClient) HMSet(key string, fields ...interface{}) error {
  * Hash HGET
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HGet(key string, field string) (string, error) {
-	return redis_go.String(s.command(REDIS_COMMAND_HGET, redis_go.Args{}.Add(s.GetKey(key)).Add(field)...))
+	if s.cache != nil {
+		value, err := s.cache.load(key, s.GetKey(key), field, func() ([]byte, error) {
+			value, err := s.hget(key, field)
+			return []byte(value), err
+		})
+		return string(value), err
+	}
+
+	return s.hget(key, field)
+}
+
+func (s *redisClient) hget(key string, field string) (string, error) {
+	return replyString(s.command(REDIS_COMMAND_HGET, redis_go.Args{}.Add(s.GetKey(key)).Add(field)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Hash HMGET
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HMGet(key string, fields ...interface{}) ([]string, error) {
-	return redis_go.Strings(s.command(REDIS_COMMAND_HMGET, redis_go.Args{}.Add(s.GetKey(key)).Add(fields...)...))
+	return replyStrings(s.command(REDIS_COMMAND_HMGET, redis_go.Args{}.Add(s.GetKey(key)).Add(fields...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HMGET
+ * 按字段顺序返回值，字段不存在时对应 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HMGetValues(key string, fields ...interface{}) ([]*string, error) {
+	return replyNullableStrings(s.command(REDIS_COMMAND_HMGET, redis_go.Args{}.Add(s.GetKey(key)).Add(fields...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HGETALL
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HGetAll(key string) (map[string]string, error) {
+	return replyStringMap(s.command(REDIS_COMMAND_HGETALL, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Hash HKEYS
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HKeys(key string) ([]string, error) {
-	return redis_go.Strings(s.command(REDIS_COMMAND_HKEYS, redis_go.Args{}.Add(s.GetKey(key))...))
+	return replyStrings(s.command(REDIS_COMMAND_HKEYS, redis_go.Args{}.Add(s.GetKey(key))...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Hash HVALS
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HVals(key string) ([]string, error) {
-	return redis_go.Strings(s.command(REDIS_COMMAND_HVALS, redis_go.Args{}.Add(s.GetKey(key))...))
+	return replyStrings(s.command(REDIS_COMMAND_HVALS, redis_go.Args{}.Add(s.GetKey(key))...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Hash HINCRBY
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HIncrBy(key string, field string, value int) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_HINCRBY, redis_go.Args{}.Add(s.GetKey(key)).Add(field).Add(value)...))
+	return replyInt(s.command(REDIS_COMMAND_HINCRBY, redis_go.Args{}.Add(s.GetKey(key)).Add(field).Add(value)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Hash HINCRBYFLOAT
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HIncrByFloat(key string, field string, value float64) (float64, error) {
-	return redis_go.Float64(s.command(REDIS_COMMAND_HINCRBYFLOAT, redis_go.Args{}.Add(s.GetKey(key)).Add(field).Add(value)...))
+	return replyFloat64(s.command(REDIS_COMMAND_HINCRBYFLOAT, redis_go.Args{}.Add(s.GetKey(key)).Add(field).Add(value)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Hash HLEN
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HLen(key string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_HLEN, redis_go.Args{}.Add(s.GetKey(key))...))
+	return replyInt(s.command(REDIS_COMMAND_HLEN, redis_go.Args{}.Add(s.GetKey(key))...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Hash HSTRLEN
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HStrLen(key, field string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_HSTRLEN, redis_go.Args{}.Add(s.GetKey(key)).Add(field)...))
+	return replyInt(s.command(REDIS_COMMAND_HSTRLEN, redis_go.Args{}.Add(s.GetKey(key)).Add(field)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Hash HEXISTS
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) HExists(key, field string) (bool, error) {
-	return redis_go.Bool(s.command(REDIS_COMMAND_HEXISTS, redis_go.Args{}.Add(s.GetKey(key)).Add(field)...))
+	return replyBool(s.command(REDIS_COMMAND_HEXISTS, redis_go.Args{}.Add(s.GetKey(key)).Add(field)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -638,23 +1338,168 @@ func (s *redisClient) HDel(key string, fields ...interface{}) error {
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Set Add
+ * Hash HRANDFIELD
+ * count 为负数时允许返回重复字段
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SAdd(key string, values ...interface{}) error {
-	if _, err := s.command(REDIS_COMMAND_SADD, redis_go.Args{}.Add(s.GetKey(key)).Add(values...)...); err != nil {
-		return err
+func (s *redisClient) HRandField(key string, count int) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_HRANDFIELD, s.GetKey(key), count))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HRANDFIELD WITHVALUES
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HRandFieldWithValues(key string, count int) (map[string]string, error) {
+	return replyStringMap(s.command(REDIS_COMMAND_HRANDFIELD, s.GetKey(key), count, "WITHVALUES"))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HSCAN
+ * 返回下一次迭代的游标及本次的字段值，游标为0时迭代结束
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HScan(key string, cursor uint64, args ...ScanOption) (uint64, map[string]string, error) {
+	cursor, items, err := replyScan(s.command(REDIS_COMMAND_HSCAN, scanArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(cursor), args)...))
+	if err != nil {
+		return 0, nil, err
 	}
-	return nil
+
+	if len(items)%2 != 0 {
+		return 0, nil, ErrUnexpectedReply
+	}
+
+	values := make(map[string]string, len(items)/2)
+	for index := 0; index < len(items); index += 2 {
+		values[items[index]] = items[index+1]
+	}
+
+	return cursor, values, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Set Move
+ * Hash HEXPIRE
+ * 设置字段过期时间（Redis 7.4+，秒级精度），ttl 大于0且不是整秒时返回 ErrInvalidTtl
+ * （非整秒的过期时间使用 HPexpire），每个字段返回：
+ * -2 字段不存在 | 0 条件不满足 | 1 已设置 | 2 过期时间为0，字段已删除
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SMove(srcKkey, desKey string, values ...interface{}) error {
-	if _, err := s.command(REDIS_COMMAND_SMOVE, redis_go.Args{}.Add(s.GetKey(srcKkey)).Add(s.GetKey(desKey)).Add(values...)...); err != nil {
-		return err
+func (s *redisClient) HExpire(key string, ttl time.Duration, fields ...string) ([]int, error) {
+	if ttl > 0 && ttl%time.Second != 0 {
+		return nil, ErrInvalidTtl
 	}
-	return nil
+
+	return replyInts(s.command(REDIS_COMMAND_HEXPIRE, hashFieldsArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(int64(ttl/time.Second)), fields)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HPEXPIRE
+ * 设置字段过期时间（Redis 7.4+，毫秒级精度），ttl 大于0且不足1毫秒时返回 ErrInvalidTtl，
+ * 返回值同 HExpire
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HPexpire(key string, ttl time.Duration, fields ...string) ([]int, error) {
+	if ttl > 0 && ttl < time.Millisecond {
+		return nil, ErrInvalidTtl
+	}
+
+	return replyInts(s.command(REDIS_COMMAND_HPEXPIRE, hashFieldsArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(ttl.Milliseconds()), fields)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HTTL
+ * 每个字段返回剩余秒数：-2 字段不存在 | -1 未设置过期时间
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HTtl(key string, fields ...string) ([]int, error) {
+	return replyInts(s.command(REDIS_COMMAND_HTTL, hashFieldsArgs(redis_go.Args{}.Add(s.GetKey(key)), fields)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HPTTL
+ * 每个字段返回剩余毫秒数，返回值同 HTtl
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HPttl(key string, fields ...string) ([]int, error) {
+	return replyInts(s.command(REDIS_COMMAND_HPTTL, hashFieldsArgs(redis_go.Args{}.Add(s.GetKey(key)), fields)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HPERSIST
+ * 每个字段返回：-2 字段不存在 | -1 未设置过期时间 | 1 已移除过期时间
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HPersist(key string, fields ...string) ([]int, error) {
+	return replyInts(s.command(REDIS_COMMAND_HPERSIST, hashFieldsArgs(redis_go.Args{}.Add(s.GetKey(key)), fields)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HGETDEL
+ * 获取并删除字段（Redis 8.0+），字段不存在时对应 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HGetDel(key string, fields ...string) ([]*string, error) {
+	return replyNullableStrings(s.command(REDIS_COMMAND_HGETDEL, hashFieldsArgs(redis_go.Args{}.Add(s.GetKey(key)), fields)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HGETEX
+ * 获取字段并设置过期时间（Redis 8.0+），ttl 小于等于0时移除字段过期时间，
+ * ttl 不足1毫秒时返回 ErrInvalidTtl
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HGetEx(key string, ttl time.Duration, fields ...string) ([]*string, error) {
+	if ttl > 0 && ttl < time.Millisecond {
+		return nil, ErrInvalidTtl
+	}
+
+	args := redis_go.Args{}.Add(s.GetKey(key))
+	if ttl > 0 {
+		args = args.Add("PX").Add(ttl.Milliseconds())
+	} else {
+		args = args.Add("PERSIST")
+	}
+
+	return replyNullableStrings(s.command(REDIS_COMMAND_HGETEX, hashFieldsArgs(args, fields)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Hash HSETEX
+ * 设置字段值及过期时间（Redis 8.0+），field value [field value ...]
+ * ttl 小于等于0时不设置过期时间，不足1毫秒时返回 ErrInvalidTtl，字段与值不成对时返回 ErrInvalidArgs
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) HSetEx(key string, ttl time.Duration, fields ...interface{}) (bool, error) {
+	if ttl > 0 && ttl < time.Millisecond {
+		return false, ErrInvalidTtl
+	}
+
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return false, ErrInvalidArgs
+	}
+
+	args := redis_go.Args{}.Add(s.GetKey(key))
+	if ttl > 0 {
+		args = args.Add("PX").Add(ttl.Milliseconds())
+	}
+
+	args = args.Add("FIELDS").Add(len(fields) / 2).Add(fields...)
+
+	return replyBool(s.command(REDIS_COMMAND_HSETEX, args...))
+}
+
+func hashFieldsArgs(args redis_go.Args, fields []string) redis_go.Args {
+	args = args.Add("FIELDS").Add(len(fields))
+	for _, field := range fields {
+		args = args.Add(field)
+	}
+
+	return args
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Add
+ * 返回新增的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SAdd(key string, values ...interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_SADD, redis_go.Args{}.Add(s.GetKey(key)).Add(values...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Move
+ * 返回成员是否被移动，成员不在源集合时返回 false
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SMove(srcKey, destKey string, value interface{}) (bool, error) {
+	return replyBool(s.command(REDIS_COMMAND_SMOVE, s.GetKey(srcKey), s.GetKey(destKey), value))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -665,59 +1510,78 @@ func (s *redisClient) SPop(key string, countArgs ...int) ([]string, error) {
 	if len(countArgs) > 0 {
 		count = countArgs[0]
 	}
-	return redis_go.Strings(s.command(REDIS_COMMAND_SPOP, s.GetKey(key), count))
+	return replyStrings(s.command(REDIS_COMMAND_SPOP, s.GetKey(key), count))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set Rem
+ * 返回删除的成员数
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SRem(key string, values ...interface{}) error {
-	if _, err := s.command(REDIS_COMMAND_SREM, redis_go.Args{}.Add(s.GetKey(key)).Add(values...)...); err != nil {
-		return err
-	}
-	return nil
+func (s *redisClient) SRem(key string, values ...interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_SREM, redis_go.Args{}.Add(s.GetKey(key)).Add(values...)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set Card
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) SCard(key string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_SCARD, s.GetKey(key)))
+	return replyInt(s.command(REDIS_COMMAND_SCARD, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set IsMemeber
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) SIsMemeber(key string, value interface{}) (bool, error) {
-	return redis_go.Bool(s.command(REDIS_COMMAND_SISMEMBER, redis_go.Args{}.Add(s.GetKey(key)).Add(value)...))
+	return replyBool(s.command(REDIS_COMMAND_SISMEMBER, redis_go.Args{}.Add(s.GetKey(key)).Add(value)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set SMISMEMBER
+ * 按参数顺序返回各值是否为集合成员
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SMIsMember(key string, values ...interface{}) ([]bool, error) {
+	flags, err := replyInts(s.command(REDIS_COMMAND_SMISMEMBER, redis_go.Args{}.Add(s.GetKey(key)).Add(values...)...))
+	if err != nil {
+		return nil, err
+	}
+
+	isMembers := make([]bool, 0, len(flags))
+	for _, flag := range flags {
+		isMembers = append(isMembers, flag == 1)
+	}
+
+	return isMembers, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set Members
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) SMembers(key string) ([]string, error) {
-	return redis_go.Strings(s.command(REDIS_COMMAND_SMEMBERS, s.GetKey(key)))
+	return replyStrings(s.command(REDIS_COMMAND_SMEMBERS, s.GetKey(key)))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set Members
+ * Deprecated: 使用 gredis.As[int](redis.SMembers(key))
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) SMembersInt(key string) ([]int, error) {
-	return redis_go.Ints(s.command(REDIS_COMMAND_SMEMBERS, s.GetKey(key)))
+	return As[int](s.SMembers(key))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set Members
+ * Deprecated: 使用 gredis.As[int64](redis.SMembers(key))
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) SMembersInt64(key string) ([]int64, error) {
-	return redis_go.Int64s(s.command(REDIS_COMMAND_SMEMBERS, s.GetKey(key)))
+	return As[int64](s.SMembers(key))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set Members
+ * Deprecated: 使用 gredis.As[float64](redis.SMembers(key))
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) SMembersFloat64(key string) ([]float64, error) {
-	return redis_go.Float64s(s.command(REDIS_COMMAND_SMEMBERS, s.GetKey(key)))
+	return As[float64](s.SMembers(key))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -728,261 +1592,1054 @@ func (s *redisClient) SRandMembers(key string, countArgs ...int) ([]string, erro
 	if len(countArgs) > 0 {
 		count = countArgs[0]
 	}
-	return redis_go.Strings(s.command(REDIS_COMMAND_SRANDMEMBER, s.GetKey(key), count))
+	return replyStrings(s.command(REDIS_COMMAND_SRANDMEMBER, s.GetKey(key), count))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set RandMember
+ * Deprecated: 使用 gredis.As[int](redis.SRandMembers(key, count))
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) SRandMembersInt(key string, countArgs ...int) ([]int, error) {
-	var count int = 1
-	if len(countArgs) > 0 {
-		count = countArgs[0]
-	}
-	return redis_go.Ints(s.command(REDIS_COMMAND_SRANDMEMBER, s.GetKey(key), count))
+	return As[int](s.SRandMembers(key, countArgs...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * Set RandMember
+ * Deprecated: 使用 gredis.As[int64](redis.SRandMembers(key, count))
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
 func (s *redisClient) SRandMembersInt64(key string, countArgs ...int) ([]int64, error) {
-	var count int = 1
-	if len(countArgs) > 0 {
-		count = countArgs[0]
-	}
-	return redis_go.Int64s(s.command(REDIS_COMMAND_SRANDMEMBER, s.GetKey(key), count))
+	return As[int64](s.SRandMembers(key, countArgs...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Union ints
+ * Deprecated: 使用 gredis.As[int](redis.SUnion(keys...))
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SUnionInt(keys ...string) ([]int, error) {
+	return As[int](s.SUnion(keys...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Union strings
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SUnion(keys ...string) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_SUNION, s.keysArgs(keys)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Union store
+ * 返回目标集合的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SUnionStore(destKey string, keys ...string) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_SUNIONSTORE, redis_go.Args{}.Add(s.GetKey(destKey)).Add(s.keysArgs(keys)...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Inter ints
+ * Deprecated: 使用 gredis.As[int](redis.SInter(keys...))
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SInterInt(keys ...string) ([]int, error) {
+	return As[int](s.SInter(keys...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Inter strings
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SInter(keys ...string) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_SINTER, s.keysArgs(keys)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Inter store
+ * 返回目标集合的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SInterStore(destKey string, keys ...string) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_SINTERSTORE, redis_go.Args{}.Add(s.GetKey(destKey)).Add(s.keysArgs(keys)...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set SINTERCARD
+ * 返回交集的成员数，limit 大于0时计数达到 limit 即返回
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SInterCard(limit int, keys ...string) (int, error) {
+	args := redis_go.Args{}.Add(len(keys)).Add(s.keysArgs(keys)...)
+	if limit > 0 {
+		args = args.Add("LIMIT").Add(limit)
+	}
+
+	return replyInt(s.command(REDIS_COMMAND_SINTERCARD, args...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Diff ints
+ * Deprecated: 使用 gredis.As[int](redis.SDiff(keys...))
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SDiffInt(keys ...string) ([]int, error) {
+	return As[int](s.SDiff(keys...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Diff strings
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SDiff(keys ...string) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_SDIFF, s.keysArgs(keys)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set Diff store
+ * 返回目标集合的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SDiffStore(destKey string, keys ...string) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_SDIFFSTORE, redis_go.Args{}.Add(s.GetKey(destKey)).Add(s.keysArgs(keys)...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Set SSCAN
+ * 增量迭代集合成员，cursor 从0开始，返回的游标为0时迭代结束，迭代期间可能返回重复成员
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) SScan(key string, cursor uint64, args ...ScanOption) (uint64, []string, error) {
+	return replyScan(s.command(REDIS_COMMAND_SSCAN, scanArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(cursor), args)...))
+}
+
+func (s *redisClient) keysArgs(keys []string) redis_go.Args {
+	args := redis_go.Args{}
+	for _, key := range keys {
+		args = args.Add(s.GetKey(key))
+	}
+
+	return args
+}
+
+func scanArgs(args redis_go.Args, options []ScanOption) redis_go.Args {
+	if len(options) == 0 {
+		return args
+	}
+
+	if len(options[0].Match) > 0 {
+		args = args.Add("MATCH").Add(options[0].Match)
+	}
+
+	if options[0].Count > 0 {
+		args = args.Add("COUNT").Add(options[0].Count)
+	}
+
+	return args
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZADD
+ * score int, value interface{}, score int, value interface{} ...
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZAdd(key string, members ...interface{}) error {
+	_, err := s.command(REDIS_COMMAND_ZADD, redis_go.Args{}.Add(s.GetKey(key)).Add(members...)...)
+	return err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRANGE
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRange(key string, start, end int) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_ZRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRANGE WITHSCORES
+ * 按排名顺序返回成员及分数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRangeWithScore(key string, start, end int) ([]ZMember, error) {
+	return replyZMembers(s.command(REDIS_COMMAND_ZRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end).Add("WITHSCORES")...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRANGEBYSCORE
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRangeByScore(key string, min, max interface{}, limitArgs ...int) ([]string, error) {
+	var offset int = 0
+	var count int = -1
+	if len(limitArgs) > 0 {
+		offset = limitArgs[0]
+	}
+	if len(limitArgs) > 1 {
+		count = limitArgs[1]
+	}
+
+	args := redis_go.Args{}.Add(s.GetKey(key)).Add(min).Add(max).Add("LIMIT").Add(offset).Add(count)
+
+	return replyStrings(s.command(REDIS_COMMAND_ZRANGEBYSCORE, args...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZREVRANGE
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRevRange(key string, start, end int) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_ZREVRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZREVRANGEBYSCORE
+ * key, min, max, offset, count
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRevRangeByScore(key string, min, max interface{}, limitArgs ...int) ([]string, error) {
+	var offset int = 0
+	var count int = -1
+	if len(limitArgs) > 0 {
+		offset = limitArgs[0]
+	}
+	if len(limitArgs) > 1 {
+		count = limitArgs[1]
+	}
+
+	args := redis_go.Args{}.Add(s.GetKey(key)).Add(max).Add(min).Add("LIMIT").Add(offset).Add(count)
+
+	return replyStrings(s.command(REDIS_COMMAND_ZREVRANGEBYSCORE, args...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZREM
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRem(key string, members ...interface{}) error {
+	if _, err := s.command(REDIS_COMMAND_ZREM, redis_go.Args{}.Add(s.GetKey(key)).Add(members...)...); err != nil {
+		return err
+	}
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZREMRANGEBYSCORE
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRemRangeByScore(key string, min, max interface{}) error {
+	if _, err := s.command(REDIS_COMMAND_ZREMRANGEBYSCORE, redis_go.Args{}.Add(s.GetKey(key)).Add(min).Add(max)...); err != nil {
+		return err
+	}
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZREMRANGEBYRANK
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRemRangeByRank(key string, start, end int) error {
+	if _, err := s.command(REDIS_COMMAND_ZREMRANGEBYRANK, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...); err != nil {
+		return err
+	}
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZCARD
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZCard(key string) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_ZCARD, s.GetKey(key)))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZSCORE
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZScore(key string, member interface{}) (float64, error) {
+	return replyFloat64(s.command(REDIS_COMMAND_ZSCORE, s.GetKey(key), member))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRank
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRank(key string, member interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_ZRANK, redis_go.Args{}.Add(s.GetKey(key)).Add(member)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZREVRANK
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRevRank(key string, member interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_ZREVRANK, redis_go.Args{}.Add(s.GetKey(key)).Add(member)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZCOUNT
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZCount(key string, min, max interface{}) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_ZCOUNT, redis_go.Args{}.Add(s.GetKey(key)).Add(min).Add(max)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZADD [NX | XX] [GT | LT] [CH]
+ * 返回新增的成员数，Ch 为 true 时返回新增及分数变化的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZAddMembers(key string, members []ZMember, args ...ZAddOption) (int, error) {
+	commandArgs := s.zAddArgs(key, args)
+	for _, member := range members {
+		commandArgs = commandArgs.Add(member.Score).Add(member.Member)
+	}
+
+	return replyInt(s.command(REDIS_COMMAND_ZADD, commandArgs...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZADD INCR
+ * 增加成员分数并返回新分数，因 Nx | Xx | Gt | Lt 未执行时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZAddIncr(key string, member interface{}, increment float64, args ...ZAddOption) (float64, error) {
+	commandArgs := s.zAddArgs(key, args).Add("INCR").Add(increment).Add(member)
+
+	return replyFloat64(s.command(REDIS_COMMAND_ZADD, commandArgs...))
+}
+
+func (s *redisClient) zAddArgs(key string, args []ZAddOption) redis_go.Args {
+	commandArgs := redis_go.Args{}.Add(s.GetKey(key))
+	if len(args) == 0 {
+		return commandArgs
+	}
+
+	option := args[0]
+
+	if option.Nx {
+		commandArgs = commandArgs.Add("NX")
+	} else if option.Xx {
+		commandArgs = commandArgs.Add("XX")
+	}
+
+	if option.Gt {
+		commandArgs = commandArgs.Add("GT")
+	} else if option.Lt {
+		commandArgs = commandArgs.Add("LT")
+	}
+
+	if option.Ch {
+		commandArgs = commandArgs.Add("CH")
+	}
+
+	return commandArgs
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZINCRBY
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZIncrBy(key string, member interface{}, increment float64) (float64, error) {
+	return replyFloat64(s.command(REDIS_COMMAND_ZINCRBY, s.GetKey(key), increment, member))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZMSCORE
+ * 按参数顺序返回分数，不存在的成员分数为0
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZMScore(key string, members ...interface{}) ([]float64, error) {
+	return replyFloat64s(s.command(REDIS_COMMAND_ZMSCORE, redis_go.Args{}.Add(s.GetKey(key)).Add(members...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRANGE [BYSCORE | BYLEX] [REV] [LIMIT offset count]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRangeArgs(key string, start, stop interface{}, args ...ZRangeOption) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_ZRANGE, s.zRangeArgs(key, start, stop, args)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRANGE [BYSCORE] [REV] [LIMIT offset count] WITHSCORES
+ * Redis 不支持 BYLEX 与 WITHSCORES 同时使用
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRangeArgsWithScores(key string, start, stop interface{}, args ...ZRangeOption) ([]ZMember, error) {
+	return replyZMembers(s.command(REDIS_COMMAND_ZRANGE, s.zRangeArgs(key, start, stop, args).Add("WITHSCORES")...))
+}
+
+func (s *redisClient) zRangeArgs(key string, start, stop interface{}, args []ZRangeOption) redis_go.Args {
+	commandArgs := redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(stop)
+	if len(args) == 0 {
+		return commandArgs
+	}
+
+	option := args[0]
+
+	if option.ByScore {
+		commandArgs = commandArgs.Add("BYSCORE")
+	} else if option.ByLex {
+		commandArgs = commandArgs.Add("BYLEX")
+	}
+
+	if option.Rev {
+		commandArgs = commandArgs.Add("REV")
+	}
+
+	if (option.ByScore || option.ByLex) && (option.Offset > 0 || option.Count > 0) {
+		count := option.Count
+		if count <= 0 {
+			count = -1
+		}
+
+		commandArgs = commandArgs.Add("LIMIT").Add(option.Offset).Add(count)
+	}
+
+	return commandArgs
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRANGEBYLEX
+ * min | max: - | + | [member | (member
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRangeByLex(key string, min, max string, limitArgs ...int) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_ZRANGEBYLEX, zLimitArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(min).Add(max), limitArgs)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZREVRANGEBYLEX
+ * key, min, max, offset, count
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRevRangeByLex(key string, min, max string, limitArgs ...int) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_ZREVRANGEBYLEX, zLimitArgs(redis_go.Args{}.Add(s.GetKey(key)).Add(max).Add(min), limitArgs)...))
+}
+
+func zLimitArgs(args redis_go.Args, limitArgs []int) redis_go.Args {
+	if len(limitArgs) == 0 {
+		return args
+	}
+
+	count := -1
+	if len(limitArgs) > 1 {
+		count = limitArgs[1]
+	}
+
+	return args.Add("LIMIT").Add(limitArgs[0]).Add(count)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZPOPMIN
+ * 弹出分数最低的 count（默认1）个成员
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZPopMin(key string, countArgs ...int) ([]ZMember, error) {
+	return s.zPop(REDIS_COMMAND_ZPOPMIN, key, countArgs)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZPOPMAX
+ * 弹出分数最高的 count（默认1）个成员
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZPopMax(key string, countArgs ...int) ([]ZMember, error) {
+	return s.zPop(REDIS_COMMAND_ZPOPMAX, key, countArgs)
+}
+
+func (s *redisClient) zPop(commandName, key string, countArgs []int) ([]ZMember, error) {
+	args := redis_go.Args{}.Add(s.GetKey(key))
+	if len(countArgs) > 0 {
+		args = args.Add(countArgs[0])
+	}
+
+	return replyZMembers(s.command(commandName, args...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET BZPOPMIN
+ * 从第一个非空有序集合弹出分数最低的成员，返回（不含前缀的）Key及成员，
+ * timeout 为0时永久阻塞，超时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BZPopMin(timeout time.Duration, keys ...string) (string, ZMember, error) {
+	return s.bzPop(REDIS_COMMAND_BZPOPMIN, timeout, keys)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET BZPOPMAX
+ * 从第一个非空有序集合弹出分数最高的成员，返回（不含前缀的）Key及成员，
+ * timeout 为0时永久阻塞，超时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) BZPopMax(timeout time.Duration, keys ...string) (string, ZMember, error) {
+	return s.bzPop(REDIS_COMMAND_BZPOPMAX, timeout, keys)
+}
+
+func (s *redisClient) bzPop(commandName string, timeout time.Duration, keys []string) (string, ZMember, error) {
+	args := redis_go.Args{}
+	for _, key := range keys {
+		args = args.Add(s.GetKey(key))
+	}
+
+	values, err := replyStrings(s.blockingCommand(timeout, commandName, args.Add(timeout.Seconds())...))
+	if err != nil {
+		return "", ZMember{}, err
+	}
+
+	if len(values) != 3 {
+		return "", ZMember{}, ErrUnexpectedReply
+	}
+
+	members, err := replyZMembers([]interface{}{values[1], values[2]}, nil)
+	if err != nil {
+		return "", ZMember{}, err
+	}
+
+	return s.trimKey(values[0]), members[0], nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZUNIONSTORE
+ * 返回目标有序集合的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZUnionStore(destKey string, keys []string, args ...ZStoreOption) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_ZUNIONSTORE, s.zStoreArgs(destKey, keys, args)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZINTERSTORE
+ * 返回目标有序集合的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZInterStore(destKey string, keys []string, args ...ZStoreOption) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_ZINTERSTORE, s.zStoreArgs(destKey, keys, args)...))
+}
+
+func (s *redisClient) zStoreArgs(destKey string, keys []string, args []ZStoreOption) redis_go.Args {
+	commandArgs := redis_go.Args{}.Add(s.GetKey(destKey)).Add(s.zKeysArgs(keys)...)
+	if len(args) == 0 {
+		return commandArgs
+	}
+
+	option := args[0]
+
+	if len(option.Weights) > 0 {
+		commandArgs = commandArgs.Add("WEIGHTS")
+		for _, weight := range option.Weights {
+			commandArgs = commandArgs.Add(weight)
+		}
+	}
+
+	if len(option.Aggregate) > 0 {
+		commandArgs = commandArgs.Add("AGGREGATE").Add(option.Aggregate)
+	}
+
+	return commandArgs
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZDIFFSTORE
+ * 返回目标有序集合的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZDiffStore(destKey string, keys ...string) (int, error) {
+	return replyInt(s.command(REDIS_COMMAND_ZDIFFSTORE, redis_go.Args{}.Add(s.GetKey(destKey)).Add(s.zKeysArgs(keys)...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZDIFF
+ * 返回第一个有序集合中不在其它有序集合中的成员
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZDiff(keys ...string) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_ZDIFF, s.zKeysArgs(keys)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZDIFF WITHSCORES
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZDiffWithScores(keys ...string) ([]ZMember, error) {
+	return replyZMembers(s.command(REDIS_COMMAND_ZDIFF, s.zKeysArgs(keys).Add("WITHSCORES")...))
+}
+
+func (s *redisClient) zKeysArgs(keys []string) redis_go.Args {
+	args := redis_go.Args{}.Add(len(keys))
+	for _, key := range keys {
+		args = args.Add(s.GetKey(key))
+	}
+
+	return args
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRANDMEMBER
+ * count 为正数时返回不重复的成员，为负数时允许重复
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRandMember(key string, count int) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_ZRANDMEMBER, s.GetKey(key), count))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZSET ZRANDMEMBER WITHSCORES
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) ZRandMemberWithScores(key string, count int) ([]ZMember, error) {
+	return replyZMembers(s.command(REDIS_COMMAND_ZRANDMEMBER, s.GetKey(key), count, "WITHSCORES"))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Geo GEOADD
+ * 返回新增的成员数，Ch 时返回新增及坐标变化的成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) GeoAdd(key string, locations []GeoLocation, args ...GeoAddOption) (int, error) {
+	commandArgs := redis_go.Args{}.Add(s.GetKey(key))
+	if len(args) > 0 {
+		if args[0].Nx {
+			commandArgs = commandArgs.Add("NX")
+		}
+
+		if args[0].Xx {
+			commandArgs = commandArgs.Add("XX")
+		}
+
+		if args[0].Ch {
+			commandArgs = commandArgs.Add("CH")
+		}
+	}
+
+	for _, location := range locations {
+		commandArgs = commandArgs.Add(location.Longitude).Add(location.Latitude).Add(location.Name)
+	}
+
+	return replyInt(s.command(REDIS_COMMAND_GEOADD, commandArgs...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Geo GEOPOS
+ * 按成员顺序返回坐标，成员不存在时对应 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) GeoPos(key string, members ...string) ([]*GeoLocation, error) {
+	values, err := replyValues(s.command(REDIS_COMMAND_GEOPOS, redis_go.Args{}.Add(s.GetKey(key)).Add(stringsArgs(members)...)...))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(members) {
+		return nil, ErrUnexpectedReply
+	}
+
+	locations := make([]*GeoLocation, len(values))
+	for index, value := range values {
+		if value == nil {
+			continue
+		}
+
+		location := &GeoLocation{Name: members[index]}
+		if location.Longitude, location.Latitude, err = replyGeoCoord(value); err != nil {
+			return nil, err
+		}
+
+		locations[index] = location
+	}
+
+	return locations, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Geo GEODIST
+ * unitArgs: 距离单位 REDIS_GEO_UNIT_*，默认米，任一成员不存在时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) GeoDist(key, member1, member2 string, unitArgs ...string) (float64, error) {
+	args := redis_go.Args{}.Add(s.GetKey(key)).Add(member1).Add(member2)
+	if len(unitArgs) > 0 && len(unitArgs[0]) > 0 {
+		args = args.Add(unitArgs[0])
+	}
+
+	return replyFloat64(s.command(REDIS_COMMAND_GEODIST, args...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Geo GEOHASH
+ * 返回 11 位标准 geohash 字符串，成员不存在时对应空字符串
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) GeoHash(key string, members ...string) ([]string, error) {
+	return replyStrings(s.command(REDIS_COMMAND_GEOHASH, redis_go.Args{}.Add(s.GetKey(key)).Add(stringsArgs(members)...)...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Geo GEOSEARCH
+ * 返回区域内的成员，附带信息由 WithCoord | WithDist | WithHash 指定
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) GeoSearch(key string, query GeoSearchQuery) ([]GeoLocation, error) {
+	args := geoSearchArgs(redis_go.Args{}.Add(s.GetKey(key)), query)
+	if query.WithCoord {
+		args = args.Add("WITHCOORD")
+	}
+
+	if query.WithDist {
+		args = args.Add("WITHDIST")
+	}
+
+	if query.WithHash {
+		args = args.Add("WITHHASH")
+	}
+
+	values, err := replyValues(s.command(REDIS_COMMAND_GEOSEARCH, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]GeoLocation, 0, len(values))
+	for _, value := range values {
+		location, err := replyGeoLocation(value, query)
+		if err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, location)
+	}
+
+	return locations, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Set Union ints
+ * Geo GEOSEARCHSTORE
+ * 将区域内的成员存入目标键，返回存储的成员数
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SUnionInt(keys ...string) ([]int, error) {
-	newKeys := make([]interface{}, 0)
-
-	for _, key := range keys {
-		newKeys = append(newKeys, s.GetKey(key))
+func (s *redisClient) GeoSearchStore(destKey, key string, query GeoSearchQuery) (int, error) {
+	args := geoSearchArgs(redis_go.Args{}.Add(s.GetKey(destKey)).Add(s.GetKey(key)), query)
+	if query.StoreDist {
+		args = args.Add("STOREDIST")
 	}
 
-	return redis_go.Ints(s.command(REDIS_COMMAND_SUNION, redis_go.Args{}.Add(newKeys...)...))
+	return replyInt(s.command(REDIS_COMMAND_GEOSEARCHSTORE, args...))
 }
 
-/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Set Union strings
- * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SUnion(keys ...string) ([]string, error) {
-	newKeys := make([]interface{}, 0)
+func geoSearchArgs(args redis_go.Args, query GeoSearchQuery) redis_go.Args {
+	if len(query.Member) > 0 {
+		args = args.Add("FROMMEMBER").Add(query.Member)
+	} else {
+		args = args.Add("FROMLONLAT").Add(query.Longitude).Add(query.Latitude)
+	}
 
-	for _, key := range keys {
-		newKeys = append(newKeys, s.GetKey(key))
+	unit := query.Unit
+	if len(unit) == 0 {
+		unit = REDIS_GEO_UNIT_M
 	}
 
-	return redis_go.Strings(s.command(REDIS_COMMAND_SUNION, redis_go.Args{}.Add(newKeys...)...))
-}
+	if query.Radius > 0 {
+		args = args.Add("BYRADIUS").Add(query.Radius).Add(unit)
+	} else {
+		args = args.Add("BYBOX").Add(query.Width).Add(query.Height).Add(unit)
+	}
 
-/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Set Inter ints
- * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SInterInt(keys ...string) ([]int, error) {
-	newKeys := make([]interface{}, 0)
+	if len(query.Sort) > 0 {
+		args = args.Add(query.Sort)
+	}
 
-	for _, key := range keys {
-		newKeys = append(newKeys, s.GetKey(key))
+	if query.Count > 0 {
+		args = args.Add("COUNT").Add(query.Count)
+		if query.Any {
+			args = args.Add("ANY")
+		}
 	}
 
-	return redis_go.Ints(s.command(REDIS_COMMAND_SINTER, redis_go.Args{}.Add(newKeys...)...))
+	return args
 }
 
-/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Set Inter strings
- * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SInter(keys ...string) ([]string, error) {
-	newKeys := make([]interface{}, 0)
-
-	for _, key := range keys {
-		newKeys = append(newKeys, s.GetKey(key))
+func stringsArgs(values []string) redis_go.Args {
+	args := make(redis_go.Args, 0, len(values))
+	for _, value := range values {
+		args = append(args, value)
 	}
 
-	return redis_go.Strings(s.command(REDIS_COMMAND_SINTER, redis_go.Args{}.Add(newKeys...)...))
+	return args
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Set Diff ints
+ * JSON JSON.SET
+ * value 序列化为 JSON 后写入 path，路径以 $ 开头时为 JSONPath，否则为旧式路径
+ * 新键只能写入根路径，未设置（Nx | Xx 条件不满足或父节点不存在）时返回 false
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SDiffInt(keys ...string) ([]int, error) {
-	newKeys := make([]interface{}, 0)
+func (s *redisClient) JSONSet(key, path string, value interface{}, args ...JSONSetOption) (bool, error) {
+	jsonString, err := glib.ToJson(value)
+	if err != nil {
+		return false, err
+	}
 
-	for _, key := range keys {
-		newKeys = append(newKeys, s.GetKey(key))
+	commandArgs := redis_go.Args{}.Add(s.GetKey(key)).Add(path).Add(jsonString)
+	if len(args) > 0 {
+		if args[0].Nx {
+			commandArgs = commandArgs.Add("NX")
+		}
+
+		if args[0].Xx {
+			commandArgs = commandArgs.Add("XX")
+		}
+	}
+
+	reply, err := s.command(REDIS_COMMAND_JSON_SET, commandArgs...)
+	if err != nil {
+		return false, err
 	}
 
-	return redis_go.Ints(s.command(REDIS_COMMAND_SDIFF, redis_go.Args{}.Add(newKeys...)...))
+	return reply != nil, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Set Diff strings
+ * JSON JSON.GET
+ * 返回紧凑格式的 JSON，不指定路径时返回整个文档
+ * JSONPath 返回全部匹配的数组，多个路径时返回以路径为键的对象，键不存在时返回 ErrNotFound
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) SDiff(keys ...string) ([]string, error) {
-	newKeys := make([]interface{}, 0)
-
-	for _, key := range keys {
-		newKeys = append(newKeys, s.GetKey(key))
-	}
-
-	return redis_go.Strings(s.command(REDIS_COMMAND_SDIFF, redis_go.Args{}.Add(newKeys...)...))
+func (s *redisClient) JSONGet(key string, paths ...string) ([]byte, error) {
+	return replyBytes(s.command(REDIS_COMMAND_JSON_GET, redis_go.Args{}.Add(s.GetKey(key)).Add(stringsArgs(paths)...)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZADD
- * score int, value interface{}, score int, value interface{} ...
+ * JSON JSON.GET 并反序列化到 structData
+ * 读取单个值时使用旧式路径（如 . | .profile），JSONPath 的结果为数组
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZAdd(key string, members ...interface{}) error {
-	_, err := s.command(REDIS_COMMAND_ZADD, redis_go.Args{}.Add(s.GetKey(key)).Add(members...)...)
-	return err
+func (s *redisClient) JSONGetData(key, path string, structData interface{}) error {
+	data, err := s.JSONGet(key, path)
+	if err != nil {
+		return err
+	}
+
+	return glib.FromJson(string(data), structData)
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZRANGE
+ * JSON JSON.MGET
+ * 按键顺序返回各文档在 path 的 JSON，键不存在时对应 nil
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRange(key string, start, end int) ([]string, error) {
-	return redis_go.Strings(s.command(REDIS_COMMAND_ZRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...))
+func (s *redisClient) JSONMGet(path string, keys ...string) ([][]byte, error) {
+	return replyByteSlices(s.command(REDIS_COMMAND_JSON_MGET, s.keysArgs(keys).Add(path)...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZRANGE WITHSCORES
+ * JSON JSON.DEL
+ * 返回删除的值个数，不指定路径或为根路径时删除整个键
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRangeWithScore(key string, start, end int) (map[string]int, error) {
-	return redis_go.IntMap(s.command(REDIS_COMMAND_ZRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end).Add("WITHSCORES")...))
+func (s *redisClient) JSONDel(key string, pathArgs ...string) (int, error) {
+	args := redis_go.Args{}.Add(s.GetKey(key))
+	if len(pathArgs) > 0 && len(pathArgs[0]) > 0 {
+		args = args.Add(pathArgs[0])
+	}
+
+	return replyInt(s.command(REDIS_COMMAND_JSON_DEL, args...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZRANGEBYSCORE
+ * JSON JSON.NUMINCRBY
+ * 返回各匹配值增加后的结果，非数值对应 nil；旧式路径仅返回第一个匹配
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRangeByScore(key string, min, max interface{}, limitArgs ...int) ([]string, error) {
-	var offset int = 0
-	var count int = -1
-	if len(limitArgs) > 0 {
-		offset = limitArgs[0]
+func (s *redisClient) JSONNumIncrBy(key, path string, value float64) ([]*float64, error) {
+	data, err := replyBytes(s.command(REDIS_COMMAND_JSON_NUMINCRBY, s.GetKey(key), path, value))
+	if err != nil {
+		return nil, err
 	}
-	if len(limitArgs) > 1 {
-		count = limitArgs[1]
+
+	if !strings.HasPrefix(string(data), "[") {
+		data = append(append([]byte("["), data...), ']')
 	}
 
-	args := redis_go.Args{}.Add(s.GetKey(key)).Add(min).Add(max).Add("LIMIT").Add(offset).Add(count)
+	results := make([]*float64, 0)
+	if err := glib.FromJson(string(data), &results); err != nil {
+		return nil, err
+	}
 
-	return redis_go.Strings(s.command(REDIS_COMMAND_ZRANGEBYSCORE, args...))
+	return results, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZREVRANGE
+ * JSON JSON.ARRAPPEND
+ * values 序列化为 JSON 后追加到数组，返回各数组的新长度，非数组对应 nil；旧式路径仅返回第一个匹配
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRevRange(key string, start, end int) ([]string, error) {
-	return redis_go.Strings(s.command(REDIS_COMMAND_ZREVRANGE, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...))
+func (s *redisClient) JSONArrAppend(key, path string, values ...interface{}) ([]*int64, error) {
+	args := redis_go.Args{}.Add(s.GetKey(key)).Add(path)
+	for _, value := range values {
+		jsonString, err := glib.ToJson(value)
+		if err != nil {
+			return nil, err
+		}
+
+		args = args.Add(jsonString)
+	}
+
+	reply, err := s.command(REDIS_COMMAND_JSON_ARRAPPEND, args...)
+	if length, isOk := reply.(int64); isOk && err == nil {
+		return []*int64{&length}, nil
+	}
+
+	return replyNullableInt64s(reply, err)
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZREVRANGEBYSCORE
- * key, min, max, offset, count
+ * Search FT.CREATE
+ * 创建哈希文档索引，索引名及文档前缀均在客户端前缀之下
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRevRangeByScore(key string, min, max interface{}, limitArgs ...int) ([]string, error) {
-	var offset int = 0
-	var count int = -1
-	if len(limitArgs) > 0 {
-		offset = limitArgs[0]
+func (s *redisClient) FTCreate(index string, fields []SearchField, args ...SearchIndexOption) error {
+	option := SearchIndexOption{}
+	if len(args) > 0 {
+		option = args[0]
 	}
-	if len(limitArgs) > 1 {
-		count = limitArgs[1]
+
+	commandArgs := redis_go.Args{}.Add(s.GetKey(index)).Add("ON").Add("HASH")
+	if len(option.Prefixes) > 0 {
+		commandArgs = commandArgs.Add("PREFIX").Add(len(option.Prefixes)).Add(s.keysArgs(option.Prefixes)...)
+	} else if len(s.prefixKey) > 0 {
+		commandArgs = commandArgs.Add("PREFIX").Add(1).Add(s.prefixKey)
 	}
 
-	args := redis_go.Args{}.Add(s.GetKey(key)).Add(max).Add(min).Add("LIMIT").Add(offset).Add(count)
+	if len(option.Language) > 0 {
+		commandArgs = commandArgs.Add("LANGUAGE").Add(option.Language)
+	}
 
-	return redis_go.Strings(s.command(REDIS_COMMAND_ZREVRANGEBYSCORE, args...))
-}
+	if option.Stopwords != nil {
+		commandArgs = commandArgs.Add("STOPWORDS").Add(len(option.Stopwords)).Add(stringsArgs(option.Stopwords)...)
+	}
 
-/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZREM
- * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRem(key string, members ...interface{}) error {
-	if _, err := s.command(REDIS_COMMAND_ZREM, redis_go.Args{}.Add(s.GetKey(key)).Add(members...)...); err != nil {
-		return err
+	commandArgs = commandArgs.Add("SCHEMA")
+	for _, field := range fields {
+		commandArgs = searchFieldArgs(commandArgs, field)
 	}
-	return nil
+
+	_, err := s.command(REDIS_COMMAND_FT_CREATE, commandArgs...)
+
+	return err
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZREMRANGEBYSCORE
+ * Search FT.DROPINDEX
+ * deleteArgs: 是否一并删除索引覆盖的文档，默认保留
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRemRangeByScore(key string, min, max interface{}) error {
-	if _, err := s.command(REDIS_COMMAND_ZREMRANGEBYSCORE, redis_go.Args{}.Add(s.GetKey(key)).Add(min).Add(max)...); err != nil {
-		return err
+func (s *redisClient) FTDropIndex(index string, deleteArgs ...bool) error {
+	args := redis_go.Args{}.Add(s.GetKey(index))
+	if len(deleteArgs) > 0 && deleteArgs[0] {
+		args = args.Add("DD")
 	}
-	return nil
+
+	_, err := s.command(REDIS_COMMAND_FT_DROPINDEX, args...)
+
+	return err
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZREMRANGEBYRANK
+ * Search FT.INFO
+ * 以下 FT 命令直接解析 RESP3 的映射回复，不经 resp2Reply 转换
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRemRangeByRank(key string, start, end int) error {
-	if _, err := s.command(REDIS_COMMAND_ZREMRANGEBYRANK, redis_go.Args{}.Add(s.GetKey(key)).Add(start).Add(end)...); err != nil {
-		return err
+func (s *redisClient) FTInfo(index string) (SearchIndexInfo, error) {
+	info, err := replySearchIndexInfo(s.do(REDIS_COMMAND_FT_INFO, s.GetKey(index)))
+	if err != nil {
+		return info, err
 	}
-	return nil
-}
 
-/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZCARD
- * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZCard(key string) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_ZCARD, s.GetKey(key)))
+	info.Name = s.trimKey(info.Name)
+	for position, prefix := range info.Prefixes {
+		info.Prefixes[position] = s.trimKey(prefix)
+	}
+
+	return info, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZSCORE
+ * Search FT.SEARCH
+ * query 为 nil 时返回前10个文档，可通过 ScanDocuments 将结果转换为结构体
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZScore(key string, member interface{}) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_ZSCORE, s.GetKey(key), member))
+func (s *redisClient) FTSearch(index string, query *SearchQuery) (SearchResult, error) {
+	if query == nil {
+		query = NewSearchQuery()
+	}
+
+	reply, err := s.do(REDIS_COMMAND_FT_SEARCH, query.args(redis_go.Args{}.Add(s.GetKey(index)))...)
+
+	result, err := replySearchResult(reply, err, query)
+	if err != nil {
+		return result, err
+	}
+
+	for position := range result.Documents {
+		result.Documents[position].Id = s.trimKey(result.Documents[position].Id)
+	}
+
+	return result, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZRank
+ * Search FT.AGGREGATE
+ * 返回聚合后的行，数值以字符串表示
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRank(key string, member interface{}) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_ZRANK, redis_go.Args{}.Add(s.GetKey(key)).Add(member)...))
+func (s *redisClient) FTAggregate(index string, query *AggregateQuery) ([]map[string]string, error) {
+	if query == nil {
+		query = NewAggregateQuery()
+	}
+
+	return replyAggregateRows(s.do(REDIS_COMMAND_FT_AGGREGATE, query.args(redis_go.Args{}.Add(s.GetKey(index)))...))
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZREVRANK
+ * Pipeline MULTI and EXEC
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZRevRank(key string, member interface{}) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_ZREVRANK, redis_go.Args{}.Add(s.GetKey(key)).Add(member)...))
+func (s *redisClient) Pipeline(commands []map[string][]interface{}, watchKeys ...interface{}) (interface{}, error) {
+	pipeline := make([]*Command, 0, len(commands))
+	for _, commandLine := range commands {
+		for cmd, args := range commandLine {
+			pipeline = append(pipeline, s.newCommand(cmd, args))
+		}
+	}
+
+	var reply interface{}
+	err := s.processPipeline(pipeline, func(pipeline []*Command) error {
+		var err error
+		reply, err = s.executePipeline(pipeline, watchKeys)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reply, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * ZSET ZCOUNT
+ * 执行 MULTI/EXEC 事务，按重试策略重试，并将结果回填到各命令
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) ZCount(key string, min, max interface{}) (int, error) {
-	return redis_go.Int(s.command(REDIS_COMMAND_ZCOUNT, redis_go.Args{}.Add(s.GetKey(key)).Add(min).Add(max)...))
+func (s *redisClient) executePipeline(commands []*Command, watchKeys []interface{}) (interface{}, error) {
+	isIdempotent := true
+	for _, command := range commands {
+		isIdempotent = isIdempotent && isIdempotentCommand(command.Name, command.Args)
+	}
+
+	for attempt := 1; ; attempt++ {
+		for _, command := range commands {
+			command.Attempts = attempt
+		}
+
+		reply, err := s.executePipelineOnce(commands, watchKeys)
+		if !errors.Is(err, ErrTxAborted) && s.retry.shouldRetry(attempt, isIdempotent, err) {
+			if err = s.retry.wait(s.context(), attempt); err == nil {
+				continue
+			}
+			reply = nil
+		}
+
+		replies, _ := reply.([]interface{})
+		for index, command := range commands {
+			command.Err = err
+			if index < len(replies) {
+				command.Reply = replies[index]
+				if replyErr, isOk := replies[index].(redis_go.Error); isOk {
+					command.Err = newRedisError(command.Name, command.Args, replyErr)
+				}
+			}
+		}
+
+		return reply, err
+	}
 }
 
-/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
- * Pipeline MULTI and EXEC
- * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func (s *redisClient) Pipeline(commands []map[string][]interface{}, watchKeys ...interface{}) (interface{}, error) {
-	redisPool := s.pool.Get()
+func (s *redisClient) executePipelineOnce(commands []*Command, watchKeys []interface{}) (interface{}, error) {
+	if err := s.breaker.allow(s.probe); err != nil {
+		return nil, newRedisError(REDIS_COMMAND_EXEC, nil, err)
+	}
+
+	redisPool, poolWait := s.getConn()
 	defer redisPool.Close()
 
+	for _, command := range commands {
+		command.PoolWait += poolWait
+	}
+
 	if len(watchKeys) > 0 {
 		redisPool.Send(REDIS_COMMAND_WATCH, watchKeys...)
 	}
 
 	redisPool.Send(REDIS_COMMAND_MULTI)
 
-	for _, commandLine := range commands {
-		for cmd, args := range commandLine {
-			redisPool.Send(cmd, args...)
-		}
+	for _, command := range commands {
+		redisPool.Send(command.Name, command.Args...)
+	}
+
+	reply, err := redisPool.Do(REDIS_COMMAND_EXEC)
+	err = newRedisError(REDIS_COMMAND_EXEC, nil, err)
+	s.breaker.report(err)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return redisPool.Do(REDIS_COMMAND_EXEC)
+	if reply == nil {
+		return nil, newRedisError(REDIS_COMMAND_EXEC, nil, ErrTxAborted)
+	}
+
+	if s.protocol == REDIS_PROTOCOL_RESP3 {
+		reply = resp2Reply(reply, false)
+	}
+
+	return reply, nil
 }
 
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -1025,6 +2682,28 @@ func (s *redisClient) FlushAll() error {
 	return nil
 }
 
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 本地缓存统计
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) CacheStats() CacheStats {
+	if s.cache == nil {
+		return CacheStats{}
+	}
+
+	return s.cache.stats()
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 关闭客户端，释放连接池及本地缓存
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) Close() error {
+	if s.cache != nil {
+		s.cache.close()
+	}
+
+	return s.pool.Close()
+}
+
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * redigo帮助方法包装
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
@@ -1108,6 +2787,13 @@ func (s *redisClient) GetKey(key string) string {
 	return key
 }
 
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 去除服务器返回Key的前缀
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) trimKey(key string) string {
+	return strings.TrimPrefix(key, s.prefixKey)
+}
+
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * 获取对象Key
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
@@ -1126,13 +2812,21 @@ func (s *redisClient) GetObjectKey(model interface{}) string {
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * 获取 RedisPool
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func newRedisPool(address, password string, db, timeout int) *redis_go.Pool {
+func newRedisPool(dialFunc func() (redis_go.Conn, error), maxIdle, maxActive, idleTimeout int, wait bool) *redis_go.Pool {
+	if maxIdle <= 0 {
+		maxIdle = 8
+	}
+
+	if idleTimeout <= 0 {
+		idleTimeout = 240
+	}
+
 	return &redis_go.Pool{
-		MaxIdle:     8,
-		IdleTimeout: 240 * time.Second,
-		Dial: func() (redis_go.Conn, error) {
-			return dial(address, password, db, timeout)
-		},
+		MaxIdle:     maxIdle,
+		MaxActive:   maxActive,
+		IdleTimeout: time.Duration(idleTimeout) * time.Second,
+		Wait:        wait,
+		Dial:        dialFunc,
 		TestOnBorrow: func(conn redis_go.Conn, t time.Time) error {
 			if time.Since(t) < time.Minute {
 				return nil
@@ -1167,23 +2861,95 @@ func dial_old(address, password string, timeout int) (redis_go.Conn, error) {
 	return conn, err
 }
 
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 使用客户端配置链接 Redis 服务器
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) dial() (redis_go.Conn, error) {
+	return s.dialWithPush(s.pushHandler)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 优先使用 RESP3 链接，服务器不支持时回退到 RESP2
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) dialWithPush(pushHandler func(message PushMessage)) (redis_go.Conn, error) {
+	info := &DialInfo{
+		Network:    "tcp",
+		Address:    s.address,
+		Username:   s.username,
+		Password:   s.password,
+		ClientName: s.clientName,
+		Db:         s.db,
+	}
+
+	var conn redis_go.Conn
+	err := s.processDial(info, func(info *DialInfo) error {
+		var err error
+		if s.protocol == REDIS_PROTOCOL_RESP3 {
+			conn, err = dialResp3(info.Network, info.Address, info.Username, info.Password, info.ClientName, info.Db, s.timeout, s.option.NetDial, pushHandler)
+			if err != errResp3Unsupported {
+				return err
+			}
+		}
+
+		conn, err = dial(info.Network, info.Address, info.Username, info.Password, info.ClientName, info.Db, s.timeout, s.option.NetDial)
+		return err
+	})
+
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
 /* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
  * 链接 Redis 服务器
  * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
-func dial(address, password string, db, timeout int) (redis_go.Conn, error) {
-	conn, err := redis_go.Dial(
-		"tcp",
-		address,
-		redis_go.DialConnectTimeout(time.Duration(timeout)*time.Second),
-		redis_go.DialReadTimeout(time.Duration(timeout)*time.Second),
-		redis_go.DialWriteTimeout(time.Duration(timeout)*time.Second),
-		redis_go.DialPassword(password),
-		redis_go.DialDatabase(db),
-	)
+func dial(network, address, username, password, clientName string, db, timeout int, netDial func(network, address string) (net.Conn, error)) (redis_go.Conn, error) {
+	options := []redis_go.DialOption{
+		redis_go.DialConnectTimeout(time.Duration(timeout) * time.Second),
+		redis_go.DialReadTimeout(time.Duration(timeout) * time.Second),
+		redis_go.DialWriteTimeout(time.Duration(timeout) * time.Second),
+	}
+
+	if netDial != nil {
+		options = append(options, redis_go.DialNetDial(netDial))
+	}
+
+	conn, err := redis_go.Dial(network, address, options...)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if len(password) > 0 {
+		args := redis_go.Args{}
+		if len(username) > 0 {
+			args = args.Add(username)
+		}
+
+		if _, err := conn.Do(REDIS_COMMAND_AUTH, args.Add(password)...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if len(clientName) > 0 {
+		if _, err := conn.Do(REDIS_COMMAND_CLIENT, "SETNAME", clientName); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if db != 0 {
+		if _, err := conn.Do(REDIS_COMMAND_SELECT, db); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
 	return conn, err
 }
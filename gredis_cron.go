@@ -0,0 +1,203 @@
+package gredis
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ================================================================================
+ * Cron schedule
+ * 标准5字段 cron 表达式：分 时 日 月 周（0-7，0和7均为周日），
+ * 字段支持星号、a-b、a,b 及以 /n 指定步长，
+ * 另支持 @yearly | @monthly | @weekly | @daily | @hourly | @every <duration>
+ * 日与周同时受限时任一匹配即可（与 Vixie cron 一致）
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+var (
+	ErrInvalidCron = errors.New("gredis: invalid cron spec")
+)
+
+var (
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+
+	cronBounds = [5][2]int{
+		{0, 59},
+		{0, 23},
+		{1, 31},
+		{1, 12},
+		{0, 7},
+	}
+)
+
+type (
+	cronSchedule struct {
+		minutes       uint64
+		hours         uint64
+		days          uint64
+		months        uint64
+		weekdays      uint64
+		isDayStar     bool
+		isWeekdayStar bool
+		every         time.Duration
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析 cron 表达式
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func parseCron(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil || every < time.Second {
+			return nil, ErrInvalidCron
+		}
+
+		return &cronSchedule{every: every}, nil
+	}
+
+	if macro, isOk := cronMacros[spec]; isOk {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, ErrInvalidCron
+	}
+
+	values := [5]uint64{}
+	for index, field := range fields {
+		value, err := parseCronField(field, cronBounds[index][0], cronBounds[index][1])
+		if err != nil {
+			return nil, err
+		}
+		values[index] = value
+	}
+
+	// 7 与 0 均表示周日
+	if values[4]&(1<<7) != 0 {
+		values[4] |= 1
+	}
+
+	return &cronSchedule{
+		minutes:       values[0],
+		hours:         values[1],
+		days:          values[2],
+		months:        values[3],
+		weekdays:      values[4],
+		isDayStar:     strings.HasPrefix(fields[2], "*"),
+		isWeekdayStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	bits := uint64(0)
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if index := strings.IndexByte(part, '/'); index >= 0 {
+			value, err := strconv.Atoi(part[index+1:])
+			if err != nil || value <= 0 {
+				return 0, ErrInvalidCron
+			}
+			step, part = value, part[:index]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, ErrInvalidCron
+			}
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, ErrInvalidCron
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, ErrInvalidCron
+			}
+
+			start, end = value, value
+			if step > 1 {
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, ErrInvalidCron
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取 t 之后（不含 t）的下一次执行时间，5年内无匹配时返回零值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *cronSchedule) next(t time.Time) time.Time {
+	if c.every > 0 {
+		return t.Add(c.every).Truncate(time.Second)
+	}
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c *cronSchedule) matchDay(t time.Time) bool {
+	isDay := c.days&(1<<uint(t.Day())) != 0
+	isWeekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if c.isDayStar || c.isWeekdayStar {
+		return isDay && isWeekday
+	}
+
+	return isDay || isWeekday
+}
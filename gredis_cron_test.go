@@ -0,0 +1,42 @@
+package gredis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, 1, 31, 10, 30, 15, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 31, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 7", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * 1", time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * */1", time.Date(2024, 2, 13, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2024, 1, 31, 10, 31, 45, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		cron, err := parseCron(c.spec)
+		if err != nil {
+			t.Fatalf("parseCron(%q) err = %v", c.spec, err)
+		}
+
+		if next := cron.next(from); !next.Equal(c.want) {
+			t.Errorf("next(%q) = %v, want %v", c.spec, next, c.want)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "@every 1ms"} {
+		if _, err := parseCron(spec); err != ErrInvalidCron {
+			t.Errorf("parseCron(%q) err = %v, want ErrInvalidCron", spec, err)
+		}
+	}
+}
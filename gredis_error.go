@@ -0,0 +1,185 @@
+package gredis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ================================================================================
+ * Redis errors
+ * 哨兵错误及结构化的 RedisError，支持 errors.Is / errors.As
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+var (
+	ErrNotFound        = errors.New("gredis: not found")
+	ErrWrongType       = errors.New("gredis: wrong type")
+	ErrTxAborted       = errors.New("gredis: transaction aborted")
+	ErrPoolExhausted   = errors.New("gredis: connection pool exhausted")
+	ErrMoved           = errors.New("gredis: key moved")
+	ErrAsk             = errors.New("gredis: key ask redirect")
+	ErrClusterDown     = errors.New("gredis: cluster down")
+	ErrLoading         = errors.New("gredis: server loading")
+	ErrBusy            = errors.New("gredis: server busy")
+	ErrTryAgain        = errors.New("gredis: try again")
+	ErrNoScript        = errors.New("gredis: no matching script")
+	ErrReadOnly        = errors.New("gredis: read only replica")
+	ErrNoAuth          = errors.New("gredis: authentication required")
+	ErrWrongPass       = errors.New("gredis: invalid username-password pair")
+	ErrUnexpectedReply = errors.New("gredis: unexpected reply")
+	ErrInvalidTtl      = errors.New("gredis: ttl not representable in the command precision")
+	ErrInvalidArgs     = errors.New("gredis: invalid command arguments")
+)
+
+var (
+	redisErrorPrefixes = map[string]error{
+		"WRONGTYPE":   ErrWrongType,
+		"EXECABORT":   ErrTxAborted,
+		"MOVED":       ErrMoved,
+		"ASK":         ErrAsk,
+		"CLUSTERDOWN": ErrClusterDown,
+		"LOADING":     ErrLoading,
+		"BUSY":        ErrBusy,
+		"TRYAGAIN":    ErrTryAgain,
+		"NOSCRIPT":    ErrNoScript,
+		"READONLY":    ErrReadOnly,
+		"NOAUTH":      ErrNoAuth,
+		"WRONGPASS":   ErrWrongPass,
+	}
+
+	keylessCommands = map[string]bool{
+		REDIS_COMMAND_AUTH:     true,
+		REDIS_COMMAND_HELLO:    true,
+		REDIS_COMMAND_CLIENT:   true,
+		REDIS_COMMAND_INFO:     true,
+		REDIS_COMMAND_PING:     true,
+		REDIS_COMMAND_SELECT:   true,
+		REDIS_COMMAND_SELECTDB: true,
+		REDIS_COMMAND_KEYS:     true,
+		REDIS_COMMAND_MULTI:    true,
+		REDIS_COMMAND_EXEC:     true,
+		REDIS_COMMAND_BGSAVE:   true,
+		REDIS_COMMAND_FLUSHDB:  true,
+		REDIS_COMMAND_FLUSHALL: true,
+		REDIS_COMMAND_EVAL:     true,
+		REDIS_COMMAND_EVALSHA:  true,
+		REDIS_COMMAND_BITOP:    true,
+	}
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 命令执行错误
+	 * Command: 命令名称
+	 * Key: 命令的首个Key（已包含前缀）
+	 * Prefix: 服务器错误前缀，如 ERR | WRONGTYPE | MOVED
+	 * Err: 底层错误（服务器错误、网络错误或哨兵错误）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	RedisError struct {
+		Command string
+		Key     string
+		Prefix  string
+		Err     error
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 包装命令执行错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func newRedisError(commandName string, args []interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var redisError *RedisError
+	if errors.As(err, &redisError) {
+		return err
+	}
+
+	redisError = &RedisError{
+		Command: commandName,
+		Key:     commandKey(commandName, args),
+		Err:     err,
+	}
+
+	var serverError redis_go.Error
+	if errors.As(err, &serverError) {
+		message := string(serverError)
+		if index := strings.IndexByte(message, ' '); index > 0 {
+			redisError.Prefix = message[:index]
+		} else {
+			redisError.Prefix = message
+		}
+	}
+
+	return redisError
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取命令的首个Key，无Key的命令返回空字符串
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func commandKey(commandName string, args []interface{}) string {
+	if len(args) == 0 || keylessCommands[strings.ToUpper(commandName)] {
+		return ""
+	}
+
+	switch key := args[0].(type) {
+	case string:
+		return key
+	case []byte:
+		return string(key)
+	}
+
+	return ""
+}
+
+func (e *RedisError) Error() string {
+	message := e.Err.Error()
+
+	if len(e.Key) > 0 {
+		return fmt.Sprintf("gredis: %s %s: %s", e.Command, e.Key, message)
+	}
+
+	return fmt.Sprintf("gredis: %s: %s", e.Command, message)
+}
+
+func (e *RedisError) Unwrap() error {
+	return e.Err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按服务器错误前缀匹配哨兵错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (e *RedisError) Is(target error) bool {
+	if sentinel, isOk := redisErrorPrefixes[e.Prefix]; isOk && sentinel == target {
+		return true
+	}
+
+	return target == ErrPoolExhausted && e.Err == redis_go.ErrPoolExhausted
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换回复类型错误：nil 回复为 ErrNotFound，类型不匹配为 ErrUnexpectedReply
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if err == redis_go.ErrNil {
+		return ErrNotFound
+	}
+
+	var redisError *RedisError
+	if errors.As(err, &redisError) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %v", ErrUnexpectedReply, err)
+}
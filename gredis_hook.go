@@ -0,0 +1,244 @@
+package gredis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/* ================================================================================
+ * Redis command hooks
+ * 命令、管道及拨号的扩展点，用于日志、指标、链路追踪、认证刷新、Key改写及故障注入
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 钩子接口
+	 * Before* 按注册顺序调用，返回错误时终止执行，After* 按注册的逆序调用
+	 * After* 返回的错误将替换命令的执行错误
+	 * 仅需部分扩展点时可嵌入 EmptyHook
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	Hook interface {
+		BeforeProcess(ctx context.Context, command *Command) (context.Context, error)
+		AfterProcess(ctx context.Context, command *Command) error
+		BeforeProcessPipeline(ctx context.Context, commands []*Command) (context.Context, error)
+		AfterProcessPipeline(ctx context.Context, commands []*Command) error
+		BeforeDial(ctx context.Context, dial *DialInfo) (context.Context, error)
+		AfterDial(ctx context.Context, dial *DialInfo) error
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 命令信息
+	 * Name | Args: 命令及参数，BeforeProcess 中修改后以修改后的值执行
+	 * Key: 命令的首个Key（已包含前缀）
+	 * Prefix | ClientName: 客户端的Key前缀及连接名称
+	 * Reply | Err: 执行结果，AfterProcess 中可用
+	 * PoolWait: 从连接池获取连接的累计耗时
+	 * Attempts: 实际执行次数（含重试）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	Command struct {
+		Name       string
+		Args       []interface{}
+		Key        string
+		Prefix     string
+		ClientName string
+		Address    string
+		Db         int
+		Reply      interface{}
+		Err        error
+		StartTime  time.Time
+		Duration   time.Duration
+		PoolWait   time.Duration
+		Attempts   int
+
+		isBlocking  bool
+		readTimeout time.Duration
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 拨号信息，BeforeDial 中修改的网络类型、地址、认证信息、连接名称及数据库均用于本次拨号
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	DialInfo struct {
+		Network    string
+		Address    string
+		Username   string
+		Password   string
+		ClientName string
+		Db         int
+		Err        error
+		StartTime  time.Time
+		Duration   time.Duration
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 空钩子，所有扩展点均不做处理
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	EmptyHook struct{}
+
+	hookChain struct {
+		mutex sync.RWMutex
+		hooks []Hook
+	}
+)
+
+func (EmptyHook) BeforeProcess(ctx context.Context, command *Command) (context.Context, error) {
+	return ctx, nil
+}
+
+func (EmptyHook) AfterProcess(ctx context.Context, command *Command) error {
+	return nil
+}
+
+func (EmptyHook) BeforeProcessPipeline(ctx context.Context, commands []*Command) (context.Context, error) {
+	return ctx, nil
+}
+
+func (EmptyHook) AfterProcessPipeline(ctx context.Context, commands []*Command) error {
+	return nil
+}
+
+func (EmptyHook) BeforeDial(ctx context.Context, dial *DialInfo) (context.Context, error) {
+	return ctx, nil
+}
+
+func (EmptyHook) AfterDial(ctx context.Context, dial *DialInfo) error {
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 注册钩子
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) AddHook(hook Hook) {
+	s.hooks.add(hook)
+}
+
+func newHookChain(hooks []Hook) *hookChain {
+	return &hookChain{
+		hooks: hooks,
+	}
+}
+
+func (c *hookChain) add(hook Hook) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	hooks := make([]Hook, 0, len(c.hooks)+1)
+	hooks = append(hooks, c.hooks...)
+	c.hooks = append(hooks, hook)
+}
+
+func (c *hookChain) get() []Hook {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.hooks
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 在钩子链中执行命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) processCommand(command *Command, execute func(command *Command)) {
+	hooks := s.hooks.get()
+	ctx := s.context()
+	command.StartTime = time.Now()
+
+	index := 0
+	for ; index < len(hooks); index++ {
+		var err error
+		if ctx, err = hooks[index].BeforeProcess(ctx, command); err != nil {
+			command.Err = newRedisError(command.Name, command.Args, err)
+			break
+		}
+	}
+
+	if command.Err == nil {
+		execute(command)
+	}
+
+	command.Duration = time.Since(command.StartTime)
+
+	for i := index - 1; i >= 0; i-- {
+		if err := hooks[i].AfterProcess(ctx, command); err != nil {
+			command.Err = newRedisError(command.Name, command.Args, err)
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 在钩子链中执行管道，返回钩子链的错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) processPipeline(commands []*Command, execute func(commands []*Command) error) error {
+	hooks := s.hooks.get()
+	ctx := s.context()
+	startTime := time.Now()
+
+	for _, command := range commands {
+		command.StartTime = startTime
+	}
+
+	var err error
+
+	index := 0
+	for ; index < len(hooks); index++ {
+		var hookErr error
+		if ctx, hookErr = hooks[index].BeforeProcessPipeline(ctx, commands); hookErr != nil {
+			err = newRedisError(REDIS_COMMAND_EXEC, nil, hookErr)
+			break
+		}
+	}
+
+	if err == nil {
+		err = execute(commands)
+	} else {
+		for _, command := range commands {
+			command.Err = err
+		}
+	}
+
+	duration := time.Since(startTime)
+	for _, command := range commands {
+		command.Duration = duration
+	}
+
+	for i := index - 1; i >= 0; i-- {
+		if hookErr := hooks[i].AfterProcessPipeline(ctx, commands); hookErr != nil {
+			err = newRedisError(REDIS_COMMAND_EXEC, nil, hookErr)
+		}
+	}
+
+	return err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 在钩子链中拨号
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) processDial(dial *DialInfo, execute func(dial *DialInfo) error) error {
+	hooks := s.hooks.get()
+	ctx := s.context()
+	dial.StartTime = time.Now()
+
+	index := 0
+	for ; index < len(hooks); index++ {
+		var err error
+		if ctx, err = hooks[index].BeforeDial(ctx, dial); err != nil {
+			dial.Err = err
+			break
+		}
+	}
+
+	if dial.Err == nil {
+		dial.Err = execute(dial)
+	}
+
+	dial.Duration = time.Since(dial.StartTime)
+
+	for i := index - 1; i >= 0; i-- {
+		if err := hooks[i].AfterDial(ctx, dial); err != nil {
+			dial.Err = err
+		}
+	}
+
+	return dial.Err
+}
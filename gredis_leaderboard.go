@@ -0,0 +1,374 @@
+package gredis
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+/* ================================================================================
+ * Leaderboard
+ * 基于有序集合的排行榜，分数越高排名越靠前，成员元数据（昵称、头像等）存于伴随哈希
+ * 键（均在客户端前缀之下）：
+ * {name} 不分窗口的排行榜 | {name}:{yyyyMMdd} 日榜 | {name}:{yyyy}W{ww} 周榜
+ * {name}:meta 成员元数据哈希，各时间窗口共用
+ * {排行榜Key}:scores 密集排名时的去重分数有序集合，排名为更高分数的个数加1（O(log N)），
+ * 由 Submit | Remove 维护，因此密集排名需自创建起使用，且不能绕过排行榜直接修改有序集合
+ * 时间窗口按提交时的客户端时间切换，窗口排行榜在窗口结束后保留 Retention 个窗口时长后过期
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	leaderboardDefaultRetention int = 1
+)
+
+var (
+	leaderboardSubmitScript = NewScript(`
+local previous = redis.call('ZSCORE', KEYS[1], ARGV[3])
+local score
+if ARGV[1] == 'sum' then
+	score = redis.call('ZINCRBY', KEYS[1], ARGV[2], ARGV[3])
+elseif ARGV[1] == 'latest' then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[3])
+	score = redis.call('ZSCORE', KEYS[1], ARGV[3])
+else
+	redis.call('ZADD', KEYS[1], 'GT', ARGV[2], ARGV[3])
+	score = redis.call('ZSCORE', KEYS[1], ARGV[3])
+end
+if ARGV[5] == 'dense' and previous ~= score then
+	if previous and redis.call('ZCOUNT', KEYS[1], previous, previous) == 0 then
+		redis.call('ZREM', KEYS[2], previous)
+	end
+	redis.call('ZADD', KEYS[2], score, score)
+end
+if ARGV[4] ~= '0' then
+	redis.call('PEXPIREAT', KEYS[1], ARGV[4])
+	if ARGV[5] == 'dense' then
+		redis.call('PEXPIREAT', KEYS[2], ARGV[4])
+	end
+end
+return score
+`)
+
+	leaderboardRemoveScript = NewScript(`
+local count = 0
+for i = 2, #ARGV do
+	local score = redis.call('ZSCORE', KEYS[1], ARGV[i])
+	if score then
+		count = count + redis.call('ZREM', KEYS[1], ARGV[i])
+		if ARGV[1] == 'dense' and redis.call('ZCOUNT', KEYS[1], score, score) == 0 then
+			redis.call('ZREM', KEYS[2], score)
+		end
+	end
+end
+return count
+`)
+
+	// 返回 {起始位置, 首个成员排名, member, score, metadata, ...}
+	leaderboardRangeScript = NewScript(`
+local start, stop = tonumber(ARGV[1]), tonumber(ARGV[2])
+if ARGV[4] ~= '' then
+	local position = redis.call('ZREVRANK', KEYS[1], ARGV[4])
+	if not position then
+		return {}
+	end
+	start = math.max(position - tonumber(ARGV[5]), 0)
+	stop = position + tonumber(ARGV[5])
+end
+local items = redis.call('ZREVRANGE', KEYS[1], start, stop, 'WITHSCORES')
+if #items == 0 then
+	return {}
+end
+local rank
+if ARGV[3] == 'dense' then
+	rank = redis.call('ZCOUNT', KEYS[3], '(' .. items[2], '+inf') + 1
+else
+	rank = redis.call('ZCOUNT', KEYS[1], '(' .. items[2], '+inf') + 1
+end
+local result = {tostring(start), tostring(rank)}
+for i = 1, #items, 2 do
+	table.insert(result, items[i])
+	table.insert(result, items[i + 1])
+	table.insert(result, redis.call('HGET', KEYS[2], items[i]) or '')
+end
+return result
+`)
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 排行榜选项
+	 * Policy: 提交分数策略 REDIS_LEADERBOARD_POLICY_*，默认保留最高分
+	 * Ranking: 并列排名方式 REDIS_LEADERBOARD_RANK_*，默认竞争排名
+	 * Window: 时间窗口 REDIS_LEADERBOARD_WINDOW_*，默认不分窗口
+	 * Retention: 窗口结束后保留的窗口个数，默认1（即可查询上一窗口）
+	 * Location: 划分时间窗口使用的时区，默认 time.Local
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	LeaderboardOption struct {
+		Policy    string
+		Ranking   string
+		Window    string
+		Retention int
+		Location  *time.Location
+	}
+
+	Leaderboard struct {
+		redis  IRedis
+		name   string
+		at     time.Time
+		option LeaderboardOption
+	}
+
+	LeaderboardEntry struct {
+		Member   string
+		Score    float64
+		Rank     int
+		Metadata string
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化排行榜
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewLeaderboard(redis IRedis, name string, args ...LeaderboardOption) *Leaderboard {
+	option := LeaderboardOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	if len(option.Policy) == 0 {
+		option.Policy = REDIS_LEADERBOARD_POLICY_BEST
+	}
+
+	if len(option.Ranking) == 0 {
+		option.Ranking = REDIS_LEADERBOARD_RANK_COMPETITION
+	}
+
+	if option.Retention <= 0 {
+		option.Retention = leaderboardDefaultRetention
+	}
+
+	if option.Location == nil {
+		option.Location = time.Local
+	}
+
+	return &Leaderboard{
+		redis:  redis,
+		name:   name,
+		option: option,
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取 t 所在时间窗口的排行榜，如 At(time.Now().AddDate(0, 0, -1)) 为昨日日榜
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) At(t time.Time) *Leaderboard {
+	board := *l
+	board.at = t
+
+	return &board
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取当前时间窗口排行榜的Key（不含客户端前缀）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) Key() string {
+	key, _ := l.window()
+	return key
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按提交策略提交成员分数，返回成员提交后的分数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) Submit(member string, score float64) (float64, error) {
+	key, expireAt := l.window()
+
+	expireAtMilli := int64(0)
+	if !expireAt.IsZero() {
+		expireAtMilli = expireAt.UnixMilli()
+	}
+
+	keys := []string{key, l.scoresKey(key)}
+
+	return replyFloat64(l.redis.Eval(leaderboardSubmitScript, keys, l.option.Policy, score, member, expireAtMilli, l.option.Ranking))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 从当前时间窗口的排行榜移除成员，元数据保留
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) Remove(members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	key := l.Key()
+
+	args := make([]interface{}, 0, len(members)+1)
+	args = append(args, l.option.Ranking)
+	for _, member := range members {
+		args = append(args, member)
+	}
+
+	_, err := l.redis.Eval(leaderboardRemoveScript, []string{key, l.scoresKey(key)}, args...)
+	return err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 设置成员元数据
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) SetMetadata(member string, metadata interface{}) error {
+	return l.redis.HSet(l.metaKey(), member, metadata)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 删除成员元数据
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) DeleteMetadata(members ...string) error {
+	fields := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		fields = append(fields, member)
+	}
+
+	return l.redis.HDel(l.metaKey(), fields...)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取排行榜成员数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) Count() (int, error) {
+	return l.redis.ZCard(l.Key())
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取成员的分数及排名，不在排行榜时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) Entry(member string) (*LeaderboardEntry, error) {
+	entries, err := l.AroundMe(member, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries[0], nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取排名前 count 的成员
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) Top(count int) ([]*LeaderboardEntry, error) {
+	return l.Page(1, count)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 分页获取成员，page 从1开始
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) Page(page, pageSize int) ([]*LeaderboardEntry, error) {
+	if page < 1 || pageSize < 1 {
+		return []*LeaderboardEntry{}, nil
+	}
+
+	start := (page - 1) * pageSize
+
+	return l.entries(start, start+pageSize-1, "", 0)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取成员及其前后各 radius 名的成员，成员不在排行榜时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) AroundMe(member string, radius int) ([]*LeaderboardEntry, error) {
+	entries, err := l.entries(0, 0, member, radius)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return entries, nil
+}
+
+func (l *Leaderboard) entries(start, stop int, member string, radius int) ([]*LeaderboardEntry, error) {
+	key := l.Key()
+	keys := []string{key, l.metaKey(), l.scoresKey(key)}
+
+	values, err := replyStrings(l.redis.Eval(leaderboardRangeScript, keys, start, stop, l.option.Ranking, member, radius))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*LeaderboardEntry, 0, len(values)/3)
+	if len(values) == 0 {
+		return entries, nil
+	}
+
+	if len(values) < 2 || (len(values)-2)%3 != 0 {
+		return nil, ErrUnexpectedReply
+	}
+
+	position, err := strconv.Atoi(values[0])
+	if err != nil {
+		return nil, ErrUnexpectedReply
+	}
+
+	rank, err := strconv.Atoi(values[1])
+	if err != nil {
+		return nil, ErrUnexpectedReply
+	}
+
+	for index := 2; index < len(values); index += 3 {
+		score, err := strconv.ParseFloat(values[index+1], 64)
+		if err != nil {
+			return nil, ErrUnexpectedReply
+		}
+
+		if count := len(entries); count > 0 && entries[count-1].Score != score {
+			if l.option.Ranking == REDIS_LEADERBOARD_RANK_DENSE {
+				rank++
+			} else {
+				rank = position + count + 1
+			}
+		}
+
+		entries = append(entries, &LeaderboardEntry{
+			Member:   values[index],
+			Score:    score,
+			Rank:     rank,
+			Metadata: values[index+2],
+		})
+	}
+
+	return entries, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取时间窗口的Key及过期时间，不分窗口时过期时间为零值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (l *Leaderboard) window() (string, time.Time) {
+	at := l.at
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	at = at.In(l.option.Location)
+	day := startOfDay(at, l.option.Location)
+
+	switch l.option.Window {
+	case REDIS_LEADERBOARD_WINDOW_DAILY:
+		return l.name + ":" + day.Format("20060102"), day.AddDate(0, 0, 1+l.option.Retention)
+	case REDIS_LEADERBOARD_WINDOW_WEEKLY:
+		year, week := at.ISOWeek()
+		monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return fmt.Sprintf("%s:%04dW%02d", l.name, year, week), monday.AddDate(0, 0, 7*(1+l.option.Retention))
+	}
+
+	return l.name, time.Time{}
+}
+
+func (l *Leaderboard) metaKey() string {
+	return l.name + ":meta"
+}
+
+func (l *Leaderboard) scoresKey(key string) string {
+	return key + ":scores"
+}
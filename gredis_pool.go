@@ -0,0 +1,72 @@
+package gredis
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ================================================================================
+ * Redis pool stats
+ * 连接池统计，活动及空闲连接数取自 redigo 连接池，等待统计由客户端记录
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 连接池统计
+	 * ActiveCount: 连接总数（含空闲及使用中）
+	 * IdleCount: 空闲连接数
+	 * WaitingCount: 当前正在等待获取连接的调用数
+	 * GetCount | WaitDuration: 获取连接的累计次数及累计耗时
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	PoolStats struct {
+		ActiveCount  int
+		IdleCount    int
+		WaitingCount int64
+		GetCount     uint64
+		WaitDuration time.Duration
+	}
+
+	poolCounter struct {
+		waiting      int64
+		gets         uint64
+		waitDuration int64
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 从连接池获取连接，返回连接及等待时长
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) getConn() (redis_go.Conn, time.Duration) {
+	atomic.AddInt64(&s.poolCounter.waiting, 1)
+	startTime := time.Now()
+
+	conn := s.pool.Get()
+
+	wait := time.Since(startTime)
+	atomic.AddInt64(&s.poolCounter.waiting, -1)
+	atomic.AddUint64(&s.poolCounter.gets, 1)
+	atomic.AddInt64(&s.poolCounter.waitDuration, int64(wait))
+
+	return conn, wait
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 连接池统计
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) PoolStats() PoolStats {
+	stats := s.pool.Stats()
+
+	return PoolStats{
+		ActiveCount:  stats.ActiveCount,
+		IdleCount:    stats.IdleCount,
+		WaitingCount: atomic.LoadInt64(&s.poolCounter.waiting),
+		GetCount:     atomic.LoadUint64(&s.poolCounter.gets),
+		WaitDuration: time.Duration(atomic.LoadInt64(&s.poolCounter.waitDuration)),
+	}
+}
@@ -0,0 +1,438 @@
+package gredis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+/* ================================================================================
+ * Reliable work queue
+ * 基于列表的可靠队列：出队时以 LMOVE 原子地移入工作者的处理中列表，确认后删除，
+ * 工作者心跳超过可见性超时后由回收器将其处理中的任务放回队列
+ * 键（均在客户端前缀之下）：
+ * {name}:pending 待处理任务Id列表 | {name}:processing:{worker} 工作者处理中列表
+ * {name}:delayed 延迟任务有序集合（分值为执行时间毫秒） | {name}:workers 工作者心跳有序集合
+ * {name}:payloads | {name}:attempts 任务内容及失败次数哈希 | {name}:dead 死信列表
+ * 所有时间均取自 Redis 服务器的 TIME，避免工作者之间的时钟偏差
+ * 回收脚本按心跳有序集合中的工作者拼接处理中列表的键，无法预先通过 KEYS 声明，
+ * Redis Cluster 中队列名称需使用哈希标签（如 "{jobs}"）使所有键位于同一槽
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	queueDefaultVisibilityTimeout time.Duration = 30 * time.Second
+	queueDefaultMaxAttempts       int           = 3
+	queuePromoteLimit             int           = 100
+)
+
+const (
+	queueNowScript = `
+local now = redis.call('TIME')
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+`
+)
+
+var (
+	queueEnqueueScript = NewScript(queueNowScript + `
+local id = tostring(redis.call('INCR', KEYS[1]))
+redis.call('HSET', KEYS[2], id, ARGV[1])
+local delay = tonumber(ARGV[2])
+if delay > 0 then
+	redis.call('ZADD', KEYS[4], ms + delay, id)
+else
+	redis.call('LPUSH', KEYS[3], id)
+end
+return id
+`)
+
+	queuePromoteScript = NewScript(queueNowScript + `
+if ARGV[1] ~= '' then
+	redis.call('ZADD', KEYS[1], ms, ARGV[1])
+end
+local ids = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ms, 'LIMIT', 0, tonumber(ARGV[2]))
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('LPUSH', KEYS[3], id)
+end
+return #ids
+`)
+
+	queueAckScript = NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+return 1
+`)
+
+	queueReleaseScript = NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+	return 0
+end
+redis.call('RPUSH', KEYS[2], ARGV[1])
+return 1
+`)
+
+	queueNackScript = NewScript(queueNowScript + `
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+	return 0
+end
+local attempts = redis.call('HINCRBY', KEYS[4], ARGV[1], 1)
+if attempts >= tonumber(ARGV[3]) then
+	redis.call('LPUSH', KEYS[5], ARGV[1])
+	return 2
+end
+local delay = tonumber(ARGV[2])
+if delay > 0 then
+	redis.call('ZADD', KEYS[3], ms + delay, ARGV[1])
+else
+	redis.call('LPUSH', KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+	queueReapScript = NewScript(queueNowScript + `
+local workers = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ms - tonumber(ARGV[2]))
+local count = 0
+for _, worker in ipairs(workers) do
+	local processing = ARGV[1] .. worker
+	local id = redis.call('LPOP', processing)
+	while id do
+		local attempts = redis.call('HINCRBY', KEYS[3], id, 1)
+		if attempts >= tonumber(ARGV[3]) then
+			redis.call('LPUSH', KEYS[4], id)
+		else
+			redis.call('RPUSH', KEYS[2], id)
+		end
+		count = count + 1
+		id = redis.call('LPOP', processing)
+	end
+	redis.call('ZREM', KEYS[1], worker)
+end
+return count
+`)
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 队列选项
+	 * VisibilityTimeout: 工作者心跳超时时长，超时后其处理中的任务被放回队列，默认30秒
+	 * MaxAttempts: 最大执行次数（含首次），失败或超时次数达到后移入死信列表，默认3
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	QueueOption struct {
+		VisibilityTimeout time.Duration
+		MaxAttempts       int
+	}
+
+	Queue struct {
+		redis  IRedis
+		name   string
+		option QueueOption
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 任务
+	 * Attempts: 此前失败（Nack 或超时回收）的次数
+	 * Worker: 出队的工作者
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	Job struct {
+		Id       string
+		Payload  []byte
+		Attempts int
+		Worker   string
+	}
+
+	QueueStats struct {
+		Pending int
+		Delayed int
+		Dead    int
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化队列
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewQueue(redis IRedis, name string, args ...QueueOption) *Queue {
+	option := QueueOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	if option.VisibilityTimeout <= 0 {
+		option.VisibilityTimeout = queueDefaultVisibilityTimeout
+	}
+
+	if option.MaxAttempts <= 0 {
+		option.MaxAttempts = queueDefaultMaxAttempts
+	}
+
+	return &Queue{
+		redis:  redis,
+		name:   name,
+		option: option,
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 任务入队，返回任务Id
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) Enqueue(payload interface{}) (string, error) {
+	return q.EnqueueIn(payload, 0)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 延迟任务入队，delay 后由 Dequeue 或回收器移入待处理列表
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) EnqueueIn(payload interface{}, delay time.Duration) (string, error) {
+	keys := []string{q.key("seq"), q.key("payloads"), q.key("pending"), q.key("delayed")}
+
+	return replyString(q.redis.Eval(queueEnqueueScript, keys, payload, delay.Milliseconds()))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 阻塞出队，任务原子地移入 worker 的处理中列表，处理完成后需调用 Ack 或 Nack
+ * timeout 为0时永久阻塞，超时返回 ErrNotFound
+ * 取得任务后读取失败时任务放回待处理列表头部并返回错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) Dequeue(worker string, timeout time.Duration) (*Job, error) {
+	id, err := q.claim(worker, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := q.redis.HGet(q.key("payloads"), id)
+	if err != nil {
+		// 任务内容不存在时保留在处理中列表，由回收器累计失败次数后移入死信列表
+		if !errors.Is(err, ErrNotFound) {
+			q.release(worker, id)
+		}
+		return nil, err
+	}
+
+	attempts := 0
+	if value, err := q.redis.HGet(q.key("attempts"), id); err == nil {
+		attempts, _ = strconv.Atoi(value)
+	} else if !errors.Is(err, ErrNotFound) {
+		q.release(worker, id)
+		return nil, err
+	}
+
+	return &Job{
+		Id:       id,
+		Payload:  []byte(payload),
+		Attempts: attempts,
+		Worker:   worker,
+	}, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 分段阻塞等待任务，每段不超过可见性超时的一半且段前发送心跳（同时移入到期的延迟任务），
+ * 避免阻塞期间工作者被回收器判定为超时；取得任务后再次心跳，可见性超时自出队时起算
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) claim(worker string, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if err := q.Heartbeat(worker); err != nil {
+			return "", err
+		}
+
+		block := q.option.VisibilityTimeout / 2
+		if timeout > 0 {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return "", ErrNotFound
+			}
+
+			if remaining < block {
+				block = remaining
+			}
+		}
+
+		id, err := q.redis.BLMove(q.key("pending"), q.processingKey(worker), REDIS_LIST_RIGHT, REDIS_LIST_LEFT, block)
+		if err == nil {
+			if err := q.Heartbeat(worker); err != nil {
+				q.release(worker, id)
+				return "", err
+			}
+
+			return id, nil
+		}
+
+		if !errors.Is(err, ErrNotFound) {
+			return "", err
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 确认任务完成并删除，任务已被回收（工作者超时）时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) Ack(job *Job) error {
+	keys := []string{q.processingKey(job.Worker), q.key("payloads"), q.key("attempts")}
+
+	return q.owned(replyInt(q.redis.Eval(queueAckScript, keys, job.Id)))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 任务处理失败，失败次数加1，达到 MaxAttempts 时移入死信列表，
+ * 否则 delay 后重新入队（delay 为0时立即入队），任务已被回收时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) Nack(job *Job, delay time.Duration) error {
+	keys := []string{q.processingKey(job.Worker), q.key("pending"), q.key("delayed"), q.key("attempts"), q.key("dead")}
+
+	return q.owned(replyInt(q.redis.Eval(queueNackScript, keys, job.Id, delay.Milliseconds(), q.option.MaxAttempts)))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 工作者心跳，处理耗时超过可见性超时的任务时需定期调用，同时移入到期的延迟任务
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) Heartbeat(worker string) error {
+	_, err := q.promote(worker)
+	return err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将到期的延迟任务移入待处理列表，返回移动的任务数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) Promote() (int, error) {
+	return q.promote("")
+}
+
+func (q *Queue) promote(worker string) (int, error) {
+	keys := []string{q.key("workers"), q.key("delayed"), q.key("pending")}
+
+	return replyInt(q.redis.Eval(queuePromoteScript, keys, worker, queuePromoteLimit))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 回收心跳超时的工作者处理中的任务，失败次数加1后放回队列（优先再次出队）或移入死信列表，
+ * 返回回收的任务数，处理中列表的键由脚本以 processingPrefix 拼接
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) Reap() (int, error) {
+	keys := []string{q.key("workers"), q.key("pending"), q.key("attempts"), q.key("dead")}
+	processingPrefix := q.fullKey("processing:")
+
+	return replyInt(q.redis.Eval(queueReapScript, keys, processingPrefix, q.option.VisibilityTimeout.Milliseconds(), q.option.MaxAttempts))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 每隔 interval 移入到期的延迟任务并回收超时任务，直到 ctx 结束或出错，
+ * 出错时返回该错误，由调用方决定是否重新运行
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) RunReaper(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := q.Promote(); err != nil {
+				return err
+			}
+
+			if _, err := q.Reap(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 队列统计
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) Stats() (QueueStats, error) {
+	stats := QueueStats{}
+
+	var err error
+	if stats.Pending, err = q.redis.LLen(q.key("pending")); err != nil {
+		return stats, err
+	}
+
+	if stats.Delayed, err = q.redis.ZCard(q.key("delayed")); err != nil {
+		return stats, err
+	}
+
+	if stats.Dead, err = q.redis.LLen(q.key("dead")); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 死信列表中的任务，最近移入的在前
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) DeadJobs() ([]*Job, error) {
+	ids, err := q.redis.LRange(q.key("dead"), 0, -1)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	fields := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		fields = append(fields, id)
+	}
+
+	payloads, err := q.redis.HMGet(q.key("payloads"), fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := q.redis.HMGet(q.key("attempts"), fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(ids))
+	for index, id := range ids {
+		job := &Job{
+			Id:      id,
+			Payload: []byte(payloads[index]),
+		}
+		job.Attempts, _ = strconv.Atoi(attempts[index])
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将已取得但未交给调用方的任务放回待处理列表头部，失败时由回收器回收
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *Queue) release(worker, id string) error {
+	keys := []string{q.processingKey(worker), q.key("pending")}
+
+	return q.owned(replyInt(q.redis.Eval(queueReleaseScript, keys, id)))
+}
+
+func (q *Queue) owned(count int, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (q *Queue) key(suffix string) string {
+	return q.name + ":" + suffix
+}
+
+func (q *Queue) fullKey(suffix string) string {
+	return q.redis.Option().Prefix + q.key(suffix)
+}
+
+func (q *Queue) processingKey(worker string) string {
+	return q.key("processing:" + worker)
+}
@@ -0,0 +1,623 @@
+package gredis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ================================================================================
+ * Redis reply helper
+ * 包装 redigo 类型转换，统一错误为 gredis 哨兵错误
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * As 支持的元素类型
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	Element interface {
+		string | int | int64 | float64
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将字符串列表结果转换为指定类型，用于任意返回 ([]string, error) 的方法，如：
+ * ids, err := gredis.As[int64](redis.SMembers(key))
+ * 元素无法转换时返回 ErrUnexpectedReply
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func As[T Element](values []string, err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, len(values))
+	for index, value := range values {
+		var parseErr error
+
+		switch target := any(&result[index]).(type) {
+		case *string:
+			*target = value
+		case *int:
+			*target, parseErr = strconv.Atoi(value)
+		case *int64:
+			*target, parseErr = strconv.ParseInt(value, 10, 64)
+		case *float64:
+			*target, parseErr = strconv.ParseFloat(value, 64)
+		}
+
+		if parseErr != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnexpectedReply, parseErr)
+		}
+	}
+
+	return result, nil
+}
+
+func replyString(reply interface{}, err error) (string, error) {
+	value, err := redis_go.String(reply, err)
+	return value, replyError(err)
+}
+
+func replyStrings(reply interface{}, err error) ([]string, error) {
+	values, err := redis_go.Strings(reply, err)
+	return values, replyError(err)
+}
+
+func replyBytes(reply interface{}, err error) ([]byte, error) {
+	value, err := redis_go.Bytes(reply, err)
+	return value, replyError(err)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换可能包含 nil 元素的字节列表回复，nil 元素保持为 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyByteSlices(reply interface{}, err error) ([][]byte, error) {
+	values, err := replyValues(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([][]byte, len(values))
+	for index, value := range values {
+		if value == nil {
+			continue
+		}
+
+		if result[index], err = replyBytes(value, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func replyInt(reply interface{}, err error) (int, error) {
+	value, err := redis_go.Int(reply, err)
+	return value, replyError(err)
+}
+
+func replyInts(reply interface{}, err error) ([]int, error) {
+	values, err := redis_go.Ints(reply, err)
+	return values, replyError(err)
+}
+
+func replyInt64(reply interface{}, err error) (int64, error) {
+	value, err := redis_go.Int64(reply, err)
+	return value, replyError(err)
+}
+
+func replyInt64s(reply interface{}, err error) ([]int64, error) {
+	values, err := redis_go.Int64s(reply, err)
+	return values, replyError(err)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换可能包含 nil 元素的整数列表回复，nil 元素对应 nil 指针
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyNullableInt64s(reply interface{}, err error) ([]*int64, error) {
+	values, err := replyValues(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*int64, len(values))
+	for index, value := range values {
+		if value == nil {
+			continue
+		}
+
+		item, err := replyInt64(value, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		result[index] = &item
+	}
+
+	return result, nil
+}
+
+func replyFloat64(reply interface{}, err error) (float64, error) {
+	value, err := redis_go.Float64(reply, err)
+	return value, replyError(err)
+}
+
+func replyFloat64s(reply interface{}, err error) ([]float64, error) {
+	values, err := redis_go.Float64s(reply, err)
+	return values, replyError(err)
+}
+
+func replyBool(reply interface{}, err error) (bool, error) {
+	value, err := redis_go.Bool(reply, err)
+	return value, replyError(err)
+}
+
+func replyValues(reply interface{}, err error) ([]interface{}, error) {
+	values, err := redis_go.Values(reply, err)
+	return values, replyError(err)
+}
+
+func replyStringMap(reply interface{}, err error) (map[string]string, error) {
+	values, err := redis_go.StringMap(reply, err)
+	return values, replyError(err)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换可能包含 nil 元素的字符串列表回复，nil 元素对应 nil 指针
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyNullableStrings(reply interface{}, err error) ([]*string, error) {
+	values, err := replyValues(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*string, len(values))
+	for index, value := range values {
+		if value == nil {
+			continue
+		}
+
+		item, err := replyString(value, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		result[index] = &item
+	}
+
+	return result, nil
+}
+
+func replyIntMap(reply interface{}, err error) (map[string]int, error) {
+	values, err := redis_go.IntMap(reply, err)
+	return values, replyError(err)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换 member score [member score ...] 回复，RESP3 的成对数组已由 resp2Reply 展开
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyZMembers(reply interface{}, err error) ([]ZMember, error) {
+	values, err := replyStrings(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values)%2 != 0 {
+		return nil, ErrUnexpectedReply
+	}
+
+	members := make([]ZMember, 0, len(values)/2)
+	for index := 0; index < len(values); index += 2 {
+		score, err := strconv.ParseFloat(values[index+1], 64)
+		if err != nil {
+			return nil, ErrUnexpectedReply
+		}
+
+		members = append(members, ZMember{Member: values[index], Score: score})
+	}
+
+	return members, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换 SCAN 系列命令的 [cursor, [item ...]] 回复
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyScan(reply interface{}, err error) (uint64, []string, error) {
+	values, err := replyValues(reply, err)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if len(values) != 2 {
+		return 0, nil, ErrUnexpectedReply
+	}
+
+	cursor, err := redis_go.Uint64(values[0], nil)
+	if err != nil {
+		return 0, nil, replyError(err)
+	}
+
+	items, err := replyStrings(values[1], nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return cursor, items, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换 [longitude, latitude] 坐标回复
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyGeoCoord(reply interface{}) (float64, float64, error) {
+	values, err := replyFloat64s(reply, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(values) != 2 {
+		return 0, 0, ErrUnexpectedReply
+	}
+
+	return values[0], values[1], nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换 GEOSEARCH 的单个结果，未指定附带信息时仅为成员名，
+ * 否则为 [name, dist?, hash?, [longitude, latitude]?]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyGeoLocation(reply interface{}, query GeoSearchQuery) (GeoLocation, error) {
+	location := GeoLocation{}
+
+	if !query.WithCoord && !query.WithDist && !query.WithHash {
+		name, err := replyString(reply, nil)
+		location.Name = name
+		return location, err
+	}
+
+	values, err := replyValues(reply, nil)
+	if err != nil {
+		return location, err
+	}
+
+	if len(values) == 0 {
+		return location, ErrUnexpectedReply
+	}
+
+	if location.Name, err = replyString(values[0], nil); err != nil {
+		return location, err
+	}
+	values = values[1:]
+
+	if query.WithDist {
+		if len(values) == 0 {
+			return location, ErrUnexpectedReply
+		}
+
+		if location.Dist, err = replyFloat64(values[0], nil); err != nil {
+			return location, err
+		}
+		values = values[1:]
+	}
+
+	if query.WithHash {
+		if len(values) == 0 {
+			return location, ErrUnexpectedReply
+		}
+
+		if location.Hash, err = replyInt64(values[0], nil); err != nil {
+			return location, err
+		}
+		values = values[1:]
+	}
+
+	if query.WithCoord {
+		if len(values) == 0 {
+			return location, ErrUnexpectedReply
+		}
+
+		if location.Longitude, location.Latitude, err = replyGeoCoord(values[0]); err != nil {
+			return location, err
+		}
+	}
+
+	return location, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换 FT.SEARCH 回复：total, id, [score], [field value ...], ...
+ * 是否包含分数及字段由 query 的 WithScores | NoContent 决定
+ * RESP3 回复为 {total_results, results: [{id, score, extra_attributes}]}，先转换为上述形式
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replySearchResult(reply interface{}, err error, query *SearchQuery) (SearchResult, error) {
+	result := SearchResult{}
+
+	if values, isOk := reply.(map[string]interface{}); isOk {
+		documents := []interface{}{values["total_results"]}
+		for _, item := range searchResults(values) {
+			documents = append(documents, item["id"])
+
+			if query.isWithScores {
+				documents = append(documents, resp2Reply(item["score"], false))
+			}
+
+			if !query.isNoContent {
+				documents = append(documents, searchAttributes(item))
+			}
+		}
+		reply = documents
+	}
+
+	values, err := replyValues(reply, err)
+	if err != nil {
+		return result, err
+	}
+
+	if len(values) == 0 {
+		return result, ErrUnexpectedReply
+	}
+
+	if result.Total, err = replyInt(values[0], nil); err != nil {
+		return result, err
+	}
+
+	for values = values[1:]; len(values) > 0; {
+		document := SearchDocument{}
+		if document.Id, err = replyString(values[0], nil); err != nil {
+			return result, err
+		}
+		values = values[1:]
+
+		if query.isWithScores {
+			if len(values) == 0 {
+				return result, ErrUnexpectedReply
+			}
+
+			if document.Score, err = replyFloat64(values[0], nil); err != nil {
+				return result, err
+			}
+			values = values[1:]
+		}
+
+		if !query.isNoContent {
+			if len(values) == 0 {
+				return result, ErrUnexpectedReply
+			}
+
+			if document.Fields, err = replyStringMap(values[0], nil); err != nil {
+				return result, err
+			}
+			values = values[1:]
+		}
+
+		result.Documents = append(result.Documents, document)
+	}
+
+	return result, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换 FT.AGGREGATE 回复：total, [field value ...], ...
+ * RESP3 回复为 {total_results, results: [{extra_attributes}]}
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replyAggregateRows(reply interface{}, err error) ([]map[string]string, error) {
+	if values, isOk := reply.(map[string]interface{}); isOk {
+		rows := []interface{}{values["total_results"]}
+		for _, item := range searchResults(values) {
+			rows = append(rows, searchAttributes(item))
+		}
+		reply = rows
+	}
+
+	values, err := replyValues(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, ErrUnexpectedReply
+	}
+
+	rows := make([]map[string]string, 0, len(values)-1)
+	for _, value := range values[1:] {
+		row, err := replyStringMap(value, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * RESP3 的 FT.SEARCH | FT.AGGREGATE 回复中的结果项
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func searchResults(reply map[string]interface{}) []map[string]interface{} {
+	items, _ := reply["results"].([]interface{})
+
+	results := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if result, isOk := item.(map[string]interface{}); isOk {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 结果项的字段，转换为 RESP2 的 field value 数组
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func searchAttributes(result map[string]interface{}) interface{} {
+	if attributes, isOk := result["extra_attributes"]; isOk {
+		return resp2Reply(attributes, false)
+	}
+
+	return []interface{}{}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将 RESP3 的 FT.INFO 回复转换为 RESP2 形式：映射展开为 key value 数组，
+ * 字段的 flags 数组展开为独立的标志项（如 SORTABLE）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func searchInfoReply(reply interface{}) interface{} {
+	switch reply := reply.(type) {
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(reply)*2)
+		for key, value := range reply {
+			if flags, isOk := value.([]interface{}); isOk && key == "flags" {
+				values = append(values, flags...)
+				continue
+			}
+
+			values = append(values, []byte(key), searchInfoReply(value))
+		}
+		return values
+	case []interface{}:
+		values := make([]interface{}, 0, len(reply))
+		for _, value := range reply {
+			values = append(values, searchInfoReply(value))
+		}
+		return values
+	}
+
+	return resp2Reply(reply, false)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换 FT.INFO 回复，忽略未使用的信息项
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replySearchIndexInfo(reply interface{}, err error) (SearchIndexInfo, error) {
+	info := SearchIndexInfo{}
+
+	if _, isOk := reply.(map[string]interface{}); isOk {
+		reply = searchInfoReply(reply)
+	}
+
+	values, err := replyValues(reply, err)
+	if err != nil {
+		return info, err
+	}
+
+	for index := 0; index+1 < len(values); index += 2 {
+		name, err := replyString(values[index], nil)
+		if err != nil {
+			return info, err
+		}
+
+		switch name {
+		case "index_name":
+			info.Name, err = replyString(values[index+1], nil)
+		case "num_docs":
+			info.NumDocs, err = replyInt(values[index+1], nil)
+		case "index_definition":
+			var definition []interface{}
+			if definition, err = replyValues(values[index+1], nil); err != nil {
+				return info, err
+			}
+
+			for offset := 0; offset+1 < len(definition); offset += 2 {
+				if key, _ := replyString(definition[offset], nil); key == "prefixes" {
+					if info.Prefixes, err = replyStrings(definition[offset+1], nil); err != nil {
+						return info, err
+					}
+				}
+			}
+		case "attributes":
+			var attributes []interface{}
+			if attributes, err = replyValues(values[index+1], nil); err != nil {
+				return info, err
+			}
+
+			for _, attribute := range attributes {
+				field, err := replySearchField(attribute)
+				if err != nil {
+					return info, err
+				}
+
+				info.Fields = append(info.Fields, field)
+			}
+		}
+
+		if err != nil {
+			return info, err
+		}
+	}
+
+	return info, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转换 FT.INFO 的字段属性：键值对与 SORTABLE 等单独的标志混合
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func replySearchField(reply interface{}) (SearchField, error) {
+	field := SearchField{}
+
+	items, err := replyValues(reply, nil)
+	if err != nil {
+		return field, err
+	}
+
+	// dim 等数值项为整数回复
+	values := make([]string, len(items))
+	for index, item := range items {
+		if number, isOk := item.(int64); isOk {
+			values[index] = strconv.FormatInt(number, 10)
+		} else if values[index], err = replyString(item, nil); err != nil {
+			return field, err
+		}
+	}
+
+	for index := 0; index < len(values); index++ {
+		value := ""
+		if index+1 < len(values) {
+			value = values[index+1]
+		}
+
+		switch strings.ToLower(values[index]) {
+		case "sortable":
+			field.Sortable = true
+			continue
+		case "noindex":
+			field.NoIndex = true
+			continue
+		case "nostem":
+			field.NoStem = true
+			continue
+		case "casesensitive":
+			field.CaseSensitive = true
+			continue
+		case "unf", "withsuffixtrie", "indexempty", "indexmissing":
+			continue
+		case "attribute":
+			field.Name = value
+		case "type":
+			field.Type = value
+		case "weight":
+			field.Weight, err = strconv.ParseFloat(value, 64)
+		case "separator":
+			field.Separator = value
+		case "algorithm":
+			field.Algorithm = value
+		case "dim":
+			field.Dim, err = strconv.Atoi(value)
+		case "distance_metric":
+			field.Distance = value
+		}
+
+		if err != nil {
+			return field, fmt.Errorf("%w: %v", ErrUnexpectedReply, err)
+		}
+
+		index++
+	}
+
+	return field, nil
+}
@@ -0,0 +1,651 @@
+package gredis
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ================================================================================
+ * RESP3 protocol connection
+ * 实现 redigo Conn 接口，支持 HELLO 3 协商、RESP3 新类型解析及推送消息分发
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	REDIS_PROTOCOL_RESP2 int = 2
+	REDIS_PROTOCOL_RESP3 int = 3
+)
+
+var (
+	errResp3Unsupported = errors.New("gredis: server does not support RESP3")
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * RESP3 推送消息（如 invalidate、message 等）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	PushMessage struct {
+		Kind string
+		Data []interface{}
+	}
+
+	resp3Conn struct {
+		conn         net.Conn
+		br           *bufio.Reader
+		bw           *bufio.Writer
+		readTimeout  time.Duration
+		writeTimeout time.Duration
+		pushHandler  func(message PushMessage)
+
+		mu      sync.Mutex
+		pending int
+		err     error
+
+		lenScratch [32]byte
+		numScratch [40]byte
+	}
+
+	resp3ProtocolError string
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 以 RESP3 协议链接 Redis 服务器
+ * 服务器不支持 HELLO 时返回 errResp3Unsupported，由调用方回退到 RESP2
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func dialResp3(network, address, username, password, clientName string, db, timeout int, netDial func(network, address string) (net.Conn, error), pushHandler func(message PushMessage)) (redis_go.Conn, error) {
+	duration := time.Duration(timeout) * time.Second
+
+	if netDial == nil {
+		netDial = (&net.Dialer{Timeout: duration}).Dial
+	}
+
+	netConn, err := netDial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := newResp3Conn(netConn, duration, duration, pushHandler)
+
+	args := redis_go.Args{}.Add(REDIS_PROTOCOL_RESP3)
+	if len(password) > 0 {
+		if len(username) == 0 {
+			username = "default"
+		}
+		args = args.Add("AUTH", username, password)
+	}
+
+	if len(clientName) > 0 {
+		args = args.Add("SETNAME", clientName)
+	}
+
+	if _, err := conn.Do(REDIS_COMMAND_HELLO, args...); err != nil {
+		conn.Close()
+
+		if isResp3Unsupported(err) {
+			return nil, errResp3Unsupported
+		}
+
+		return nil, err
+	}
+
+	if db != 0 {
+		if _, err := conn.Do(REDIS_COMMAND_SELECT, db); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 旧版本服务器对 HELLO 返回 unknown command，或 HELLO 3 返回 NOPROTO
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func isResp3Unsupported(err error) bool {
+	var redisError redis_go.Error
+	if !errors.As(err, &redisError) {
+		return false
+	}
+
+	message := string(redisError)
+
+	return strings.HasPrefix(message, "NOPROTO") || strings.Contains(strings.ToLower(message), "unknown command")
+}
+
+func newResp3Conn(netConn net.Conn, readTimeout, writeTimeout time.Duration, pushHandler func(message PushMessage)) *resp3Conn {
+	return &resp3Conn{
+		conn:         netConn,
+		br:           bufio.NewReader(netConn),
+		bw:           bufio.NewWriter(netConn),
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
+		pushHandler:  pushHandler,
+	}
+}
+
+func (c *resp3Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.err
+	if c.err == nil {
+		c.err = errors.New("gredis: closed")
+		err = c.conn.Close()
+	}
+
+	return err
+}
+
+func (c *resp3Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *resp3Conn) fatal(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		c.conn.Close()
+	}
+
+	return err
+}
+
+func (c *resp3Conn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return c.DoWithTimeout(c.readTimeout, commandName, args...)
+}
+
+func (c *resp3Conn) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = 0
+	c.mu.Unlock()
+
+	if commandName == "" && pending == 0 {
+		return nil, nil
+	}
+
+	if c.writeTimeout != 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+
+	if commandName != "" {
+		if err := c.writeCommand(commandName, args); err != nil {
+			return nil, c.fatal(err)
+		}
+	}
+
+	if err := c.bw.Flush(); err != nil {
+		return nil, c.fatal(err)
+	}
+
+	c.setReadDeadline(timeout)
+
+	if commandName == "" {
+		replies := make([]interface{}, pending)
+		for i := range replies {
+			reply, err := c.readResponse(true)
+			if err != nil {
+				return nil, c.fatal(err)
+			}
+			replies[i] = reply
+		}
+		return replies, nil
+	}
+
+	var err error
+	var reply interface{}
+	for i := 0; i <= pending; i++ {
+		var e error
+		if reply, e = c.readResponse(true); e != nil {
+			return nil, c.fatal(e)
+		}
+		if e, isOk := reply.(redis_go.Error); isOk && err == nil {
+			err = e
+		}
+	}
+
+	return reply, err
+}
+
+func (c *resp3Conn) Send(commandName string, args ...interface{}) error {
+	c.mu.Lock()
+	c.pending++
+	c.mu.Unlock()
+
+	if c.writeTimeout != 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+
+	if err := c.writeCommand(commandName, args); err != nil {
+		return c.fatal(err)
+	}
+
+	return nil
+}
+
+func (c *resp3Conn) Flush() error {
+	if c.writeTimeout != 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+
+	if err := c.bw.Flush(); err != nil {
+		return c.fatal(err)
+	}
+
+	return nil
+}
+
+func (c *resp3Conn) Receive() (interface{}, error) {
+	return c.ReceiveWithTimeout(c.readTimeout)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 接收一个回复；未设置推送处理函数时，推送消息以 PushMessage 返回
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *resp3Conn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	c.setReadDeadline(timeout)
+
+	reply, err := c.readResponse(c.pushHandler != nil)
+	if err != nil {
+		return nil, c.fatal(err)
+	}
+
+	if _, isPush := reply.(PushMessage); !isPush {
+		c.mu.Lock()
+		if c.pending > 0 {
+			c.pending--
+		}
+		c.mu.Unlock()
+	}
+
+	if err, isOk := reply.(redis_go.Error); isOk {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (c *resp3Conn) setReadDeadline(timeout time.Duration) {
+	var deadline time.Time
+	if timeout != 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	c.conn.SetReadDeadline(deadline)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 读取一个回复，skipPush 为 true 时推送消息交由处理函数后继续读取
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *resp3Conn) readResponse(skipPush bool) (interface{}, error) {
+	for {
+		reply, err := c.readReply()
+		if err != nil {
+			return nil, err
+		}
+
+		message, isPush := reply.(PushMessage)
+		if !isPush || !skipPush {
+			return reply, nil
+		}
+
+		if c.pushHandler != nil {
+			c.pushHandler(message)
+		}
+	}
+}
+
+func (c *resp3Conn) writeLen(prefix byte, n int) error {
+	c.lenScratch[len(c.lenScratch)-1] = '\n'
+	c.lenScratch[len(c.lenScratch)-2] = '\r'
+	i := len(c.lenScratch) - 3
+	for {
+		c.lenScratch[i] = byte('0' + n%10)
+		i--
+		n = n / 10
+		if n == 0 {
+			break
+		}
+	}
+	c.lenScratch[i] = prefix
+	_, err := c.bw.Write(c.lenScratch[i:])
+	return err
+}
+
+func (c *resp3Conn) writeString(s string) error {
+	c.writeLen('$', len(s))
+	c.bw.WriteString(s)
+	_, err := c.bw.WriteString("\r\n")
+	return err
+}
+
+func (c *resp3Conn) writeBytes(p []byte) error {
+	c.writeLen('$', len(p))
+	c.bw.Write(p)
+	_, err := c.bw.WriteString("\r\n")
+	return err
+}
+
+func (c *resp3Conn) writeCommand(commandName string, args []interface{}) error {
+	c.writeLen('*', 1+len(args))
+	if err := c.writeString(commandName); err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		if err := c.writeArg(arg, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *resp3Conn) writeArg(arg interface{}, argumentTypeOK bool) error {
+	switch arg := arg.(type) {
+	case string:
+		return c.writeString(arg)
+	case []byte:
+		return c.writeBytes(arg)
+	case int:
+		return c.writeBytes(strconv.AppendInt(c.numScratch[:0], int64(arg), 10))
+	case int64:
+		return c.writeBytes(strconv.AppendInt(c.numScratch[:0], arg, 10))
+	case float64:
+		return c.writeBytes(strconv.AppendFloat(c.numScratch[:0], arg, 'g', -1, 64))
+	case bool:
+		if arg {
+			return c.writeString("1")
+		}
+		return c.writeString("0")
+	case nil:
+		return c.writeString("")
+	case redis_go.Argument:
+		if argumentTypeOK {
+			return c.writeArg(arg.RedisArg(), false)
+		}
+		var buf bytes.Buffer
+		fmt.Fprint(&buf, arg)
+		return c.writeBytes(buf.Bytes())
+	default:
+		var buf bytes.Buffer
+		fmt.Fprint(&buf, arg)
+		return c.writeBytes(buf.Bytes())
+	}
+}
+
+func (c *resp3Conn) readLine() ([]byte, error) {
+	line, err := c.br.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, resp3ProtocolError("long response line")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	i := len(line) - 2
+	if i < 0 || line[i] != '\r' {
+		return nil, resp3ProtocolError("bad response line terminator")
+	}
+
+	return line[:i], nil
+}
+
+func (c *resp3Conn) readBlob(line []byte) ([]byte, error) {
+	n, err := resp3ParseLen(line)
+	if n < 0 || err != nil {
+		return nil, err
+	}
+
+	p := make([]byte, n)
+	if _, err := io.ReadFull(c.br, p); err != nil {
+		return nil, err
+	}
+
+	if line, err := c.readLine(); err != nil {
+		return nil, err
+	} else if len(line) != 0 {
+		return nil, resp3ProtocolError("bad blob format")
+	}
+
+	return p, nil
+}
+
+func (c *resp3Conn) readAggregate(line []byte, factor int) ([]interface{}, error) {
+	n, err := resp3ParseLen(line)
+	if n < 0 || err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, n*factor)
+	for i := range values {
+		if values[i], err = c.readReply(); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析回复，RESP3 类型映射：
+ * null(_) -> nil, double(,) -> float64, boolean(#) -> bool, big number(() -> *big.Int
+ * blob error(!) -> redis_go.Error, verbatim(=) -> string, map(%) -> map[string]interface{}
+ * set(~) -> []interface{}, attribute(|) -> 忽略并返回其后的回复, push(>) -> PushMessage
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *resp3Conn) readReply() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, resp3ProtocolError("short response line")
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return redis_go.Error(string(line[1:])), nil
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		blob, err := c.readBlob(line[1:])
+		if blob == nil || err != nil {
+			return nil, err
+		}
+		return blob, nil
+	case '*', '~':
+		values, err := c.readAggregate(line[1:], 1)
+		if values == nil || err != nil {
+			return nil, err
+		}
+		return values, nil
+	case '_':
+		return nil, nil
+	case ',':
+		return resp3ParseDouble(string(line[1:]))
+	case '#':
+		if len(line) != 2 || (line[1] != 't' && line[1] != 'f') {
+			return nil, resp3ProtocolError("malformed boolean")
+		}
+		return line[1] == 't', nil
+	case '(':
+		value, isOk := new(big.Int).SetString(string(line[1:]), 10)
+		if !isOk {
+			return nil, resp3ProtocolError("malformed big number")
+		}
+		return value, nil
+	case '!':
+		blob, err := c.readBlob(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		return redis_go.Error(string(blob)), nil
+	case '=':
+		blob, err := c.readBlob(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) >= 4 && blob[3] == ':' {
+			blob = blob[4:]
+		}
+		return string(blob), nil
+	case '%':
+		values, err := c.readAggregate(line[1:], 2)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]interface{}, len(values)/2)
+		for i := 0; i < len(values); i += 2 {
+			result[resp3MapKey(values[i])] = values[i+1]
+		}
+		return result, nil
+	case '|':
+		if _, err := c.readAggregate(line[1:], 2); err != nil {
+			return nil, err
+		}
+		return c.readReply()
+	case '>':
+		values, err := c.readAggregate(line[1:], 1)
+		if err != nil {
+			return nil, err
+		}
+		message := PushMessage{}
+		if len(values) > 0 {
+			message.Kind, _ = redis_go.String(values[0], nil)
+			message.Data = values[1:]
+		}
+		return message, nil
+	}
+
+	return nil, resp3ProtocolError("unexpected response line")
+}
+
+func resp3ParseLen(p []byte) (int, error) {
+	if len(p) == 0 {
+		return -1, resp3ProtocolError("malformed length")
+	}
+
+	if p[0] == '-' && len(p) == 2 && p[1] == '1' {
+		return -1, nil
+	}
+
+	n, err := strconv.Atoi(string(p))
+	if err != nil || n < 0 {
+		return -1, resp3ProtocolError("illegal bytes in length")
+	}
+
+	return n, nil
+}
+
+func resp3ParseDouble(s string) (float64, error) {
+	switch s {
+	case "inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan":
+		return math.NaN(), nil
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+func resp3MapKey(key interface{}) string {
+	switch key := key.(type) {
+	case string:
+		return key
+	case []byte:
+		return string(key)
+	}
+
+	return fmt.Sprint(key)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将 RESP3 回复转换为 RESP2 形态，使 redigo 的类型转换函数保持可用
+ * pairs 为 true 时将 [[a, b], ...] 形式的成对数组展开为 [a, b, ...]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func resp2Reply(reply interface{}, pairs bool) interface{} {
+	switch reply := reply.(type) {
+	case float64:
+		switch {
+		case math.IsInf(reply, 1):
+			return []byte("inf")
+		case math.IsInf(reply, -1):
+			return []byte("-inf")
+		}
+		return []byte(strconv.FormatFloat(reply, 'f', -1, 64))
+	case bool:
+		if reply {
+			return int64(1)
+		}
+		return int64(0)
+	case *big.Int:
+		return []byte(reply.String())
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(reply)*2)
+		for key, value := range reply {
+			values = append(values, []byte(key), resp2Reply(value, false))
+		}
+		return values
+	case []interface{}:
+		values := make([]interface{}, 0, len(reply))
+		for _, value := range reply {
+			if pair, isOk := value.([]interface{}); pairs && isOk && len(pair) == 2 {
+				values = append(values, resp2Reply(pair[0], false), resp2Reply(pair[1], false))
+			} else {
+				values = append(values, resp2Reply(value, false))
+			}
+		}
+		return values
+	}
+
+	return reply
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * RESP3 下带 WITHSCORES | WITHVALUES 的命令及 ZPOPMIN | ZPOPMAX 返回成对数组
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func isPairReply(commandName string, args []interface{}) bool {
+	switch strings.ToUpper(commandName) {
+	case "ZPOPMIN", "ZPOPMAX":
+		return true
+	}
+
+	for _, arg := range args {
+		if value, isOk := arg.(string); isOk {
+			if strings.EqualFold(value, "WITHSCORES") || strings.EqualFold(value, "WITHVALUES") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (err resp3ProtocolError) Error() string {
+	return fmt.Sprintf("gredis: %s (possible server error or unsupported concurrent read by application)", string(err))
+}
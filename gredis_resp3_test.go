@@ -0,0 +1,168 @@
+package gredis
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 以固定字节作为服务器回复的连接，写入的命令记录在 written 中
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+type fixtureConn struct {
+	net.Conn
+	reader  *bytes.Reader
+	written bytes.Buffer
+}
+
+func (c *fixtureConn) Read(p []byte) (int, error)       { return c.reader.Read(p) }
+func (c *fixtureConn) Write(p []byte) (int, error)      { return c.written.Write(p) }
+func (c *fixtureConn) Close() error                     { return nil }
+func (c *fixtureConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *fixtureConn) SetWriteDeadline(time.Time) error { return nil }
+
+func newFixtureConn(fixture string, pushHandler func(message PushMessage)) (*resp3Conn, *fixtureConn) {
+	netConn := &fixtureConn{reader: bytes.NewReader([]byte(fixture))}
+
+	return newResp3Conn(netConn, time.Second, time.Second, pushHandler), netConn
+}
+
+func TestResp3ReadReply(t *testing.T) {
+	cases := []struct {
+		name    string
+		fixture string
+		want    interface{}
+	}{
+		{"simple string", "+OK\r\n", "OK"},
+		{"integer", ":-42\r\n", int64(-42)},
+		{"blob string", "$5\r\nhello\r\n", []byte("hello")},
+		{"null", "_\r\n", nil},
+		{"double", ",3.25\r\n", 3.25},
+		{"double inf", ",-inf\r\n", math.Inf(-1)},
+		{"boolean", "#t\r\n", true},
+		{"big number", "(3492890328409238509324850943850943825024385\r\n", func() *big.Int {
+			value, _ := new(big.Int).SetString("3492890328409238509324850943850943825024385", 10)
+			return value
+		}()},
+		{"verbatim", "=15\r\ntxt:Some string\r\n", "Some string"},
+		{"blob error", "!21\r\nSYNTAX invalid syntax\r\n", redis_go.Error("SYNTAX invalid syntax")},
+		{"map", "%2\r\n+first\r\n:1\r\n$6\r\nsecond\r\n,2.5\r\n", map[string]interface{}{"first": int64(1), "second": 2.5}},
+		{"set", "~2\r\n+a\r\n+b\r\n", []interface{}{"a", "b"}},
+		{"nested", "*2\r\n*2\r\n$1\r\na\r\n,1\r\n*2\r\n$1\r\nb\r\n,2\r\n", []interface{}{
+			[]interface{}{[]byte("a"), 1.0},
+			[]interface{}{[]byte("b"), 2.0},
+		}},
+		{"attribute", "|1\r\n+key-popularity\r\n%1\r\n$1\r\na\r\n,0.19\r\n*1\r\n:2039123\r\n", []interface{}{int64(2039123)}},
+		{"push", ">3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n", PushMessage{Kind: "message", Data: []interface{}{[]byte("news"), []byte("hello")}}},
+	}
+
+	for _, testCase := range cases {
+		conn, _ := newFixtureConn(testCase.fixture, nil)
+
+		reply, err := conn.readReply()
+		if err != nil || !reflect.DeepEqual(reply, testCase.want) {
+			t.Errorf("%s: readReply = %#v, %v, want %#v", testCase.name, reply, err, testCase.want)
+		}
+	}
+}
+
+func TestResp3ReadReplyErrors(t *testing.T) {
+	for _, fixture := range []string{"#x\r\n", "(12a\r\n", "$3\r\nab\r\n", "+OK\n", "?\r\n"} {
+		conn, _ := newFixtureConn(fixture, nil)
+
+		if _, err := conn.readReply(); err == nil {
+			t.Errorf("readReply(%q) err = nil", fixture)
+		}
+	}
+}
+
+func TestResp3PushDispatch(t *testing.T) {
+	var messages []PushMessage
+	conn, netConn := newFixtureConn(">2\r\n$10\r\ninvalidate\r\n*1\r\n$3\r\nkey\r\n$3\r\nbar\r\n", func(message PushMessage) {
+		messages = append(messages, message)
+	})
+
+	reply, err := redis_go.String(conn.Do("GET", "foo"))
+	if err != nil || reply != "bar" {
+		t.Fatalf("Do = %q, %v", reply, err)
+	}
+
+	if netConn.written.String() != "*2\r\n$3\r\nGET\r\n$3\r\nfoo\r\n" {
+		t.Errorf("written = %q", netConn.written.String())
+	}
+
+	if len(messages) != 1 || messages[0].Kind != "invalidate" || !reflect.DeepEqual(messages[0].Data, []interface{}{[]interface{}{[]byte("key")}}) {
+		t.Errorf("push messages = %#v", messages)
+	}
+
+	// 未设置推送处理函数时，推送消息由 Receive 返回
+	conn, _ = newFixtureConn(">2\r\n$10\r\ninvalidate\r\n_\r\n", nil)
+	if message, err := conn.Receive(); err != nil || !reflect.DeepEqual(message, PushMessage{Kind: "invalidate", Data: []interface{}{nil}}) {
+		t.Errorf("Receive = %#v, %v", message, err)
+	}
+}
+
+func TestResp3Pipeline(t *testing.T) {
+	conn, _ := newFixtureConn("+OK\r\n-ERR boom\r\n:1\r\n", nil)
+
+	conn.Send("SET", "a", 1)
+	conn.Send("INCR", "b")
+
+	if reply, err := conn.Do("EXISTS", "a"); err == nil || reply != int64(1) {
+		t.Errorf("Do after Send = %v, %v, want last reply with first error", reply, err)
+	}
+}
+
+func TestResp2Reply(t *testing.T) {
+	values, err := redis_go.StringMap(resp2Reply(map[string]interface{}{"name": []byte("alice"), "age": []byte("30")}, false), nil)
+	if err != nil || !reflect.DeepEqual(values, map[string]string{"name": "alice", "age": "30"}) {
+		t.Errorf("map = %v, %v", values, err)
+	}
+
+	pairs := []interface{}{
+		[]interface{}{[]byte("a"), 1.5},
+		[]interface{}{[]byte("b"), math.Inf(1)},
+	}
+
+	flattened, err := redis_go.Strings(resp2Reply(pairs, true), nil)
+	if err != nil || !reflect.DeepEqual(flattened, []string{"a", "1.5", "b", "inf"}) {
+		t.Errorf("pairs = %v, %v", flattened, err)
+	}
+
+	// 非成对命令保留嵌套数组
+	if nested := resp2Reply(pairs, false).([]interface{}); len(nested) != 2 {
+		t.Errorf("nested = %#v", nested)
+	}
+
+	number, _ := new(big.Int).SetString("12345678901234567890", 10)
+	if value, err := redis_go.String(resp2Reply(number, false), nil); err != nil || value != "12345678901234567890" {
+		t.Errorf("big number = %q, %v", value, err)
+	}
+
+	if value := resp2Reply(true, false); value != int64(1) {
+		t.Errorf("boolean = %#v", value)
+	}
+
+	for _, command := range []struct {
+		name string
+		args []interface{}
+		want bool
+	}{
+		{"ZRANGE", []interface{}{"key", 0, -1, "withscores"}, true},
+		{"HRANDFIELD", []interface{}{"key", 2, "WITHVALUES"}, true},
+		{"ZPOPMIN", []interface{}{"key"}, true},
+		{"ZRANGE", []interface{}{"key", 0, -1}, false},
+	} {
+		if isPair := isPairReply(command.name, command.args); isPair != command.want {
+			t.Errorf("isPairReply(%s %v) = %v", command.name, command.args, isPair)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package gredis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+)
+
+/* ================================================================================
+ * Redis retry policy
+ * 对瞬时故障按指数退避（带抖动）自动重试，非幂等命令仅在确认未执行时重试
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	retryDefaultMinBackoff time.Duration = 8 * time.Millisecond
+	retryDefaultMaxBackoff time.Duration = 512 * time.Millisecond
+)
+
+var (
+	nonIdempotentCommands = map[string]bool{
+		REDIS_COMMAND_INCR:           true,
+		REDIS_COMMAND_INCRBY:         true,
+		REDIS_COMMAND_INCRBYFLOAT:    true,
+		REDIS_COMMAND_DECR:           true,
+		REDIS_COMMAND_DECRBY:         true,
+		REDIS_COMMAND_APPEND:         true,
+		REDIS_COMMAND_GETSET:         true,
+		REDIS_COMMAND_GETDEL:         true,
+		REDIS_COMMAND_SETNX:          true,
+		REDIS_COMMAND_MSETNX:         true,
+		REDIS_COMMAND_BITFIELD:       true,
+		REDIS_COMMAND_JSON_NUMINCRBY: true,
+		REDIS_COMMAND_JSON_ARRAPPEND: true,
+		REDIS_COMMAND_LPUSH:          true,
+		REDIS_COMMAND_RPUSH:          true,
+		REDIS_COMMAND_LPOP:           true,
+		REDIS_COMMAND_RPOP:           true,
+		REDIS_COMMAND_LPUSHX:         true,
+		REDIS_COMMAND_RPUSHX:         true,
+		REDIS_COMMAND_LINSERT:        true,
+		REDIS_COMMAND_LMOVE:          true,
+		REDIS_COMMAND_BLMOVE:         true,
+		REDIS_COMMAND_RPOPLPUSH:      true,
+		REDIS_COMMAND_BRPOPLPUSH:     true,
+		REDIS_COMMAND_BLPOP:          true,
+		REDIS_COMMAND_BRPOP:          true,
+		REDIS_COMMAND_LMPOP:          true,
+		REDIS_COMMAND_BLMPOP:         true,
+		REDIS_COMMAND_EVAL:           true,
+		REDIS_COMMAND_EVALSHA:        true,
+		REDIS_COMMAND_LREM:           true,
+		REDIS_COMMAND_LTRIM:          true,
+		REDIS_COMMAND_RENAME:         true,
+		REDIS_COMMAND_SMOVE:          true,
+		REDIS_COMMAND_HINCRBY:        true,
+		REDIS_COMMAND_HINCRBYFLOAT:   true,
+		REDIS_COMMAND_HGETDEL:        true,
+		REDIS_COMMAND_SPOP:           true,
+		REDIS_COMMAND_ZINCRBY:        true,
+		REDIS_COMMAND_ZPOPMIN:        true,
+		REDIS_COMMAND_ZPOPMAX:        true,
+		REDIS_COMMAND_BZPOPMIN:       true,
+		REDIS_COMMAND_BZPOPMAX:       true,
+	}
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 重试策略
+	 * MaxAttempts: 最大尝试次数（含首次），小于等于1时不重试
+	 * MinBackoff | MaxBackoff: 指数退避的初始及最大间隔
+	 * Retryable: 自定义瞬时错误判断，为nil时使用默认判断
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	RetryPolicy struct {
+		MaxAttempts int
+		MinBackoff  time.Duration
+		MaxBackoff  time.Duration
+		Retryable   func(err error) bool
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 判断第attempt次执行失败后是否重试
+ * 命令确认未执行（连接失败、LOADING、BUSY、TRYAGAIN等）时总是可重试，
+ * 可能已执行的失败（读写超时、连接断开）仅对幂等命令重试
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *RetryPolicy) shouldRetry(attempt int, isIdempotent bool, err error) bool {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if p.Retryable != nil {
+		if !p.Retryable(err) {
+			return false
+		}
+	} else if !isRetryableError(err) {
+		return false
+	}
+
+	return isIdempotent || isNotExecutedError(err)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 第attempt次失败后的退避时间：指数增长，取[backoff/2, backoff]之间的随机值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	minBackoff := p.MinBackoff
+	if minBackoff <= 0 {
+		minBackoff = retryDefaultMinBackoff
+	}
+
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = retryDefaultMaxBackoff
+	}
+
+	backoff := minBackoff
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	half := int64(backoff / 2)
+
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 第attempt次失败后退避等待，上下文结束时立即返回 ctx.Err()
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 是否为幂等命令，部分命令取决于参数：SET | JSON.SET 带 NX | XX | GET 时重复执行的结果取决于首次执行，
+ * ZADD INCR 重复执行会再次累加
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func isIdempotentCommand(commandName string, args []interface{}) bool {
+	name := strings.ToUpper(commandName)
+	if nonIdempotentCommands[name] {
+		return false
+	}
+
+	switch name {
+	case REDIS_COMMAND_SET:
+		// SET key value [NX | XX] [GET] [EX seconds | PX milliseconds | ...]
+		return !hasArgOption(args, 2, "NX", "XX", "GET")
+	case REDIS_COMMAND_JSON_SET:
+		// JSON.SET key path value [NX | XX]
+		return !hasArgOption(args, 3, "NX", "XX")
+	case REDIS_COMMAND_ZADD:
+		// ZADD key [NX | XX] [GT | LT] [CH] [INCR] score member ...
+		for index := 1; index < len(args); index++ {
+			option, _ := args[index].(string)
+
+			switch strings.ToUpper(option) {
+			case "INCR":
+				return false
+			case "NX", "XX", "GT", "LT", "CH":
+			default:
+				return true
+			}
+		}
+	}
+
+	return true
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 自第start个参数起是否包含选项之一（不区分大小写）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hasArgOption(args []interface{}, start int, options ...string) bool {
+	for index := start; index < len(args); index++ {
+		value, isOk := args[index].(string)
+		if !isOk {
+			continue
+		}
+
+		for _, option := range options {
+			if strings.EqualFold(value, option) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 默认的瞬时错误判断
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func isRetryableError(err error) bool {
+	if isNotExecutedError(err) {
+		return true
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) {
+		return true
+	}
+
+	var redisError *RedisError
+	if errors.As(err, &redisError) && len(redisError.Prefix) == 0 {
+		message := redisError.Err.Error()
+		return strings.Contains(message, "closed") || strings.Contains(message, "connection reset")
+	}
+
+	return false
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 命令确认未被服务器执行的错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func isNotExecutedError(err error) bool {
+	if errors.Is(err, ErrLoading) ||
+		errors.Is(err, ErrBusy) ||
+		errors.Is(err, ErrTryAgain) ||
+		errors.Is(err, ErrClusterDown) ||
+		errors.Is(err, ErrPoolExhausted) {
+		return true
+	}
+
+	var opError *net.OpError
+	if errors.As(err, &opError) && opError.Op == "dial" {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,470 @@
+package gredis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+/* ================================================================================
+ * Job scheduler
+ * 基于有序集合的定时任务调度：任务按执行时间（毫秒）存入有序集合，
+ * 轮询器通过 Lua 原子地领取到期任务，领取后任务分值被推迟 ClaimTimeout，
+ * 处理成功后删除（周期任务设置下一次执行时间），处理失败或进程退出时任务将再次被领取
+ * 键（均在客户端前缀之下）：
+ * {name}:schedule 调度有序集合 | {name}:payloads | {name}:crons 任务Id到内容及 cron 表达式的哈希
+ * {name}:unique | {name}:uniques 唯一键与任务Id的双向哈希 | {name}:leader 轮询器领导者锁 | {name}:seq 任务Id序列
+ * 脚本访问的键均通过 KEYS 传入，Redis Cluster 中名称使用哈希标签（如 "{jobs}"）时所有键位于同一槽
+ * ScheduleIn 及 cron 任务的执行时间基于 Redis 服务器时间，不受客户端时钟偏差影响；
+ * ScheduleAt 的 runAt 按原样与服务器时间比较，调用方需保证其时钟与服务器一致
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	schedulerDefaultPollInterval time.Duration = time.Second
+	schedulerDefaultClaimTimeout time.Duration = time.Minute
+	schedulerDefaultLeaderTtl    time.Duration = 10 * time.Second
+	schedulerDefaultBatchSize    int           = 100
+)
+
+var (
+	ErrDuplicateJob = errors.New("gredis: duplicate job")
+)
+
+var (
+	schedulerScheduleScript = NewScript(queueNowScript + `
+if ARGV[2] ~= '' then
+	local existing = redis.call('HGET', KEYS[3], ARGV[2])
+	if existing then
+		return {existing, '0'}
+	end
+end
+local id = ARGV[1]
+if id == '' then
+	id = tostring(redis.call('INCR', KEYS[1]))
+end
+local previous = redis.call('HGET', KEYS[6], id)
+if previous and previous ~= ARGV[2] then
+	redis.call('HDEL', KEYS[3], previous)
+end
+redis.call('HSET', KEYS[4], id, ARGV[4])
+redis.call('HSET', KEYS[5], id, ARGV[5])
+if ARGV[2] ~= '' then
+	redis.call('HSET', KEYS[3], ARGV[2], id)
+	redis.call('HSET', KEYS[6], id, ARGV[2])
+else
+	redis.call('HDEL', KEYS[6], id)
+end
+local runAt = tonumber(ARGV[3])
+if ARGV[6] == '1' then
+	runAt = ms + runAt
+end
+redis.call('ZADD', KEYS[2], runAt, id)
+return {id, '1'}
+`)
+
+	schedulerCancelScript = NewScript(`
+local unique = redis.call('HGET', KEYS[5], ARGV[1])
+if unique then
+	redis.call('HDEL', KEYS[2], unique)
+end
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+redis.call('HDEL', KEYS[5], ARGV[1])
+return redis.call('ZREM', KEYS[1], ARGV[1])
+`)
+
+	schedulerClaimScript = NewScript(queueNowScript + `
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ms, 'LIMIT', 0, tonumber(ARGV[1]))
+local jobs = {tostring(ms)}
+for _, id in ipairs(ids) do
+	local payload = redis.call('HGET', KEYS[2], id)
+	if payload then
+		redis.call('ZADD', KEYS[1], ms + tonumber(ARGV[2]), id)
+		table.insert(jobs, id)
+		table.insert(jobs, payload)
+		table.insert(jobs, redis.call('HGET', KEYS[3], id) or '')
+	else
+		redis.call('ZREM', KEYS[1], id)
+	end
+end
+return jobs
+`)
+
+	schedulerCompleteScript = NewScript(`
+if redis.call('HEXISTS', KEYS[3], ARGV[1]) == 0 then
+	return 0
+end
+if ARGV[2] ~= '' then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+	return 1
+end
+local unique = redis.call('HGET', KEYS[5], ARGV[1])
+if unique then
+	redis.call('HDEL', KEYS[2], unique)
+end
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+redis.call('HDEL', KEYS[5], ARGV[1])
+redis.call('ZREM', KEYS[1], ARGV[1])
+return 1
+`)
+
+	schedulerElectScript = NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if owner == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if not owner then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0
+`)
+
+	schedulerTimeScript = NewScript(queueNowScript + `
+return ms
+`)
+
+	schedulerResignScript = NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 调度器选项
+	 * PollInterval: 轮询间隔，默认1秒
+	 * ClaimTimeout: 领取后未完成的任务再次被领取的时长，默认1分钟
+	 * LeaderTtl: 领导者锁的过期时长，领导者退出后其它实例最长在此时长后接管，默认10秒
+	 * BatchSize: 每次最多领取的任务数，默认100
+	 * Location: 解析 cron 表达式使用的时区，默认 time.Local
+	 * OnError: 任务处理函数返回错误时调用，为nil时忽略（任务在 ClaimTimeout 后再次被领取）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SchedulerOption struct {
+		PollInterval time.Duration
+		ClaimTimeout time.Duration
+		LeaderTtl    time.Duration
+		BatchSize    int
+		Location     *time.Location
+		OnError      func(job *ScheduledJob, err error)
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 调度选项
+	 * Id: 自定义任务Id，已存在时覆盖原任务（适用于每次启动时注册的周期任务）
+	 * UniqueKey: 唯一键，存在未完成的同键任务时不重复调度，返回已有任务Id及 ErrDuplicateJob
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ScheduleOption struct {
+		Id        string
+		UniqueKey string
+	}
+
+	Scheduler struct {
+		redis  IRedis
+		name   string
+		token  string
+		option SchedulerOption
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 已领取的定时任务
+	 * Cron: 周期任务的 cron 表达式，一次性任务为空
+	 * ClaimedAt: 领取时的服务器时间
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ScheduledJob struct {
+		Id        string
+		Payload   []byte
+		Cron      string
+		ClaimedAt time.Time
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化调度器
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewScheduler(redis IRedis, name string, args ...SchedulerOption) *Scheduler {
+	option := SchedulerOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	if option.PollInterval <= 0 {
+		option.PollInterval = schedulerDefaultPollInterval
+	}
+
+	if option.ClaimTimeout <= 0 {
+		option.ClaimTimeout = schedulerDefaultClaimTimeout
+	}
+
+	if option.LeaderTtl <= 0 {
+		option.LeaderTtl = schedulerDefaultLeaderTtl
+	}
+
+	if option.BatchSize <= 0 {
+		option.BatchSize = schedulerDefaultBatchSize
+	}
+
+	if option.Location == nil {
+		option.Location = time.Local
+	}
+
+	token := make([]byte, 16)
+	rand.Read(token)
+
+	return &Scheduler{
+		redis:  redis,
+		name:   name,
+		token:  hex.EncodeToString(token),
+		option: option,
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 在 runAt 执行一次性任务，返回任务Id
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) ScheduleAt(runAt time.Time, payload interface{}, args ...ScheduleOption) (string, error) {
+	return s.schedule(runAt.UnixMilli(), false, payload, "", args)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 以服务器时间为准，delay 后执行一次性任务，返回任务Id
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) ScheduleIn(delay time.Duration, payload interface{}, args ...ScheduleOption) (string, error) {
+	return s.schedule(delay.Milliseconds(), true, payload, "", args)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按 cron 表达式周期执行任务，返回任务Id，表达式无效时返回 ErrInvalidCron
+ * 首次执行时间自服务器时间起计算
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) ScheduleCron(spec string, payload interface{}, args ...ScheduleOption) (string, error) {
+	cron, err := parseCron(spec)
+	if err != nil {
+		return "", err
+	}
+
+	now, err := s.now()
+	if err != nil {
+		return "", err
+	}
+
+	runAt := cron.next(now.In(s.option.Location))
+	if runAt.IsZero() {
+		return "", ErrInvalidCron
+	}
+
+	return s.schedule(runAt.UnixMilli(), false, payload, spec, args)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 写入任务，isDelay 为 true 时 runAt 为相对服务器时间的延迟毫秒数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) schedule(runAt int64, isDelay bool, payload interface{}, spec string, args []ScheduleOption) (string, error) {
+	option := ScheduleOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	keys := []string{s.key("seq"), s.key("schedule"), s.key("unique"), s.key("payloads"), s.key("crons"), s.key("uniques")}
+
+	delayFlag := "0"
+	if isDelay {
+		delayFlag = "1"
+	}
+
+	values, err := replyStrings(s.redis.Eval(schedulerScheduleScript, keys,
+		option.Id, option.UniqueKey, runAt, payload, spec, delayFlag))
+	if err != nil {
+		return "", err
+	}
+
+	if len(values) != 2 {
+		return "", ErrUnexpectedReply
+	}
+
+	if values[1] == "0" {
+		return values[0], ErrDuplicateJob
+	}
+
+	return values[0], nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 取消任务，任务不存在时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) Cancel(id string) error {
+	count, err := replyInt(s.redis.Eval(schedulerCancelScript, s.jobKeys(), id))
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 原子地领取到期任务，处理成功后需调用 Complete
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) Claim() ([]*ScheduledJob, error) {
+	keys := []string{s.key("schedule"), s.key("payloads"), s.key("crons")}
+
+	values, err := replyStrings(s.redis.Eval(schedulerClaimScript, keys, s.option.BatchSize, s.option.ClaimTimeout.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 || (len(values)-1)%3 != 0 {
+		return nil, ErrUnexpectedReply
+	}
+
+	now, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return nil, ErrUnexpectedReply
+	}
+
+	claimedAt := time.UnixMilli(now)
+
+	jobs := make([]*ScheduledJob, 0, (len(values)-1)/3)
+	for index := 1; index < len(values); index += 3 {
+		jobs = append(jobs, &ScheduledJob{
+			Id:        values[index],
+			Payload:   []byte(values[index+1]),
+			Cron:      values[index+2],
+			ClaimedAt: claimedAt,
+		})
+	}
+
+	return jobs, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 完成已领取的任务：一次性任务被删除，周期任务设置下一次执行时间
+ * 任务已被取消时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) Complete(job *ScheduledJob) error {
+	next := ""
+	if len(job.Cron) > 0 {
+		cron, err := parseCron(job.Cron)
+		if err != nil {
+			return err
+		}
+
+		runAt := cron.next(job.ClaimedAt.In(s.option.Location))
+		if runAt.IsZero() {
+			return ErrInvalidCron
+		}
+
+		next = strconv.FormatInt(runAt.UnixMilli(), 10)
+	}
+
+	count, err := replyInt(s.redis.Eval(schedulerCompleteScript, s.jobKeys(), job.Id, next))
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 竞选或续期轮询器领导者，返回当前实例是否为领导者
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) Elect() (bool, error) {
+	return replyBool(s.redis.Eval(schedulerElectScript, []string{s.key("leader")}, s.token, s.option.LeaderTtl.Milliseconds()))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 放弃领导者身份，使其它实例可立即接管
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) Resign() error {
+	_, err := s.redis.Eval(schedulerResignScript, []string{s.key("leader")}, s.token)
+	return err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 运行轮询器直到 ctx 结束或出错：仅领导者领取到期任务并交给 handler 处理，
+ * handler 返回 nil 时完成任务，否则调用 OnError，任务在 ClaimTimeout 后再次被领取；
+ * 竞选、领取或完成任务出错时返回该错误，由调用方决定是否重新运行
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) Run(ctx context.Context, handler func(job *ScheduledJob) error) error {
+	ticker := time.NewTicker(s.option.PollInterval)
+	defer ticker.Stop()
+	defer s.Resign()
+
+	for {
+		isLeader, err := s.Elect()
+		if err != nil {
+			return err
+		}
+
+		if isLeader {
+			if err := s.dispatch(handler); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) dispatch(handler func(job *ScheduledJob) error) error {
+	jobs, err := s.Claim()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := handler(job); err != nil {
+			if s.option.OnError != nil {
+				s.option.OnError(job, err)
+			}
+			continue
+		}
+
+		// 任务在处理期间被取消时无需完成
+		if err := s.Complete(job); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取 Redis 服务器时间
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Scheduler) now() (time.Time, error) {
+	ms, err := replyInt64(s.redis.Eval(schedulerTimeScript, []string{s.key("schedule")}))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.UnixMilli(ms), nil
+}
+
+func (s *Scheduler) key(suffix string) string {
+	return s.name + ":" + suffix
+}
+
+func (s *Scheduler) jobKeys() []string {
+	return []string{s.key("schedule"), s.key("unique"), s.key("payloads"), s.key("crons"), s.key("uniques")}
+}
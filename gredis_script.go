@@ -0,0 +1,63 @@
+package gredis
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ================================================================================
+ * Lua script
+ * 优先以 EVALSHA 执行，服务器未缓存脚本时回退到 EVAL
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	Script struct {
+		src  string
+		hash string
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化 Lua 脚本
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewScript(src string) *Script {
+	hash := sha1.Sum([]byte(src))
+
+	return &Script{
+		src:  src,
+		hash: hex.EncodeToString(hash[:]),
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 脚本的 SHA1 摘要
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行 Lua 脚本，keys 自动添加前缀，args 原样传递
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *redisClient) Eval(script *Script, keys []string, args ...interface{}) (interface{}, error) {
+	evalArgs := redis_go.Args{}.Add(script.hash).Add(len(keys))
+	for _, key := range keys {
+		evalArgs = evalArgs.Add(s.GetKey(key))
+	}
+	evalArgs = evalArgs.Add(args...)
+
+	reply, err := s.command(REDIS_COMMAND_EVALSHA, evalArgs...)
+	if errors.Is(err, ErrNoScript) {
+		evalArgs[0] = script.src
+		reply, err = s.command(REDIS_COMMAND_EVAL, evalArgs...)
+	}
+
+	return reply, err
+}
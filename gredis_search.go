@@ -0,0 +1,602 @@
+package gredis
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ================================================================================
+ * Search query builder
+ * FT.SEARCH | FT.AGGREGATE 的查询构造，以及由结构体标签推导索引 schema
+ * 查询子句之间为交集，查询始终使用 DIALECT 2
+ * schema 的字段名与 HSetData | HGetData 一致取 redis 标签，类型及选项取 search 标签：
+ * Name      string  `redis:"name" search:"text,sortable,weight=2"`
+ * Brand     string  `redis:"brand" search:"tag,separator=|,casesensitive"`
+ * Price     float64 `redis:"price" search:"numeric,sortable"`
+ * Location  string  `redis:"location" search:"geo"`，值为 "longitude,latitude"
+ * Embedding []byte  `redis:"embedding" search:"vector,dim=128,distance=cosine,algorithm=hnsw"`
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	searchDialect        int    = 2
+	searchEscapeChars    string = ",.<>{}[]\"':;!@#$%^&*()-+=~|/\\ "
+	searchKnnVectorParam string = "knn_vector"
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * FT.SEARCH 查询
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SearchQuery struct {
+		clauses      []string
+		knn          string
+		knnAlias     string
+		knnCount     int
+		params       []interface{}
+		returns      []string
+		sortBy       string
+		sortOrder    string
+		offset       int
+		num          int
+		isLimit      bool
+		isNoContent  bool
+		isWithScores bool
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * FT.AGGREGATE 查询，按添加顺序执行 LOAD | GROUPBY | SORTBY | LIMIT
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	AggregateQuery struct {
+		query *SearchQuery
+		steps redis_go.Args
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * GROUPBY 归约函数，由 ReduceCount | ReduceSum 等构造
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SearchReducer struct {
+		args redis_go.Args
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 由结构体的 search 标签推导索引字段，未设置 search 标签的字段不参与索引
+ * 向量字段必须指定 dim，算法默认 FLAT，距离默认 COSINE
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func SearchSchema(structData interface{}) ([]SearchField, error) {
+	structType := reflect.TypeOf(structData)
+	for structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("gredis: search schema requires a struct, got %T", structData)
+	}
+
+	return searchSchemaFields(structType)
+}
+
+func searchSchemaFields(structType reflect.Type) ([]SearchField, error) {
+	fields := make([]SearchField, 0)
+	for index := 0; index < structType.NumField(); index++ {
+		structField := structType.Field(index)
+
+		redisTag := structField.Tag.Get("redis")
+		if structField.Anonymous && len(redisTag) == 0 && structField.Type.Kind() == reflect.Struct {
+			embedded, err := searchSchemaFields(structField.Type)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, embedded...)
+			continue
+		}
+
+		searchTag := structField.Tag.Get("search")
+		if len(structField.PkgPath) > 0 || len(searchTag) == 0 || searchTag == "-" {
+			continue
+		}
+
+		name := strings.Split(redisTag, ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = structField.Name
+		}
+
+		field, err := parseSearchTag(name, searchTag)
+		if err != nil {
+			return nil, fmt.Errorf("gredis: invalid search tag on field %s: %v", structField.Name, err)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func parseSearchTag(name, tag string) (SearchField, error) {
+	options := strings.Split(tag, ",")
+
+	field := SearchField{Name: name, Type: strings.ToUpper(strings.TrimSpace(options[0]))}
+	switch field.Type {
+	case REDIS_SEARCH_FIELD_TEXT, REDIS_SEARCH_FIELD_TAG, REDIS_SEARCH_FIELD_NUMERIC, REDIS_SEARCH_FIELD_GEO:
+	case REDIS_SEARCH_FIELD_VECTOR:
+		field.Algorithm = REDIS_SEARCH_VECTOR_FLAT
+		field.Distance = REDIS_SEARCH_DISTANCE_COSINE
+	default:
+		return field, fmt.Errorf("unknown field type %q", options[0])
+	}
+
+	for _, option := range options[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		var err error
+		switch strings.ToLower(key) {
+		case "sortable":
+			field.Sortable = true
+		case "noindex":
+			field.NoIndex = true
+		case "nostem":
+			field.NoStem = true
+		case "casesensitive":
+			field.CaseSensitive = true
+		case "weight":
+			field.Weight, err = strconv.ParseFloat(value, 64)
+		case "separator":
+			field.Separator = value
+		case "dim":
+			field.Dim, err = strconv.Atoi(value)
+		case "distance":
+			field.Distance = strings.ToUpper(value)
+		case "algorithm":
+			field.Algorithm = strings.ToUpper(value)
+		default:
+			err = fmt.Errorf("unknown option %q", option)
+		}
+
+		if err != nil {
+			return field, err
+		}
+	}
+
+	if field.Type == REDIS_SEARCH_FIELD_VECTOR && field.Dim <= 0 {
+		return field, fmt.Errorf("vector field %s requires dim", name)
+	}
+
+	return field, nil
+}
+
+func searchFieldArgs(args redis_go.Args, field SearchField) redis_go.Args {
+	args = args.Add(field.Name).Add(field.Type)
+
+	switch field.Type {
+	case REDIS_SEARCH_FIELD_TEXT:
+		if field.Weight > 0 && field.Weight != 1 {
+			args = args.Add("WEIGHT").Add(field.Weight)
+		}
+
+		if field.NoStem {
+			args = args.Add("NOSTEM")
+		}
+	case REDIS_SEARCH_FIELD_TAG:
+		if len(field.Separator) > 0 {
+			args = args.Add("SEPARATOR").Add(field.Separator)
+		}
+
+		if field.CaseSensitive {
+			args = args.Add("CASESENSITIVE")
+		}
+	case REDIS_SEARCH_FIELD_VECTOR:
+		args = args.Add(field.Algorithm).Add(6).Add("TYPE").Add("FLOAT32").Add("DIM").Add(field.Dim).Add("DISTANCE_METRIC").Add(field.Distance)
+	}
+
+	if field.Sortable {
+		args = args.Add("SORTABLE")
+	}
+
+	if field.NoIndex {
+		args = args.Add("NOINDEX")
+	}
+
+	return args
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 转义查询中的特殊字符（含空格），用于标签值及需按原文匹配的词项
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func EscapeSearchValue(value string) string {
+	var builder strings.Builder
+	for _, char := range value {
+		if strings.ContainsRune(searchEscapeChars, char) {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(char)
+	}
+
+	return builder.String()
+}
+
+func escapeSearchText(text string) string {
+	words := strings.Fields(text)
+	for index, word := range words {
+		words[index] = EscapeSearchValue(word)
+	}
+
+	return strings.Join(words, " ")
+}
+
+func formatSearchNumber(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化查询，未添加子句时匹配全部文档
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 原样添加查询子句，可使用完整的查询语法（如并集、否定、前缀匹配）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) Raw(clause string) *SearchQuery {
+	if len(strings.TrimSpace(clause)) > 0 {
+		q.clauses = append(q.clauses, clause)
+	}
+
+	return q
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 在全部文本字段中匹配 text 的每个词
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) Text(text string) *SearchQuery {
+	return q.Raw(escapeSearchText(text))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 在指定文本字段中匹配 text 的每个词
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) TextField(field, text string) *SearchQuery {
+	if text = escapeSearchText(text); len(text) == 0 {
+		return q
+	}
+
+	return q.Raw("@" + field + ":(" + text + ")")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 标签字段匹配任一 values
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) Tag(field string, values ...string) *SearchQuery {
+	if len(values) == 0 {
+		return q
+	}
+
+	tags := make([]string, 0, len(values))
+	for _, value := range values {
+		tags = append(tags, EscapeSearchValue(value))
+	}
+
+	return q.Raw("@" + field + ":{" + strings.Join(tags, " | ") + "}")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 数值字段在 [min, max] 之间，无边界时使用 math.Inf
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) NumericRange(field string, min, max float64) *SearchQuery {
+	return q.Raw("@" + field + ":[" + formatSearchNumber(min) + " " + formatSearchNumber(max) + "]")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 地理位置字段在以 longitude | latitude 为中心的 radius 半径内
+ * unitArgs: 距离单位 REDIS_GEO_UNIT_*，默认米
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) Geo(field string, longitude, latitude, radius float64, unitArgs ...string) *SearchQuery {
+	unit := REDIS_GEO_UNIT_M
+	if len(unitArgs) > 0 && len(unitArgs[0]) > 0 {
+		unit = unitArgs[0]
+	}
+
+	return q.Raw(fmt.Sprintf("@%s:[%s %s %s %s]", field, formatSearchNumber(longitude), formatSearchNumber(latitude), formatSearchNumber(radius), unit))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 向量 KNN 查询，在其他子句的结果中返回与 vector 最近的 k 个文档
+ * vector 为 FLOAT32 小端序二进制，aliasArgs: 距离字段名，默认 __{field}_score
+ * 未指定 SortBy 时按距离升序，未指定 Limit 时返回全部 k 个文档，重复调用时替换之前的 KNN 子句
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) Knn(field string, k int, vector []byte, aliasArgs ...string) *SearchQuery {
+	q.knnAlias = "__" + field + "_score"
+	if len(aliasArgs) > 0 && len(aliasArgs[0]) > 0 {
+		q.knnAlias = aliasArgs[0]
+	}
+	q.knnCount = k
+
+	q.knn = fmt.Sprintf("[KNN %d @%s $%s AS %s]", k, field, searchKnnVectorParam, q.knnAlias)
+
+	return q.Param(searchKnnVectorParam, vector)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 添加查询参数，在查询中以 $name 引用，同名参数替换之前的值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) Param(name string, value interface{}) *SearchQuery {
+	for index := 0; index+1 < len(q.params); index += 2 {
+		if q.params[index] == name {
+			q.params[index+1] = value
+			return q
+		}
+	}
+
+	q.params = append(q.params, name, value)
+	return q
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 仅返回指定字段，默认返回文档全部字段
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) Return(fields ...string) *SearchQuery {
+	q.returns = fields
+	return q
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按字段排序，orderArgs: REDIS_SEARCH_SORT_ASC | DESC，默认升序
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) SortBy(field string, orderArgs ...string) *SearchQuery {
+	q.sortBy = field
+	q.sortOrder = REDIS_SEARCH_SORT_ASC
+	if len(orderArgs) > 0 && len(orderArgs[0]) > 0 {
+		q.sortOrder = orderArgs[0]
+	}
+
+	return q
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 分页，默认返回前10个文档（KNN 查询默认返回 k 个文档）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) Limit(offset, num int) *SearchQuery {
+	q.offset, q.num, q.isLimit = offset, num, true
+	return q
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 仅返回文档 Id
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) NoContent() *SearchQuery {
+	q.isNoContent = true
+	return q
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 返回文档的相关度分数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) WithScores() *SearchQuery {
+	q.isWithScores = true
+	return q
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取查询字符串
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q *SearchQuery) String() string {
+	query := strings.Join(q.clauses, " ")
+	if len(query) == 0 {
+		query = "*"
+	} else if len(q.knn) > 0 {
+		query = "(" + query + ")"
+	}
+
+	if len(q.knn) > 0 {
+		query += "=>" + q.knn
+	}
+
+	return query
+}
+
+func (q *SearchQuery) paramsArgs(args redis_go.Args) redis_go.Args {
+	if len(q.params) > 0 {
+		args = args.Add("PARAMS").Add(len(q.params)).Add(q.params...)
+	}
+
+	return args.Add("DIALECT").Add(searchDialect)
+}
+
+func (q *SearchQuery) args(args redis_go.Args) redis_go.Args {
+	args = args.Add(q.String())
+
+	if q.isNoContent {
+		args = args.Add("NOCONTENT")
+	}
+
+	if q.isWithScores {
+		args = args.Add("WITHSCORES")
+	}
+
+	if len(q.returns) > 0 {
+		args = args.Add("RETURN").Add(len(q.returns)).Add(stringsArgs(q.returns)...)
+	}
+
+	if len(q.sortBy) > 0 {
+		args = args.Add("SORTBY").Add(q.sortBy).Add(q.sortOrder)
+	} else if len(q.knnAlias) > 0 {
+		args = args.Add("SORTBY").Add(q.knnAlias).Add(REDIS_SEARCH_SORT_ASC)
+	}
+
+	if q.isLimit {
+		args = args.Add("LIMIT").Add(q.offset).Add(q.num)
+	} else if q.knnCount > 0 {
+		args = args.Add("LIMIT").Add(0).Add(q.knnCount)
+	}
+
+	return q.paramsArgs(args)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化聚合查询，queryArgs: 筛选文档的查询（仅使用其子句及参数），默认全部文档
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewAggregateQuery(queryArgs ...*SearchQuery) *AggregateQuery {
+	query := NewSearchQuery()
+	if len(queryArgs) > 0 && queryArgs[0] != nil {
+		query = queryArgs[0]
+	}
+
+	return &AggregateQuery{query: query}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 加载文档字段，未指定字段时加载全部字段
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *AggregateQuery) Load(fields ...string) *AggregateQuery {
+	if len(fields) == 0 {
+		a.steps = a.steps.Add("LOAD").Add("*")
+		return a
+	}
+
+	a.steps = a.steps.Add("LOAD").Add(len(fields)).Add(searchProperties(fields)...)
+
+	return a
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按字段分组并归约
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *AggregateQuery) GroupBy(fields []string, reducers ...SearchReducer) *AggregateQuery {
+	a.steps = a.steps.Add("GROUPBY").Add(len(fields)).Add(searchProperties(fields)...)
+	for _, reducer := range reducers {
+		a.steps = append(a.steps, reducer.args...)
+	}
+
+	return a
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按字段排序，orderArgs: REDIS_SEARCH_SORT_ASC | DESC，默认升序
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *AggregateQuery) SortBy(field string, orderArgs ...string) *AggregateQuery {
+	order := REDIS_SEARCH_SORT_ASC
+	if len(orderArgs) > 0 && len(orderArgs[0]) > 0 {
+		order = orderArgs[0]
+	}
+
+	a.steps = a.steps.Add("SORTBY").Add(2).Add("@" + field).Add(order)
+
+	return a
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 分页
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (a *AggregateQuery) Limit(offset, num int) *AggregateQuery {
+	a.steps = a.steps.Add("LIMIT").Add(offset).Add(num)
+	return a
+}
+
+func (a *AggregateQuery) args(args redis_go.Args) redis_go.Args {
+	args = append(args.Add(a.query.String()), a.steps...)
+	return a.query.paramsArgs(args)
+}
+
+func searchProperties(fields []string) redis_go.Args {
+	args := make(redis_go.Args, 0, len(fields))
+	for _, field := range fields {
+		args = append(args, "@"+field)
+	}
+
+	return args
+}
+
+func newSearchReducer(function, field, as string) SearchReducer {
+	args := redis_go.Args{}.Add("REDUCE").Add(function)
+	if len(field) > 0 {
+		args = args.Add(1).Add("@" + field)
+	} else {
+		args = args.Add(0)
+	}
+
+	if len(as) > 0 {
+		args = args.Add("AS").Add(as)
+	}
+
+	return SearchReducer{args: args}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 归约函数：分组内的文档数 | 不同值个数 | 求和 | 平均值 | 最小值 | 最大值
+ * as 为结果字段名，为空时由服务器生成
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func ReduceCount(as string) SearchReducer {
+	return newSearchReducer("COUNT", "", as)
+}
+
+func ReduceCountDistinct(field, as string) SearchReducer {
+	return newSearchReducer("COUNT_DISTINCT", field, as)
+}
+
+func ReduceSum(field, as string) SearchReducer {
+	return newSearchReducer("SUM", field, as)
+}
+
+func ReduceAvg(field, as string) SearchReducer {
+	return newSearchReducer("AVG", field, as)
+}
+
+func ReduceMin(field, as string) SearchReducer {
+	return newSearchReducer("MIN", field, as)
+}
+
+func ReduceMax(field, as string) SearchReducer {
+	return newSearchReducer("MAX", field, as)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将文档字段扫描到结构体，字段映射规则与 HGetData 一致（redis 标签）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (d SearchDocument) Scan(structData interface{}) error {
+	values := make([]interface{}, 0, len(d.Fields)*2)
+	for name, value := range d.Fields {
+		values = append(values, []byte(name), []byte(value))
+	}
+
+	return redis_go.ScanStruct(values, structData)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将搜索结果的文档扫描为结构体列表，用于 FTSearch 的返回值，如：
+ * products, err := gredis.ScanDocuments[Product](redis.FTSearch(index, query))
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func ScanDocuments[T any](result SearchResult, err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]T, len(result.Documents))
+	for index, document := range result.Documents {
+		if err := document.Scan(&items[index]); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
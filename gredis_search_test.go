@@ -0,0 +1,151 @@
+package gredis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * FT.SEARCH | FT.AGGREGATE | FT.INFO 回复形式的样例，按 RediSearch 文档中 RESP2 及 RESP3
+ * 的回复结构编写（非真实服务器抓取），仅保留解析使用的信息项
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+const (
+	searchResp2Fixture = "*7\r\n:2\r\n" +
+		"$7\r\nshop:p1\r\n$3\r\n1.5\r\n*4\r\n$4\r\nname\r\n$4\r\nlamp\r\n$5\r\nprice\r\n$2\r\n20\r\n" +
+		"$7\r\nshop:p2\r\n$3\r\n0.5\r\n*4\r\n$4\r\nname\r\n$4\r\ndesk\r\n$5\r\nprice\r\n$3\r\n150\r\n"
+
+	searchResp3Fixture = "%5\r\n$10\r\nattributes\r\n*0\r\n$6\r\nformat\r\n+STRING\r\n$7\r\nresults\r\n*2\r\n" +
+		"%4\r\n$2\r\nid\r\n$7\r\nshop:p1\r\n$5\r\nscore\r\n,1.5\r\n" +
+		"$16\r\nextra_attributes\r\n%2\r\n$4\r\nname\r\n$4\r\nlamp\r\n$5\r\nprice\r\n$2\r\n20\r\n$6\r\nvalues\r\n*0\r\n" +
+		"%4\r\n$2\r\nid\r\n$7\r\nshop:p2\r\n$5\r\nscore\r\n,0.5\r\n" +
+		"$16\r\nextra_attributes\r\n%2\r\n$4\r\nname\r\n$4\r\ndesk\r\n$5\r\nprice\r\n$3\r\n150\r\n$6\r\nvalues\r\n*0\r\n" +
+		"$13\r\ntotal_results\r\n:2\r\n$7\r\nwarning\r\n*0\r\n"
+
+	searchNoContentResp3Fixture = "%4\r\n$10\r\nattributes\r\n*0\r\n$7\r\nresults\r\n*1\r\n" +
+		"%2\r\n$2\r\nid\r\n$7\r\nshop:p1\r\n$6\r\nvalues\r\n*0\r\n" +
+		"$13\r\ntotal_results\r\n:1\r\n$7\r\nwarning\r\n*0\r\n"
+
+	aggregateResp2Fixture = "*3\r\n:2\r\n" +
+		"*4\r\n$8\r\ncategory\r\n$5\r\nbooks\r\n$5\r\ncount\r\n$1\r\n2\r\n" +
+		"*4\r\n$8\r\ncategory\r\n$5\r\ngames\r\n$5\r\ncount\r\n$1\r\n1\r\n"
+
+	aggregateResp3Fixture = "%5\r\n$10\r\nattributes\r\n*0\r\n$6\r\nformat\r\n+STRING\r\n$7\r\nresults\r\n*2\r\n" +
+		"%2\r\n$16\r\nextra_attributes\r\n%2\r\n$8\r\ncategory\r\n$5\r\nbooks\r\n$5\r\ncount\r\n$1\r\n2\r\n$6\r\nvalues\r\n*0\r\n" +
+		"%2\r\n$16\r\nextra_attributes\r\n%2\r\n$8\r\ncategory\r\n$5\r\ngames\r\n$5\r\ncount\r\n$1\r\n1\r\n$6\r\nvalues\r\n*0\r\n" +
+		"$13\r\ntotal_results\r\n:2\r\n$7\r\nwarning\r\n*0\r\n"
+
+	infoResp2Fixture = "*10\r\n$10\r\nindex_name\r\n$8\r\nshop:idx\r\n$13\r\nindex_options\r\n*0\r\n" +
+		"$16\r\nindex_definition\r\n*6\r\n$8\r\nkey_type\r\n$4\r\nHASH\r\n$8\r\nprefixes\r\n*1\r\n$13\r\nshop:product:\r\n$13\r\ndefault_score\r\n$1\r\n1\r\n" +
+		"$10\r\nattributes\r\n*2\r\n" +
+		"*10\r\n$10\r\nidentifier\r\n$4\r\nname\r\n$9\r\nattribute\r\n$4\r\nname\r\n$4\r\ntype\r\n$4\r\nTEXT\r\n$6\r\nWEIGHT\r\n$1\r\n2\r\n$8\r\nSORTABLE\r\n$6\r\nNOSTEM\r\n" +
+		"*14\r\n$10\r\nidentifier\r\n$9\r\nembedding\r\n$9\r\nattribute\r\n$9\r\nembedding\r\n$4\r\ntype\r\n$6\r\nVECTOR\r\n" +
+		"$9\r\nalgorithm\r\n$4\r\nFLAT\r\n$9\r\ndata_type\r\n$7\r\nFLOAT32\r\n$3\r\ndim\r\n:2\r\n$15\r\ndistance_metric\r\n$2\r\nL2\r\n" +
+		"$8\r\nnum_docs\r\n$1\r\n3\r\n"
+
+	infoResp3Fixture = "%5\r\n$10\r\nindex_name\r\n$8\r\nshop:idx\r\n$13\r\nindex_options\r\n*0\r\n" +
+		"$16\r\nindex_definition\r\n%3\r\n$8\r\nkey_type\r\n$4\r\nHASH\r\n$8\r\nprefixes\r\n*1\r\n$13\r\nshop:product:\r\n$13\r\ndefault_score\r\n,1\r\n" +
+		"$10\r\nattributes\r\n*2\r\n" +
+		"%5\r\n$10\r\nidentifier\r\n$4\r\nname\r\n$9\r\nattribute\r\n$4\r\nname\r\n$4\r\ntype\r\n$4\r\nTEXT\r\n$6\r\nWEIGHT\r\n,2\r\n" +
+		"$5\r\nflags\r\n*2\r\n$8\r\nSORTABLE\r\n$6\r\nNOSTEM\r\n" +
+		"%8\r\n$10\r\nidentifier\r\n$9\r\nembedding\r\n$9\r\nattribute\r\n$9\r\nembedding\r\n$4\r\ntype\r\n$6\r\nVECTOR\r\n" +
+		"$9\r\nalgorithm\r\n$4\r\nFLAT\r\n$9\r\ndata_type\r\n$7\r\nFLOAT32\r\n$3\r\ndim\r\n:2\r\n$15\r\ndistance_metric\r\n$2\r\nL2\r\n$5\r\nflags\r\n*0\r\n" +
+		"$8\r\nnum_docs\r\n:3\r\n"
+)
+
+func readFixture(t *testing.T, fixture string) interface{} {
+	t.Helper()
+
+	conn, _ := newFixtureConn(fixture, nil)
+
+	reply, err := conn.readReply()
+	if err != nil {
+		t.Fatalf("readReply = %v", err)
+	}
+
+	return reply
+}
+
+func TestSearchReplyShapes(t *testing.T) {
+	query := NewSearchQuery().WithScores()
+	want := SearchResult{Total: 2, Documents: []SearchDocument{
+		{Id: "shop:p1", Score: 1.5, Fields: map[string]string{"name": "lamp", "price": "20"}},
+		{Id: "shop:p2", Score: 0.5, Fields: map[string]string{"name": "desk", "price": "150"}},
+	}}
+
+	for name, fixture := range map[string]string{"resp2": searchResp2Fixture, "resp3": searchResp3Fixture} {
+		if result, err := replySearchResult(readFixture(t, fixture), nil, query); err != nil || !reflect.DeepEqual(result, want) {
+			t.Errorf("%s: replySearchResult = %+v, %v", name, result, err)
+		}
+	}
+
+	result, err := replySearchResult(readFixture(t, searchNoContentResp3Fixture), nil, NewSearchQuery().NoContent())
+	if err != nil || result.Total != 1 || len(result.Documents) != 1 || result.Documents[0].Id != "shop:p1" {
+		t.Errorf("resp3 NOCONTENT: replySearchResult = %+v, %v", result, err)
+	}
+}
+
+func TestAggregateReplyShapes(t *testing.T) {
+	want := []map[string]string{{"category": "books", "count": "2"}, {"category": "games", "count": "1"}}
+
+	for name, fixture := range map[string]string{"resp2": aggregateResp2Fixture, "resp3": aggregateResp3Fixture} {
+		if rows, err := replyAggregateRows(readFixture(t, fixture), nil); err != nil || !reflect.DeepEqual(rows, want) {
+			t.Errorf("%s: replyAggregateRows = %v, %v", name, rows, err)
+		}
+	}
+}
+
+func TestSearchIndexInfoReplyShapes(t *testing.T) {
+	want := SearchIndexInfo{
+		Name:     "shop:idx",
+		Prefixes: []string{"shop:product:"},
+		NumDocs:  3,
+		Fields: []SearchField{
+			{Name: "name", Type: REDIS_SEARCH_FIELD_TEXT, Weight: 2, Sortable: true, NoStem: true},
+			{Name: "embedding", Type: REDIS_SEARCH_FIELD_VECTOR, Algorithm: REDIS_SEARCH_VECTOR_FLAT, Dim: 2, Distance: REDIS_SEARCH_DISTANCE_L2},
+		},
+	}
+
+	for name, fixture := range map[string]string{"resp2": infoResp2Fixture, "resp3": infoResp3Fixture} {
+		if info, err := replySearchIndexInfo(readFixture(t, fixture), nil); err != nil || !reflect.DeepEqual(info, want) {
+			t.Errorf("%s: replySearchIndexInfo = %+v, %v", name, info, err)
+		}
+	}
+}
+
+func searchArgs(query *SearchQuery) []string {
+	args := make([]string, 0)
+	for _, arg := range query.args(redis_go.Args{}.Add("idx")) {
+		if value, isOk := arg.([]byte); isOk {
+			arg = string(value)
+		}
+		args = append(args, fmt.Sprint(arg))
+	}
+
+	return args
+}
+
+func TestSearchQueryKnnArgs(t *testing.T) {
+	query := NewSearchQuery().Tag("category", "books").
+		Knn("embedding", 3, []byte("old")).
+		Knn("embedding", 20, []byte("new"))
+
+	args := searchArgs(query)
+	want := []string{
+		"idx", "(@category:{books})=>[KNN 20 @embedding $knn_vector AS __embedding_score]",
+		"SORTBY", "__embedding_score", "ASC", "LIMIT", "0", "20",
+		"PARAMS", "2", "knn_vector", "new", "DIALECT", "2",
+	}
+
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+
+	if args = searchArgs(query.Limit(5, 5)); !reflect.DeepEqual(args[5:8], []string{"LIMIT", "5", "5"}) {
+		t.Errorf("explicit limit args = %q", args)
+	}
+}
@@ -0,0 +1,209 @@
+package gredis
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+/* ================================================================================
+ * Redis slow command log
+ * 以 log/slog 记录超过耗时阈值的命令，包含调用位置，参数值默认脱敏
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	slowLogDefaultPreviewArgs      int = 8
+	slowLogDefaultPreviewArgLength int = 32
+)
+
+var (
+	packagePrefix = reflect.TypeOf(redisClient{}).PkgPath() + "."
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 慢命令日志选项
+	 * Threshold: 耗时阈值（含重试及等待连接），小于等于0时记录所有命令，
+	 * 阻塞命令（BLPOP | BLMOVE | BZPOPMIN 等）的耗时主要是等待，始终不记录
+	 * Logger | Level: 日志记录器及级别，默认 slog.Default() 及 Warn
+	 * PreviewArgs: 预览的最大参数个数（不含Key）
+	 * ShowValues: 预览中显示参数值（按字符边界截断至不超过 PreviewArgLength 字节），默认以 ? 代替
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	SlowLogOption struct {
+		Threshold        time.Duration
+		Logger           *slog.Logger
+		Level            slog.Leveler
+		PreviewArgs      int
+		PreviewArgLength int
+		ShowValues       bool
+	}
+
+	slowLogHook struct {
+		EmptyHook
+		option SlowLogOption
+	}
+)
+
+func newSlowLogHook(option SlowLogOption) Hook {
+	if option.Logger == nil {
+		option.Logger = slog.Default()
+	}
+
+	if option.Level == nil {
+		option.Level = slog.LevelWarn
+	}
+
+	if option.PreviewArgs <= 0 {
+		option.PreviewArgs = slowLogDefaultPreviewArgs
+	}
+
+	if option.PreviewArgLength <= 0 {
+		option.PreviewArgLength = slowLogDefaultPreviewArgLength
+	}
+
+	return &slowLogHook{
+		option: option,
+	}
+}
+
+func (h *slowLogHook) AfterProcess(ctx context.Context, command *Command) error {
+	if command.isBlocking || command.Duration < h.option.Threshold || !h.option.Logger.Enabled(ctx, h.option.Level.Level()) {
+		return nil
+	}
+
+	attrs := h.attrs(command)
+	attrs = append(attrs,
+		slog.Duration("duration", command.Duration),
+		slog.Duration("pool_wait", command.PoolWait),
+		slog.Int("attempts", command.Attempts),
+		slog.String("caller", caller()))
+
+	if command.Err != nil {
+		attrs = append(attrs, slog.String("error", command.Err.Error()))
+	}
+
+	h.option.Logger.LogAttrs(ctx, h.option.Level.Level(), "gredis: slow command", attrs...)
+
+	return nil
+}
+
+func (h *slowLogHook) AfterProcessPipeline(ctx context.Context, commands []*Command) error {
+	if len(commands) == 0 || commands[0].Duration < h.option.Threshold || !h.option.Logger.Enabled(ctx, h.option.Level.Level()) {
+		return nil
+	}
+
+	names := make([]string, 0, len(commands))
+	for _, command := range commands {
+		names = append(names, strings.ToUpper(command.Name))
+	}
+
+	attrs := []slog.Attr{
+		slog.String("command", "PIPELINE"),
+		slog.String("commands", strings.Join(names, " ")),
+		slog.Duration("duration", commands[0].Duration),
+		slog.Duration("pool_wait", commands[0].PoolWait),
+		slog.String("caller", caller()),
+	}
+
+	for _, command := range commands {
+		if command.Err != nil {
+			attrs = append(attrs, slog.String("error", command.Err.Error()))
+			break
+		}
+	}
+
+	h.option.Logger.LogAttrs(ctx, h.option.Level.Level(), "gredis: slow command", attrs...)
+
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 命令名称、Key、参数个数、参数字节数及参数预览
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (h *slowLogHook) attrs(command *Command) []slog.Attr {
+	args := command.Args
+	if len(command.Key) > 0 && len(args) > 0 {
+		args = args[1:]
+	}
+
+	size := 0
+	for _, arg := range command.Args {
+		size += argSize(arg)
+	}
+
+	preview := make([]string, 0, h.option.PreviewArgs+1)
+	for index, arg := range args {
+		if index >= h.option.PreviewArgs {
+			preview = append(preview, fmt.Sprintf("...(+%d)", len(args)-index))
+			break
+		}
+
+		if !h.option.ShowValues {
+			preview = append(preview, "?")
+			continue
+		}
+
+		value := fmt.Sprint(arg)
+		if bytes, isOk := arg.([]byte); isOk {
+			value = string(bytes)
+		}
+
+		if len(value) > h.option.PreviewArgLength {
+			end := h.option.PreviewArgLength
+			for end > 0 && !utf8.RuneStart(value[end]) {
+				end--
+			}
+			value = value[:end] + "..."
+		}
+
+		preview = append(preview, value)
+	}
+
+	return []slog.Attr{
+		slog.String("command", strings.ToUpper(command.Name)),
+		slog.String("key", command.Key),
+		slog.Int("args", len(command.Args)),
+		slog.Int("bytes", size),
+		slog.String("preview", strings.Join(preview, " ")),
+	}
+}
+
+func argSize(arg interface{}) int {
+	switch value := arg.(type) {
+	case string:
+		return len(value)
+	case []byte:
+		return len(value)
+	case nil:
+		return 0
+	}
+
+	return len(fmt.Sprint(arg))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 调用位置：gredis 包外的首个调用方
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func caller() string {
+	pcs := make([]uintptr, 32)
+	count := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+
+	for {
+		frame, isMore := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+
+		if !isMore {
+			return ""
+		}
+	}
+}
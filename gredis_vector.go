@@ -0,0 +1,252 @@
+package gredis
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/* ================================================================================
+ * Vector store
+ * 基于 RediSearch 向量索引的相似度检索，向量以 FLOAT32 小端序二进制存储在哈希字段中
+ * 键（均在客户端前缀之下）：
+ * {name}:{id} 文档哈希（向量字段及元数据字段）| 索引名为 {name}
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	vectorStoreDefaultField string = "embedding"
+	vectorStoreScoreField   string = "__vector_score"
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 向量存储选项
+	 * Field: 向量所在的哈希字段，默认 embedding
+	 * Distance: 距离 REDIS_SEARCH_DISTANCE_*，默认 COSINE
+	 * Algorithm: 索引算法 REDIS_SEARCH_VECTOR_*，默认 FLAT
+	 * Fields: 同时索引的元数据字段，用于 Search 的过滤条件
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	VectorStoreOption struct {
+		Field     string
+		Distance  string
+		Algorithm string
+		Fields    []SearchField
+	}
+
+	VectorStore struct {
+		redis  IRedis
+		name   string
+		dim    int
+		option VectorStoreOption
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 相似度检索结果
+	 * Distance: 与查询向量的距离，越小越相似；COSINE 为 1 - 余弦相似度，
+	 * IP 为 1 - 内积，L2 为欧氏距离的平方
+	 * Fields: 元数据字段，不含向量字段
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	VectorMatch struct {
+		Id       string
+		Distance float64
+		Vector   []float32
+		Fields   map[string]string
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将向量编码为 FLOAT32 小端序二进制，与 RediSearch 向量字段的格式一致
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func EncodeVector(vector []float32) []byte {
+	data := make([]byte, 0, len(vector)*4)
+	for _, value := range vector {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(value))
+	}
+
+	return data
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将 FLOAT32 小端序二进制解码为向量，长度不是4的倍数时返回 ErrUnexpectedReply
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func DecodeVector(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("%w: vector blob of %d bytes", ErrUnexpectedReply, len(data))
+	}
+
+	vector := make([]float32, len(data)/4)
+	for index := range vector {
+		vector[index] = math.Float32frombits(binary.LittleEndian.Uint32(data[index*4:]))
+	}
+
+	return vector, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 初始化向量存储，dim 为向量维度
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewVectorStore(redis IRedis, name string, dim int, args ...VectorStoreOption) *VectorStore {
+	option := VectorStoreOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	if len(option.Field) == 0 {
+		option.Field = vectorStoreDefaultField
+	}
+
+	if len(option.Distance) == 0 {
+		option.Distance = REDIS_SEARCH_DISTANCE_COSINE
+	}
+
+	if len(option.Algorithm) == 0 {
+		option.Algorithm = REDIS_SEARCH_VECTOR_FLAT
+	}
+
+	return &VectorStore{
+		redis:  redis,
+		name:   name,
+		dim:    dim,
+		option: option,
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取文档哈希的Key（不含客户端前缀）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (v *VectorStore) Key(id string) string {
+	return v.name + ":" + id
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 创建向量索引，索引 {name}: 前缀下的全部哈希
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (v *VectorStore) CreateIndex() error {
+	fields := make([]SearchField, 0, len(v.option.Fields)+1)
+	fields = append(fields, SearchField{
+		Name:      v.option.Field,
+		Type:      REDIS_SEARCH_FIELD_VECTOR,
+		Algorithm: v.option.Algorithm,
+		Dim:       v.dim,
+		Distance:  v.option.Distance,
+	})
+	fields = append(fields, v.option.Fields...)
+
+	return v.redis.FTCreate(v.name, fields, SearchIndexOption{Prefixes: []string{v.name + ":"}})
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 删除向量索引，deleteArgs: 是否一并删除文档，默认保留
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (v *VectorStore) DropIndex(deleteArgs ...bool) error {
+	return v.redis.FTDropIndex(v.name, deleteArgs...)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 写入文档向量，fields 为元数据字段及值，如 "title", "hello", "category", "news"
+ * 向量维度与存储不一致时返回错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (v *VectorStore) Add(id string, vector []float32, fields ...interface{}) error {
+	if err := v.checkDim(vector); err != nil {
+		return err
+	}
+
+	args := make([]interface{}, 0, len(fields)+2)
+	args = append(append(args, v.option.Field, EncodeVector(vector)), fields...)
+
+	return v.redis.HMSet(v.Key(id), args...)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取文档向量，文档不存在时返回 ErrNotFound
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (v *VectorStore) Get(id string) ([]float32, error) {
+	data, err := v.redis.HGet(v.Key(id), v.option.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeVector([]byte(data))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 删除文档
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (v *VectorStore) Remove(ids ...string) error {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, v.Key(id))
+	}
+
+	return v.redis.Del(keys...)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 检索与 vector 最相似的 k 个文档，按距离升序
+ * filterArgs: 过滤条件，仅在满足条件的文档中检索，如
+ * gredis.NewSearchQuery().Tag("category", "news")
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (v *VectorStore) Search(vector []float32, k int, filterArgs ...*SearchQuery) ([]VectorMatch, error) {
+	if err := v.checkDim(vector); err != nil {
+		return nil, err
+	}
+
+	query := NewSearchQuery()
+	if len(filterArgs) > 0 && filterArgs[0] != nil {
+		// 复制过滤条件，避免 Knn 修改调用方的查询
+		filter := *filterArgs[0]
+		filter.params = append([]interface{}(nil), filter.params...)
+		query = &filter
+	}
+
+	query.Knn(v.option.Field, k, EncodeVector(vector), vectorStoreScoreField).Limit(0, k)
+
+	result, err := v.redis.FTSearch(v.name, query)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]VectorMatch, 0, len(result.Documents))
+	for _, document := range result.Documents {
+		match := VectorMatch{
+			Id:     strings.TrimPrefix(document.Id, v.name+":"),
+			Fields: document.Fields,
+		}
+
+		if match.Distance, err = strconv.ParseFloat(document.Fields[vectorStoreScoreField], 64); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnexpectedReply, err)
+		}
+
+		if data, isExists := document.Fields[v.option.Field]; isExists {
+			if match.Vector, err = DecodeVector([]byte(data)); err != nil {
+				return nil, err
+			}
+		}
+
+		delete(match.Fields, vectorStoreScoreField)
+		delete(match.Fields, v.option.Field)
+
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+func (v *VectorStore) checkDim(vector []float32) error {
+	if len(vector) != v.dim {
+		return fmt.Errorf("gredis: vector of dimension %d, want %d", len(vector), v.dim)
+	}
+
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将元数据字段扫描到结构体，字段映射规则与 HGetData 一致（redis 标签）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (m VectorMatch) Scan(structData interface{}) error {
+	return SearchDocument{Id: m.Id, Fields: m.Fields}.Scan(structData)
+}
@@ -0,0 +1,27 @@
+module github.com/sanxia/gredis/gredisotel
+
+go 1.22.0
+
+replace github.com/sanxia/gredis => ../
+
+require (
+	github.com/sanxia/gredis v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.34.0
+	go.opentelemetry.io/otel/sdk v1.34.0
+	go.opentelemetry.io/otel/trace v1.34.0
+)
+
+require (
+	github.com/bitly/go-simplejson v0.5.0 // indirect
+	github.com/boombuler/barcode v1.0.0 // indirect
+	github.com/garyburd/redigo v1.6.0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mozillazg/request v0.8.0 // indirect
+	github.com/sanxia/glib v1.0.1 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.34.0 // indirect
+	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e // indirect
+	golang.org/x/sys v0.29.0 // indirect
+)
@@ -0,0 +1,206 @@
+package gredisotel
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+)
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+import (
+	"github.com/sanxia/gredis"
+)
+
+/* ================================================================================
+ * Redis OpenTelemetry tracing
+ * 以钩子方式为每个命令、管道及拨号创建 span
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	instrumentationName string = "github.com/sanxia/gredis/gredisotel"
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 链路追踪选项
+	 * TracerProvider: 为nil时使用全局 TracerProvider
+	 * Attributes: 附加到每个 span 的属性
+	 * DisableStatement: 不记录 db.statement
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	TracingOption struct {
+		TracerProvider   trace.TracerProvider
+		Attributes       []attribute.KeyValue
+		DisableStatement bool
+	}
+
+	tracingHook struct {
+		gredis.EmptyHook
+		tracer trace.Tracer
+		option TracingOption
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 为Redis实例注册链路追踪钩子
+ * 需要传递上下文时使用 redis.WithContext(ctx) 调用命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func InstrumentTracing(redis gredis.IRedis, args ...TracingOption) {
+	redis.AddHook(NewTracingHook(args...))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取链路追踪钩子
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewTracingHook(args ...TracingOption) gredis.Hook {
+	option := TracingOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	provider := option.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &tracingHook{
+		tracer: provider.Tracer(instrumentationName),
+		option: option,
+	}
+}
+
+func (h *tracingHook) BeforeProcess(ctx context.Context, command *gredis.Command) (context.Context, error) {
+	attrs := h.attributes(command.Address, command.Db, command.Prefix)
+	if !h.option.DisableStatement {
+		attrs = append(attrs, attribute.String("db.statement", statement(command)))
+	}
+
+	ctx, _ = h.tracer.Start(ctx, strings.ToUpper(command.Name),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, nil
+}
+
+func (h *tracingHook) AfterProcess(ctx context.Context, command *gredis.Command) error {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("db.redis.attempts", command.Attempts))
+	end(span, command.Err)
+
+	return nil
+}
+
+func (h *tracingHook) BeforeProcessPipeline(ctx context.Context, commands []*gredis.Command) (context.Context, error) {
+	address, db, prefix := "", 0, ""
+	if len(commands) > 0 {
+		address, db, prefix = commands[0].Address, commands[0].Db, commands[0].Prefix
+	}
+
+	attrs := h.attributes(address, db, prefix)
+	attrs = append(attrs, attribute.Int("db.redis.num_cmd", len(commands)))
+
+	if !h.option.DisableStatement {
+		statements := make([]string, 0, len(commands))
+		for _, command := range commands {
+			statements = append(statements, statement(command))
+		}
+
+		attrs = append(attrs, attribute.String("db.statement", strings.Join(statements, "\n")))
+	}
+
+	ctx, _ = h.tracer.Start(ctx, "PIPELINE",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, nil
+}
+
+func (h *tracingHook) AfterProcessPipeline(ctx context.Context, commands []*gredis.Command) error {
+	var err error
+	for _, command := range commands {
+		if command.Err != nil {
+			err = command.Err
+			break
+		}
+	}
+
+	end(trace.SpanFromContext(ctx), err)
+
+	return nil
+}
+
+func (h *tracingHook) BeforeDial(ctx context.Context, dial *gredis.DialInfo) (context.Context, error) {
+	ctx, _ = h.tracer.Start(ctx, "DIAL",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(h.attributes(dial.Address, dial.Db, "")...))
+
+	return ctx, nil
+}
+
+func (h *tracingHook) AfterDial(ctx context.Context, dial *gredis.DialInfo) error {
+	end(trace.SpanFromContext(ctx), dial.Err)
+
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 公共属性
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (h *tracingHook) attributes(address string, db int, prefix string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(h.option.Attributes)+6)
+	attrs = append(attrs, h.option.Attributes...)
+	attrs = append(attrs,
+		attribute.String("db.system", "redis"),
+		attribute.Int("db.redis.database_index", db))
+
+	if host, port, err := net.SplitHostPort(address); err == nil {
+		attrs = append(attrs, attribute.String("net.peer.name", host))
+		if portValue, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, attribute.Int("net.peer.port", portValue))
+		}
+	}
+
+	if len(prefix) > 0 {
+		attrs = append(attrs, attribute.String("db.redis.key_prefix", prefix))
+	}
+
+	return attrs
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 脱敏的命令语句：保留命令名及首个Key，其余参数以 ? 代替
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func statement(command *gredis.Command) string {
+	var builder strings.Builder
+	builder.WriteString(strings.ToUpper(command.Name))
+
+	args := command.Args
+	if len(command.Key) > 0 && len(args) > 0 {
+		builder.WriteByte(' ')
+		builder.WriteString(command.Key)
+		args = args[1:]
+	}
+
+	for range args {
+		builder.WriteString(" ?")
+	}
+
+	return builder.String()
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
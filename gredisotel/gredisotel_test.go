@@ -0,0 +1,123 @@
+package gredisotel
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+import (
+	"github.com/sanxia/gredis"
+)
+
+func newTracingHook() (gredis.Hook, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	return NewTracingHook(TracingOption{TracerProvider: provider}), exporter
+}
+
+func attributeMap(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+
+	return attrs
+}
+
+func TestProcessSpan(t *testing.T) {
+	hook, exporter := newTracingHook()
+
+	command := &gredis.Command{
+		Name:    "SET",
+		Args:    []interface{}{"app:user:1", "secret", "EX", 10},
+		Key:     "app:user:1",
+		Prefix:  "app",
+		Address: "10.0.0.1:6380",
+		Db:      2,
+	}
+
+	ctx, err := hook.BeforeProcess(context.Background(), command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	command.Attempts = 1
+	if err := hook.AfterProcess(ctx, command); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+
+	if spans[0].Name != "SET" {
+		t.Errorf("name = %q", spans[0].Name)
+	}
+
+	attrs := attributeMap(spans[0])
+	expected := map[attribute.Key]attribute.Value{
+		"db.system":               attribute.StringValue("redis"),
+		"db.statement":            attribute.StringValue("SET app:user:1 ? ? ?"),
+		"db.redis.database_index": attribute.IntValue(2),
+		"db.redis.key_prefix":     attribute.StringValue("app"),
+		"net.peer.name":           attribute.StringValue("10.0.0.1"),
+		"net.peer.port":           attribute.IntValue(6380),
+	}
+
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("%s = %v, want %v", key, attrs[key].Emit(), value.Emit())
+		}
+	}
+}
+
+func TestErrorStatusAndContext(t *testing.T) {
+	hook, exporter := newTracingHook()
+
+	provider := sdktrace.NewTracerProvider()
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	redis := gredis.NewRedisWithOption(gredis.RedisOption{
+		Ip:    "127.0.0.1",
+		Port:  1,
+		Hooks: []gredis.Hook{hook},
+	})
+	defer redis.Close()
+
+	if _, err := redis.WithContext(ctx).Do("GET", "key"); err == nil {
+		t.Fatal("expected dial error")
+	}
+
+	parent.End()
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+
+	command, isOk := spans["GET"]
+	if !isOk {
+		t.Fatal("missing GET span")
+	}
+
+	if command.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("GET span is not a child of the context span")
+	}
+
+	if command.Status.Code != codes.Error || len(command.Events) == 0 {
+		t.Errorf("GET status = %v, want error", command.Status.Code)
+	}
+
+	if dial, isOk := spans["DIAL"]; !isOk || dial.Status.Code != codes.Error {
+		t.Error("missing failed DIAL span")
+	}
+}
@@ -0,0 +1,286 @@
+package gredisprom
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+import (
+	"github.com/sanxia/gredis"
+)
+
+/* ================================================================================
+ * Redis Prometheus metrics
+ * 命令耗时、错误、超时、拨号失败及连接池指标，按连接名称（client）及数据库（db）区分
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	commandPipeline string = "PIPELINE"
+	commandDial     string = "DIAL"
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 指标选项
+	 * Namespace: 指标名称前缀，默认 gredis
+	 * Buckets: 命令耗时直方图的桶，默认 prometheus.DefBuckets
+	 * ConstLabels: 附加到所有指标的固定标签
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	CollectorOption struct {
+		Namespace   string
+		Buckets     []float64
+		ConstLabels prometheus.Labels
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 指标收集器，实现 prometheus.Collector
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	Collector struct {
+		commandDuration *prometheus.HistogramVec
+		commandErrors   *prometheus.CounterVec
+		timeouts        *prometheus.CounterVec
+		dialFailures    *prometheus.CounterVec
+
+		poolActive       *prometheus.Desc
+		poolIdle         *prometheus.Desc
+		poolWaiting      *prometheus.Desc
+		poolGets         *prometheus.Desc
+		poolWaitDuration *prometheus.Desc
+
+		mutex   sync.RWMutex
+		clients []gredis.IRedis
+	}
+
+	metricsHook struct {
+		gredis.EmptyHook
+		collector *Collector
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取指标收集器
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewCollector(args ...CollectorOption) *Collector {
+	option := CollectorOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	if len(option.Namespace) == 0 {
+		option.Namespace = "gredis"
+	}
+
+	if len(option.Buckets) == 0 {
+		option.Buckets = prometheus.DefBuckets
+	}
+
+	labels := []string{"client", "db"}
+	commandLabels := []string{"client", "db", "command"}
+
+	poolDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(option.Namespace, "pool", name), help, labels, option.ConstLabels)
+	}
+
+	return &Collector{
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   option.Namespace,
+			Name:        "command_duration_seconds",
+			Help:        "Redis command latency in seconds, including retries.",
+			Buckets:     option.Buckets,
+			ConstLabels: option.ConstLabels,
+		}, commandLabels),
+		commandErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   option.Namespace,
+			Name:        "command_errors_total",
+			Help:        "Redis command errors by error type.",
+			ConstLabels: option.ConstLabels,
+		}, append(commandLabels, "type")),
+		timeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   option.Namespace,
+			Name:        "timeouts_total",
+			Help:        "Redis command and dial timeouts.",
+			ConstLabels: option.ConstLabels,
+		}, commandLabels),
+		dialFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   option.Namespace,
+			Name:        "dial_failures_total",
+			Help:        "Redis connection dial failures.",
+			ConstLabels: option.ConstLabels,
+		}, labels),
+
+		poolActive:       poolDesc("active_connections", "Connections in the pool, idle and in use."),
+		poolIdle:         poolDesc("idle_connections", "Idle connections in the pool."),
+		poolWaiting:      poolDesc("waiting", "Callers currently waiting for a pool connection."),
+		poolGets:         poolDesc("gets_total", "Connections taken from the pool."),
+		poolWaitDuration: poolDesc("wait_duration_seconds_total", "Total time spent waiting for pool connections."),
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 为Redis实例注册指标钩子并收集其连接池指标
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *Collector) Instrument(redis gredis.IRedis) {
+	c.mutex.Lock()
+	c.clients = append(c.clients, redis)
+	c.mutex.Unlock()
+
+	redis.AddHook(&metricsHook{collector: c})
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.commandDuration.Describe(ch)
+	c.commandErrors.Describe(ch)
+	c.timeouts.Describe(ch)
+	c.dialFailures.Describe(ch)
+
+	ch <- c.poolActive
+	ch <- c.poolIdle
+	ch <- c.poolWaiting
+	ch <- c.poolGets
+	ch <- c.poolWaitDuration
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.commandDuration.Collect(ch)
+	c.commandErrors.Collect(ch)
+	c.timeouts.Collect(ch)
+	c.dialFailures.Collect(ch)
+
+	c.mutex.RLock()
+	clients := c.clients
+	c.mutex.RUnlock()
+
+	// 同名且同库的多个实例共用一组标签，连接池统计按标签合计后输出，避免重复的序列
+	keys := make([][2]string, 0, len(clients))
+	totals := make(map[[2]string]*gredis.PoolStats)
+
+	for _, redis := range clients {
+		option := redis.Option()
+		stats := redis.PoolStats()
+		key := [2]string{option.ClientName, strconv.Itoa(option.Db)}
+
+		total, isExists := totals[key]
+		if !isExists {
+			total = &gredis.PoolStats{}
+			totals[key] = total
+			keys = append(keys, key)
+		}
+
+		total.ActiveCount += stats.ActiveCount
+		total.IdleCount += stats.IdleCount
+		total.WaitingCount += stats.WaitingCount
+		total.GetCount += stats.GetCount
+		total.WaitDuration += stats.WaitDuration
+	}
+
+	for _, key := range keys {
+		stats, labels := totals[key], key[:]
+
+		ch <- prometheus.MustNewConstMetric(c.poolActive, prometheus.GaugeValue, float64(stats.ActiveCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.poolIdle, prometheus.GaugeValue, float64(stats.IdleCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.poolWaiting, prometheus.GaugeValue, float64(stats.WaitingCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.poolGets, prometheus.CounterValue, float64(stats.GetCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.poolWaitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), labels...)
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 记录命令耗时及错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *Collector) observe(command *gredis.Command, commandName string) {
+	client, db := command.ClientName, strconv.Itoa(command.Db)
+
+	c.commandDuration.WithLabelValues(client, db, commandName).Observe(command.Duration.Seconds())
+
+	if command.Err == nil {
+		return
+	}
+
+	errorType := ErrorType(command.Err)
+	c.commandErrors.WithLabelValues(client, db, commandName, errorType).Inc()
+
+	if errorType == "timeout" {
+		c.timeouts.WithLabelValues(client, db, commandName).Inc()
+	}
+}
+
+func (h *metricsHook) AfterProcess(ctx context.Context, command *gredis.Command) error {
+	h.collector.observe(command, strings.ToUpper(command.Name))
+
+	return nil
+}
+
+func (h *metricsHook) AfterProcessPipeline(ctx context.Context, commands []*gredis.Command) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	pipeline := *commands[0]
+	pipeline.Err = nil
+	for _, command := range commands {
+		if command.Err != nil {
+			pipeline.Err = command.Err
+			break
+		}
+	}
+
+	h.collector.observe(&pipeline, commandPipeline)
+
+	return nil
+}
+
+func (h *metricsHook) AfterDial(ctx context.Context, dial *gredis.DialInfo) error {
+	if dial.Err == nil {
+		return nil
+	}
+
+	client, db := dial.ClientName, strconv.Itoa(dial.Db)
+	h.collector.dialFailures.WithLabelValues(client, db).Inc()
+
+	if ErrorType(dial.Err) == "timeout" {
+		h.collector.timeouts.WithLabelValues(client, db, commandDial).Inc()
+	}
+
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 错误类型：timeout | network | circuit_open | pool_exhausted | 服务器错误前缀（如 ERR、WRONGTYPE）| server | other
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func ErrorType(err error) string {
+	var netError net.Error
+	if errors.As(err, &netError) {
+		if netError.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+
+	if errors.Is(err, gredis.ErrCircuitOpen) {
+		return "circuit_open"
+	}
+
+	if errors.Is(err, gredis.ErrPoolExhausted) {
+		return "pool_exhausted"
+	}
+
+	var redisError *gredis.RedisError
+	if errors.As(err, &redisError) && len(redisError.Prefix) > 0 {
+		if strings.Trim(redisError.Prefix, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+			return redisError.Prefix
+		}
+		return "server"
+	}
+
+	return "other"
+}
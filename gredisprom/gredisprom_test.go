@@ -0,0 +1,98 @@
+package gredisprom
+
+import (
+	"strings"
+	"testing"
+)
+
+import (
+	redis_go "github.com/garyburd/redigo/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+import (
+	"github.com/sanxia/gredis"
+)
+
+func TestCollector(t *testing.T) {
+	redis := gredis.NewRedisWithOption(gredis.RedisOption{
+		Ip:         "127.0.0.1",
+		Port:       1,
+		ClientName: "orders",
+		Db:         3,
+	})
+	defer redis.Close()
+
+	collector := NewCollector()
+	collector.Instrument(redis)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	if _, err := redis.Do("GET", "key"); err == nil {
+		t.Fatal("expected dial error")
+	}
+
+	if value := testutil.ToFloat64(collector.dialFailures.WithLabelValues("orders", "3")); value != 1 {
+		t.Errorf("dial failures = %v, want 1", value)
+	}
+
+	if value := testutil.ToFloat64(collector.commandErrors.WithLabelValues("orders", "3", "GET", "network")); value != 1 {
+		t.Errorf("GET network errors = %v, want 1", value)
+	}
+
+	expected := `
+# HELP gredis_pool_gets_total Connections taken from the pool.
+# TYPE gredis_pool_gets_total counter
+gredis_pool_gets_total{client="orders",db="3"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "gredis_pool_gets_total"); err != nil {
+		t.Error(err)
+	}
+
+	if count, err := testutil.GatherAndCount(registry, "gredis_command_duration_seconds"); err != nil || count != 1 {
+		t.Errorf("command duration series = %d, %v", count, err)
+	}
+}
+
+func TestCollectorSharedLabels(t *testing.T) {
+	collector := NewCollector()
+
+	// 两个默认实例的 client 及 db 标签相同，连接池指标合计为一条序列
+	for index := 0; index < 2; index++ {
+		redis := gredis.NewRedisWithOption(gredis.RedisOption{Ip: "127.0.0.1", Port: 1})
+		defer redis.Close()
+
+		collector.Instrument(redis)
+		redis.Do("GET", "key")
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	expected := `
+# HELP gredis_pool_gets_total Connections taken from the pool.
+# TYPE gredis_pool_gets_total counter
+gredis_pool_gets_total{client="",db="0"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "gredis_pool_gets_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	cases := map[string]error{
+		"WRONGTYPE":      &gredis.RedisError{Prefix: "WRONGTYPE", Err: redis_go.Error("WRONGTYPE Operation")},
+		"server":         &gredis.RedisError{Prefix: "user_script:1:", Err: redis_go.Error("user_script:1: oops")},
+		"circuit_open":   &gredis.RedisError{Err: gredis.ErrCircuitOpen},
+		"pool_exhausted": &gredis.RedisError{Err: redis_go.ErrPoolExhausted},
+		"other":          redis_go.ErrNil,
+	}
+
+	for expected, err := range cases {
+		if errorType := ErrorType(err); errorType != expected {
+			t.Errorf("ErrorType(%v) = %s, want %s", err, errorType, expected)
+		}
+	}
+}
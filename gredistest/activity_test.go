@@ -0,0 +1,61 @@
+package gredistest_test
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+func TestActivity(t *testing.T) {
+	redis := newFakeRedis(t, gredistest.NewFake(), gredis.RedisOption{})
+
+	location := time.FixedZone("UTC+8", 8*3600)
+	activity := gredis.NewActivity(redis, "dau", gredis.ActivityOption{Retention: 7, Location: location})
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	today := time.Now()
+
+	for _, id := range []int{1, 5, 9} {
+		activity.Mark(id, yesterday)
+	}
+
+	for _, id := range []int{5, 9, 12} {
+		activity.Mark(id, today)
+	}
+
+	if isActive, err := activity.Mark(5, today); err != nil || !isActive {
+		t.Errorf("second Mark = %v, %v, want true", isActive, err)
+	}
+
+	if key := activity.Key(time.Date(2024, 6, 3, 20, 0, 0, 0, time.UTC)); key != "dau:20240604" {
+		t.Errorf("Key = %q, want dau:20240604", key)
+	}
+
+	if isActive, _ := activity.IsActive(12, yesterday); isActive {
+		t.Error("IsActive(12, yesterday) = true")
+	}
+
+	if count, err := activity.Count(yesterday); err != nil || count != 3 {
+		t.Errorf("Count = %d, %v, want 3", count, err)
+	}
+
+	if count, err := activity.CountAny(yesterday, today); err != nil || count != 4 {
+		t.Errorf("CountAny = %d, %v, want 4", count, err)
+	}
+
+	if count, err := activity.CountAll(yesterday, today); err != nil || count != 2 {
+		t.Errorf("CountAll = %d, %v, want 2", count, err)
+	}
+
+	if isExists, _ := redis.Exists("dau:tmp"); isExists {
+		t.Error("temporary bitmap was not deleted")
+	}
+
+	if ttl, _ := redis.Ttl(activity.Key(yesterday)); ttl <= 0 {
+		t.Errorf("Ttl = %d, want positive", ttl)
+	}
+}
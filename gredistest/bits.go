@@ -0,0 +1,468 @@
+package gredistest
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+/* ================================================================================
+ * Bitmap commands
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	bitMaxOffset int64 = 1<<32 - 1
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * BITFIELD 字段类型，i1..i64 | u1..u63
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	bitFieldType struct {
+		isSigned bool
+		bits     int64
+	}
+)
+
+func init() {
+	register("SETBIT", 4, setBit)
+	register("GETBIT", 3, getBit)
+	register("BITCOUNT", -2, bitCount)
+	register("BITPOS", -3, bitPos)
+	register("BITOP", -4, bitOp)
+	register("BITFIELD", -2, bitField(false))
+	register("BITFIELD_RO", -2, bitField(true))
+}
+
+func parseBitOffset(arg []byte) int64 {
+	offset, err := strconv.ParseInt(string(arg), 10, 64)
+	if err != nil || offset < 0 || offset > bitMaxOffset {
+		panic(errBitOffset)
+	}
+
+	return offset
+}
+
+func bitAt(data []byte, offset int64) int64 {
+	index := offset / 8
+	if index >= int64(len(data)) {
+		return 0
+	}
+
+	return int64(data[index]>>(7-uint(offset%8))) & 1
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按需扩展字符串并返回可写副本
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func growBits(value []byte, bitLength int64) []byte {
+	size := max(int64(len(value)), (bitLength+7)/8)
+
+	data := make([]byte, size)
+	copy(data, value)
+
+	return data
+}
+
+func (c *commandContext) storeBits(key string, data []byte, isExists bool) {
+	if isExists {
+		c.put(key, data)
+	} else {
+		c.set(key, data)
+	}
+}
+
+func setBit(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	offset := parseBitOffset(args[1])
+
+	bit := string(args[2])
+	if bit != "0" && bit != "1" {
+		panic(errBitValue)
+	}
+
+	value, isExists := c.stringValue(key)
+	previous := bitAt(value, offset)
+
+	data := growBits(value, offset+1)
+	mask := byte(1) << (7 - uint(offset%8))
+	if bit == "1" {
+		data[offset/8] |= mask
+	} else {
+		data[offset/8] &^= mask
+	}
+
+	c.storeBits(key, data, isExists)
+
+	return previous
+}
+
+func getBit(c *commandContext, args [][]byte) interface{} {
+	offset := parseBitOffset(args[1])
+	value, _ := c.stringValue(string(args[0]))
+
+	return bitAt(value, offset)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析 [start [end [BYTE | BIT]]]，返回以位为单位的闭区间
+ * isEndRequired 为 false 时允许省略 end（BITPOS），isEndGiven 标识是否指定了 end
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func parseBitRange(args [][]byte, length int, isEndRequired bool) (start, stop int64, isEndGiven, isOk bool) {
+	if len(args) == 0 {
+		return 0, int64(length)*8 - 1, false, length > 0
+	}
+
+	if len(args) > 3 || (isEndRequired && len(args) == 1) {
+		panic(errSyntax)
+	}
+
+	isBit := false
+	if len(args) == 3 {
+		switch {
+		case isOption(args[2], "BIT"):
+			isBit = true
+		case isOption(args[2], "BYTE"):
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	first, last := parseInt(args[0]), int64(-1)
+	if isEndGiven = len(args) >= 2; isEndGiven {
+		last = parseInt(args[1])
+	}
+
+	size := length
+	if isBit {
+		size = length * 8
+	}
+
+	begin, end, isOk := normalizeRange(first, last, size)
+	if !isOk {
+		return 0, 0, isEndGiven, false
+	}
+
+	if isBit {
+		return int64(begin), int64(end), isEndGiven, true
+	}
+
+	return int64(begin) * 8, int64(end)*8 + 7, isEndGiven, true
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BITCOUNT key [start end [BYTE | BIT]]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func bitCount(c *commandContext, args [][]byte) interface{} {
+	value, _ := c.stringValue(string(args[0]))
+
+	start, stop, _, isOk := parseBitRange(args[1:], len(value), true)
+	if !isOk {
+		return int64(0)
+	}
+
+	count := int64(0)
+	for offset := start; offset <= stop; offset++ {
+		count += bitAt(value, offset)
+	}
+
+	return count
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BITPOS key bit [start [end [BYTE | BIT]]]
+ * 查找0且未指定 end 时，区间内全为1则返回区间之后的第一位
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func bitPos(c *commandContext, args [][]byte) interface{} {
+	bit := string(args[1])
+	if bit != "0" && bit != "1" {
+		panic(errBitValue)
+	}
+	target := int64(bit[0] - '0')
+
+	value, isExists := c.stringValue(string(args[0]))
+	if !isExists {
+		if target == 1 {
+			return int64(-1)
+		}
+		return int64(0)
+	}
+
+	start, stop, isEndGiven, isOk := parseBitRange(args[2:], len(value), false)
+	if !isOk {
+		return int64(-1)
+	}
+
+	for offset := start; offset <= stop; offset++ {
+		if bitAt(value, offset) == target {
+			return offset
+		}
+	}
+
+	if target == 0 && !isEndGiven {
+		return stop + 1
+	}
+
+	return int64(-1)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BITOP AND | OR | XOR | NOT destkey key [key ...]
+ * 较短的字符串按0补齐，结果为空时删除目标键
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func bitOp(c *commandContext, args [][]byte) interface{} {
+	operation := strings.ToUpper(string(args[0]))
+	destKey := string(args[1])
+	keys := args[2:]
+
+	switch operation {
+	case "AND", "OR", "XOR":
+	case "NOT":
+		if len(keys) != 1 {
+			panic(errorReply("ERR BITOP NOT must be called with a single source key."))
+		}
+	default:
+		panic(errSyntax)
+	}
+
+	values := make([][]byte, 0, len(keys))
+	size := 0
+	for _, key := range keys {
+		value, _ := c.stringValue(string(key))
+		values = append(values, value)
+		size = max(size, len(value))
+	}
+
+	result := make([]byte, size)
+	for index := range result {
+		byteAt := func(value []byte) byte {
+			if index < len(value) {
+				return value[index]
+			}
+			return 0
+		}
+
+		current := byteAt(values[0])
+		for _, value := range values[1:] {
+			switch operation {
+			case "AND":
+				current &= byteAt(value)
+			case "OR":
+				current |= byteAt(value)
+			case "XOR":
+				current ^= byteAt(value)
+			}
+		}
+
+		if operation == "NOT" {
+			current = ^current
+		}
+
+		result[index] = current
+	}
+
+	if size == 0 {
+		c.remove(destKey)
+	} else {
+		c.set(destKey, result)
+	}
+
+	return int64(size)
+}
+
+func parseBitFieldType(arg []byte) bitFieldType {
+	text := strings.ToLower(string(arg))
+	if len(text) < 2 || (text[0] != 'i' && text[0] != 'u') {
+		panic(errBitType)
+	}
+
+	bits, err := strconv.ParseInt(text[1:], 10, 64)
+	isSigned := text[0] == 'i'
+	if err != nil || bits < 1 || (isSigned && bits > 64) || (!isSigned && bits > 63) {
+		panic(errBitType)
+	}
+
+	return bitFieldType{isSigned: isSigned, bits: bits}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析字段偏移，#N 表示第 N 个该类型的字段
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (t bitFieldType) offset(arg []byte) int64 {
+	isPositional := len(arg) > 0 && arg[0] == '#'
+	if isPositional {
+		arg = arg[1:]
+	}
+
+	offset, err := strconv.ParseInt(string(arg), 10, 64)
+	if err != nil || offset < 0 {
+		panic(errBitOffset)
+	}
+
+	if isPositional {
+		offset *= t.bits
+	}
+
+	if offset+t.bits-1 > bitMaxOffset {
+		panic(errBitOffset)
+	}
+
+	return offset
+}
+
+func (t bitFieldType) bounds() (*big.Int, *big.Int) {
+	if t.isSigned {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(t.bits-1))
+		return new(big.Int).Neg(limit), limit.Sub(limit, big.NewInt(1))
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.bits))
+	return big.NewInt(0), limit.Sub(limit, big.NewInt(1))
+}
+
+func (t bitFieldType) read(data []byte, offset int64) int64 {
+	raw := uint64(0)
+	for index := int64(0); index < t.bits; index++ {
+		raw = raw<<1 | uint64(bitAt(data, offset+index))
+	}
+
+	if t.isSigned && t.bits < 64 && raw&(1<<uint(t.bits-1)) != 0 {
+		raw |= ^uint64(0) << uint(t.bits)
+	}
+
+	return int64(raw)
+}
+
+func (t bitFieldType) write(data []byte, offset, value int64) {
+	raw := uint64(value)
+	for index := t.bits - 1; index >= 0; index-- {
+		position := offset + index
+		mask := byte(1) << (7 - uint(position%8))
+		if raw&1 == 1 {
+			data[position/8] |= mask
+		} else {
+			data[position/8] &^= mask
+		}
+		raw >>= 1
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按溢出策略处理结果，FAIL 且溢出时返回 false
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (t bitFieldType) overflow(value *big.Int, mode string) (int64, bool) {
+	lower, upper := t.bounds()
+	if value.Cmp(lower) >= 0 && value.Cmp(upper) <= 0 {
+		return value.Int64(), true
+	}
+
+	switch mode {
+	case "SAT":
+		if value.Cmp(lower) < 0 {
+			return lower.Int64(), true
+		}
+		return upper.Int64(), true
+	case "FAIL":
+		return 0, false
+	}
+
+	span := new(big.Int).Lsh(big.NewInt(1), uint(t.bits))
+	wrapped := new(big.Int).Sub(value, lower)
+	wrapped.Mod(wrapped, span).Add(wrapped, lower)
+
+	return wrapped.Int64(), true
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BITFIELD key [GET type offset] [SET type offset value] [INCRBY type offset increment]
+ * [OVERFLOW WRAP | SAT | FAIL]
+ * BITFIELD_RO 仅支持 GET
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func bitField(isReadOnly bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		key := string(args[0])
+		value, isExists := c.stringValue(key)
+
+		data := value
+		isModified := false
+		mode := "WRAP"
+
+		replies := make([]interface{}, 0)
+		for index := 1; index < len(args); {
+			operation := strings.ToUpper(string(args[index]))
+
+			if operation == "OVERFLOW" && !isReadOnly {
+				if index+1 >= len(args) {
+					panic(errSyntax)
+				}
+
+				mode = strings.ToUpper(string(args[index+1]))
+				if mode != "WRAP" && mode != "SAT" && mode != "FAIL" {
+					panic(errBitOverflow)
+				}
+
+				index += 2
+				continue
+			}
+
+			width := 3
+			switch {
+			case operation == "GET":
+			case (operation == "SET" || operation == "INCRBY") && !isReadOnly:
+				width = 4
+			case isReadOnly:
+				panic(errorReply("ERR BITFIELD_RO only supports the GET subcommand"))
+			default:
+				panic(errSyntax)
+			}
+
+			if index+width > len(args) {
+				panic(errSyntax)
+			}
+
+			fieldType := parseBitFieldType(args[index+1])
+			offset := fieldType.offset(args[index+2])
+			current := fieldType.read(data, offset)
+
+			if operation == "GET" {
+				replies = append(replies, current)
+				index += width
+				continue
+			}
+
+			amount := parseInt(args[index+3])
+			index += width
+
+			target := big.NewInt(amount)
+			if operation == "INCRBY" {
+				target.Add(target, big.NewInt(current))
+			}
+
+			result, isOk := fieldType.overflow(target, mode)
+			if !isOk {
+				replies = append(replies, nil)
+				continue
+			}
+
+			if !isModified || int64(len(data))*8 < offset+fieldType.bits {
+				data, isModified = growBits(data, offset+fieldType.bits), true
+			}
+
+			fieldType.write(data, offset, result)
+
+			if operation == "SET" {
+				replies = append(replies, current)
+			} else {
+				replies = append(replies, result)
+			}
+		}
+
+		if isModified {
+			c.storeBits(key, data, isExists)
+		}
+
+		return replies
+	}
+}
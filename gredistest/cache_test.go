@@ -0,0 +1,169 @@
+package gredistest_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+func newCachedRedis(t *testing.T, fake *gredistest.Fake, option gredis.CacheOption, protocolArgs ...int) gredis.IRedis {
+	protocol := gredis.REDIS_PROTOCOL_RESP2
+	if len(protocolArgs) > 0 {
+		protocol = protocolArgs[0]
+	}
+
+	redis := newFakeRedis(t, fake, gredis.RedisOption{Protocol: protocol, Cache: &option})
+
+	waitFor(t, "cache tracking", func() bool { return redis.CacheStats().Tracking })
+
+	return redis
+}
+
+func waitFor(t *testing.T, name string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", name)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	// RESP2 通过 REDIRECT 到订阅连接接收通知，RESP3 通过同一连接的推送消息接收
+	for _, protocol := range []int{gredis.REDIS_PROTOCOL_RESP2, gredis.REDIS_PROTOCOL_RESP3} {
+		t.Run(fmt.Sprintf("resp%d", protocol), func(t *testing.T) {
+			testCacheInvalidation(t, protocol)
+		})
+	}
+}
+
+func testCacheInvalidation(t *testing.T, protocol int) {
+	fake := gredistest.NewFake()
+	cached := newCachedRedis(t, fake, gredis.CacheOption{}, protocol)
+	writer := newFakeRedis(t, fake, gredis.RedisOption{})
+
+	writer.Set("user", "v1")
+	waitFor(t, "initial invalidation", func() bool { return cached.CacheStats().Invalidations == 1 })
+
+	for index := 0; index < 2; index++ {
+		if value, err := cached.Get("user"); err != nil || string(value) != "v1" {
+			t.Fatalf("Get = %q, %v", value, err)
+		}
+	}
+
+	if stats := cached.CacheStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Fatalf("CacheStats = %+v", stats)
+	}
+
+	writer.Set("user", "v2")
+	waitFor(t, "invalidation", func() bool { return cached.CacheStats().Entries == 0 })
+
+	if value, err := cached.Get("user"); err != nil || string(value) != "v2" {
+		t.Errorf("Get after write from other client = %q, %v", value, err)
+	}
+
+	writer.Set("other:user", "v1")
+	writer.HSet("profile", "name", "alice")
+	cached.HGet("profile", "name")
+
+	writer.HSet("profile", "name", "bob")
+	waitFor(t, "hash invalidation", func() bool { return cached.CacheStats().Entries == 1 })
+
+	if value, err := cached.HGet("profile", "name"); err != nil || value != "bob" {
+		t.Errorf("HGet after write from other client = %q, %v", value, err)
+	}
+}
+
+func TestCacheBudget(t *testing.T) {
+	fake := gredistest.NewFake()
+	writer := newFakeRedis(t, fake, gredis.RedisOption{})
+
+	for _, key := range []string{"k1", "k2", "k3"} {
+		writer.Set(key, "vv")
+	}
+
+	// 每个条目 len("test:k1") + len("vv") + 96 = 105 字节，预算仅容纳两个条目
+	cached := newCachedRedis(t, fake, gredis.CacheOption{Policy: gredis.CACHE_POLICY_LRU, MaxBytes: 220})
+
+	cached.Get("k1")
+	cached.Get("k2")
+	cached.Get("k1")
+	cached.Get("k3")
+
+	stats := cached.CacheStats()
+	if stats.Evictions != 1 || stats.Entries != 2 || stats.Bytes != 210 {
+		t.Fatalf("CacheStats = %+v", stats)
+	}
+
+	cached.Get("k1")
+	if hits := cached.CacheStats().Hits; hits != stats.Hits+1 {
+		t.Errorf("Get of recently used key hits = %d, want %d", hits, stats.Hits+1)
+	}
+
+	cached.Get("k2")
+	if misses := cached.CacheStats().Misses; misses != stats.Misses+1 {
+		t.Errorf("Get of evicted key misses = %d, want %d", misses, stats.Misses+1)
+	}
+}
+
+func TestCacheTtl(t *testing.T) {
+	fake := gredistest.NewFake()
+	cached := newCachedRedis(t, fake, gredis.CacheOption{
+		Ttl: time.Hour,
+		KeyTtl: func(key string) time.Duration {
+			if key == "short" {
+				return 20 * time.Millisecond
+			}
+			return 0
+		},
+	})
+
+	cached.Set("short", "1")
+	cached.Set("long", "1")
+	waitFor(t, "own write invalidation", func() bool { return cached.CacheStats().Invalidations >= 2 })
+
+	cached.Get("short")
+	cached.Get("long")
+	time.Sleep(40 * time.Millisecond)
+	cached.Get("short")
+	cached.Get("long")
+
+	if stats := cached.CacheStats(); stats.Expirations != 1 || stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("CacheStats = %+v", stats)
+	}
+}
+
+func TestCacheInvalidationBeforeFill(t *testing.T) {
+	fake := gredistest.NewFake()
+	cached := newCachedRedis(t, fake, gredis.CacheOption{})
+	writer := newFakeRedis(t, fake, gredis.RedisOption{})
+
+	writer.Set("user", "v1")
+	waitFor(t, "initial invalidation", func() bool { return cached.CacheStats().Invalidations == 1 })
+
+	// 服务器已回复旧值，失效通知在回填之前到达
+	cached.AddHook(&afterCommandHook{command: "GET", action: func() {
+		writer.Set("user", "v2")
+		waitFor(t, "invalidation before fill", func() bool { return cached.CacheStats().Invalidations == 2 })
+	}})
+
+	if value, err := cached.Get("user"); err != nil || string(value) != "v1" {
+		t.Fatalf("Get = %q, %v", value, err)
+	}
+
+	if stats := cached.CacheStats(); stats.Entries != 0 {
+		t.Fatalf("stale value cached: %+v", stats)
+	}
+
+	if value, err := cached.Get("user"); err != nil || string(value) != "v2" {
+		t.Errorf("Get after invalidation = %q, %v", value, err)
+	}
+}
@@ -0,0 +1,1211 @@
+package gredistest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+type dialCounter struct {
+	gredis.EmptyHook
+	dials int64
+}
+
+func (h *dialCounter) AfterDial(ctx context.Context, dial *gredis.DialInfo) error {
+	atomic.AddInt64(&h.dials, 1)
+	return nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 拨号前改写网络类型、地址及连接名称
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+type dialRewriter struct {
+	gredis.EmptyHook
+	network    string
+	address    string
+	clientName string
+}
+
+func (h *dialRewriter) BeforeDial(ctx context.Context, dial *gredis.DialInfo) (context.Context, error) {
+	dial.Network, dial.Address, dial.ClientName = h.network, h.address, h.clientName
+	return ctx, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 命令返回后执行一次 action
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+type afterCommandHook struct {
+	gredis.EmptyHook
+	command string
+	action  func()
+}
+
+func (h *afterCommandHook) AfterProcess(ctx context.Context, command *gredis.Command) error {
+	if action := h.action; action != nil && command.Name == h.command {
+		h.action = nil
+		action()
+	}
+	return nil
+}
+
+func newTestServer(t *testing.T, args ...gredistest.ServerOption) *gredistest.Server {
+	server, err := gredistest.NewServer(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	return server
+}
+
+func newServerRedis(t *testing.T, server *gredistest.Server, option gredis.RedisOption) gredis.IRedis {
+	if len(option.Prefix) == 0 {
+		option.Prefix = "test:"
+	}
+
+	redis := server.NewRedis(option)
+	t.Cleanup(func() { redis.Close() })
+
+	return redis
+}
+
+func newFakeRedis(t *testing.T, fake *gredistest.Fake, option gredis.RedisOption) gredis.IRedis {
+	if len(option.Prefix) == 0 {
+		option.Prefix = "test:"
+	}
+
+	redis := fake.NewRedis(option)
+	t.Cleanup(func() { redis.Close() })
+
+	return redis
+}
+
+func TestClientPoolReuse(t *testing.T) {
+	server := newTestServer(t)
+	hook := &dialCounter{}
+	redis := newServerRedis(t, server, gredis.RedisOption{Hooks: []gredis.Hook{hook}})
+
+	for i := 0; i < 10; i++ {
+		if err := redis.Set("name", "gredis"); err != nil {
+			t.Fatal(err)
+		}
+
+		if value, err := redis.Get("name"); err != nil || string(value) != "gredis" {
+			t.Fatalf("Get = %q, %v", value, err)
+		}
+	}
+
+	if count := server.Connections(); count != 1 {
+		t.Errorf("Connections = %d, want 1", count)
+	}
+
+	if dials := atomic.LoadInt64(&hook.dials); dials != 1 {
+		t.Errorf("dials = %d, want 1", dials)
+	}
+
+	if stats := redis.PoolStats(); stats.IdleCount != 1 || stats.ActiveCount != 1 {
+		t.Errorf("PoolStats = %+v, want one idle connection", stats)
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	server := newTestServer(t, gredistest.ServerOption{Password: "secret"})
+
+	redis := newServerRedis(t, server, gredis.RedisOption{Password: "secret"})
+	if err := redis.Set("name", "gredis"); err != nil {
+		t.Fatal(err)
+	}
+
+	wrong := newServerRedis(t, server, gredis.RedisOption{Password: "wrong"})
+	if _, err := wrong.Get("name"); !errors.Is(err, gredis.ErrWrongPass) {
+		t.Errorf("Get with wrong password err = %v, want ErrWrongPass", err)
+	}
+
+	anonymous := newServerRedis(t, server, gredis.RedisOption{})
+	if _, err := anonymous.Get("name"); !errors.Is(err, gredis.ErrNoAuth) {
+		t.Errorf("Get without password err = %v, want ErrNoAuth", err)
+	}
+}
+
+func TestClientSelectDb(t *testing.T) {
+	server := newTestServer(t)
+	db0 := newServerRedis(t, server, gredis.RedisOption{})
+	db1 := newServerRedis(t, server, gredis.RedisOption{Db: 1})
+
+	if err := db1.Set("name", "db1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if isExists, err := db0.Exists("name"); err != nil || isExists {
+		t.Errorf("db0 Exists = %v, %v, want false", isExists, err)
+	}
+
+	if value, err := db1.Get("name"); err != nil || string(value) != "db1" {
+		t.Errorf("db1 Get = %q, %v", value, err)
+	}
+
+	invalid := newServerRedis(t, server, gredis.RedisOption{Db: 16})
+	if _, err := invalid.Get("name"); err == nil {
+		t.Error("Get on out of range db should fail")
+	}
+}
+
+func TestClientUnixSocket(t *testing.T) {
+	server := newTestServer(t, gredistest.ServerOption{
+		Network: "unix",
+		Address: filepath.Join(t.TempDir(), "redis.sock"),
+	})
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	if err := redis.LPush("queue", "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := redis.LLen("queue"); err != nil || count != 2 {
+		t.Errorf("LLen = %d, %v, want 2", count, err)
+	}
+}
+
+func TestClientDialHook(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "redis.sock")
+	newTestServer(t, gredistest.ServerOption{Network: "unix", Address: socket})
+
+	for _, protocol := range []int{gredis.REDIS_PROTOCOL_RESP2, gredis.REDIS_PROTOCOL_RESP3} {
+		// 选项中的默认 tcp 地址无服务，拨号钩子将其改写为 unix socket
+		redis := gredis.NewRedisWithOption(gredis.RedisOption{
+			Port:       1,
+			Timeout:    1,
+			ClientName: "original",
+			Protocol:   protocol,
+			Hooks:      []gredis.Hook{&dialRewriter{network: "unix", address: socket, clientName: "rewritten"}},
+		})
+		defer redis.Close()
+
+		if name, err := redis.Do("CLIENT", "GETNAME"); err != nil || fmt.Sprintf("%s", name) != "rewritten" {
+			t.Errorf("RESP%d CLIENT GETNAME = %v, %v, want rewritten", protocol, name, err)
+		}
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{Timeout: 1})
+
+	if err := redis.Set("name", "gredis"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.SetLatency("GET", 1500*time.Millisecond)
+
+	_, err := redis.Get("name")
+
+	var netError net.Error
+	if !errors.As(err, &netError) || !netError.Timeout() {
+		t.Fatalf("Get err = %v, want timeout", err)
+	}
+
+	server.ResetFaults()
+
+	if value, err := redis.Get("name"); err != nil || string(value) != "gredis" {
+		t.Errorf("Get after timeout = %q, %v", value, err)
+	}
+}
+
+func TestClientRetryDisconnect(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	if err := redis.Set("name", "gredis"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.DisconnectNext("GET", 1)
+	if _, err := redis.Get("name"); err == nil {
+		t.Fatal("Get on disconnect should fail without retry")
+	}
+
+	retry := newServerRedis(t, server, gredis.RedisOption{
+		Retry: &gredis.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond},
+	})
+
+	server.DisconnectNext("GET", 2)
+	if value, err := retry.Get("name"); err != nil || string(value) != "gredis" {
+		t.Errorf("Get with retry = %q, %v", value, err)
+	}
+
+	server.DisconnectNext("INCR", 1)
+	if _, err := retry.Incr("counter"); err == nil {
+		t.Error("non-idempotent Incr should not be retried after disconnect")
+	}
+
+	server.DisconnectNext("ZADD", 1)
+	if _, err := retry.ZAddIncr("scores", "alice", 10); err == nil {
+		t.Error("ZAddIncr should not be retried after disconnect")
+	}
+
+	server.DisconnectNext("ZADD", 1)
+	if err := retry.ZAdd("scores", 10, "alice"); err != nil {
+		t.Errorf("ZAdd with retry err = %v", err)
+	}
+
+	// 重复执行会再次修剪、找不到源Key或返回未移动
+	server.DisconnectNext("LTRIM", 1)
+	if err := retry.LTrim("jobs", 1, -1); err == nil {
+		t.Error("LTrim should not be retried after disconnect")
+	}
+
+	server.DisconnectNext("RENAME", 1)
+	if err := retry.Rename("name", "title"); err == nil {
+		t.Error("Rename should not be retried after disconnect")
+	}
+
+	server.DisconnectNext("SMOVE", 1)
+	if _, err := retry.SMove("tags", "archived", "go"); err == nil {
+		t.Error("SMove should not be retried after disconnect")
+	}
+}
+
+func TestClientRetryContext(t *testing.T) {
+	server := newTestServer(t)
+	retry := newServerRedis(t, server, gredis.RedisOption{
+		Retry: &gredis.RetryPolicy{MaxAttempts: 5, MinBackoff: time.Second, MaxBackoff: time.Second},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// 退避等待期间上下文结束时立即返回
+	server.FailNext("", 10, "LOADING Redis is loading the dataset in memory")
+
+	startTime := time.Now()
+	if err := retry.WithContext(ctx).Set("name", "gredis"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Set err = %v, want context.DeadlineExceeded", err)
+	}
+
+	if _, err := retry.WithContext(ctx).Pipeline([]map[string][]interface{}{{"SET": {"test:name", "gredis"}}}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pipeline err = %v, want context.DeadlineExceeded", err)
+	}
+
+	if elapsed := time.Since(startTime); elapsed > 500*time.Millisecond {
+		t.Errorf("retries after the deadline took %v", elapsed)
+	}
+}
+
+func TestClientRetryConditionalSet(t *testing.T) {
+	server := newTestServer(t)
+	retry := newServerRedis(t, server, gredis.RedisOption{
+		Retry: &gredis.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond},
+	})
+
+	// 条件设置的结果取决于首次执行，连接断开后不重试
+	server.DisconnectNext("SETNX", 1)
+	if _, err := retry.SetNx("lock", "owner"); err == nil {
+		t.Error("SetNx should not be retried after disconnect")
+	}
+
+	server.DisconnectNext("MSETNX", 1)
+	if _, err := retry.MSetNx(map[string]interface{}{"a": 1, "b": 2}); err == nil {
+		t.Error("MSetNx should not be retried after disconnect")
+	}
+
+	for _, option := range []gredis.SetOption{{Nx: true}, {Xx: true}} {
+		server.DisconnectNext("SET", 1)
+		if _, err := retry.SetArgs("lock", "owner", option); err == nil {
+			t.Errorf("SetArgs(%+v) should not be retried after disconnect", option)
+		}
+	}
+
+	server.DisconnectNext("SET", 1)
+	if _, err := retry.SetGet("lock", "owner"); err == nil {
+		t.Error("SetGet should not be retried after disconnect")
+	}
+
+	server.DisconnectNext("JSON.SET", 1)
+	if _, err := retry.JSONSet("doc", "$", map[string]int{"a": 1}, gredis.JSONSetOption{Nx: true}); err == nil {
+		t.Error("JSONSet with Nx should not be retried after disconnect")
+	}
+
+	server.DisconnectNext("SET", 1)
+	if err := retry.Set("lock", "owner"); err != nil {
+		t.Errorf("Set with retry err = %v", err)
+	}
+
+	server.DisconnectNext("SET", 1)
+	if _, err := retry.SetArgs("lock", "owner", gredis.SetOption{Ttl: time.Minute}); err != nil {
+		t.Errorf("SetArgs with expiration and retry err = %v", err)
+	}
+}
+
+func TestClientRetryLoading(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	server.FailNext("SET", 1, "LOADING Redis is loading the dataset in memory")
+	if err := redis.Set("name", "gredis"); !errors.Is(err, gredis.ErrLoading) {
+		t.Fatalf("Set err = %v, want ErrLoading", err)
+	}
+
+	retry := newServerRedis(t, server, gredis.RedisOption{
+		Retry: &gredis.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond},
+	})
+
+	server.FailNext("SET", 2, "LOADING Redis is loading the dataset in memory")
+	if err := retry.Set("name", "gredis"); err != nil {
+		t.Errorf("Set with retry err = %v", err)
+	}
+}
+
+func TestClientBreaker(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{
+		Breaker: &gredis.BreakerOption{ConsecutiveFailures: 2, OpenTimeout: time.Minute},
+	})
+
+	server.FailNext("", 2, "LOADING Redis is loading the dataset in memory")
+
+	for i := 0; i < 2; i++ {
+		if _, err := redis.Get("name"); !errors.Is(err, gredis.ErrLoading) {
+			t.Fatalf("Get err = %v, want ErrLoading", err)
+		}
+	}
+
+	if _, err := redis.Get("name"); !errors.Is(err, gredis.ErrCircuitOpen) {
+		t.Errorf("Get err = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestClientPipeline(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	reply, err := redis.Pipeline([]map[string][]interface{}{
+		{"SET": {"test:name", "gredis"}},
+		{"INCR": {"test:counter"}},
+		{"GET": {"test:name"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replies, isOk := reply.([]interface{})
+	if !isOk || len(replies) != 3 {
+		t.Fatalf("Pipeline reply = %#v", reply)
+	}
+
+	if value, _ := replies[2].([]byte); string(value) != "gredis" {
+		t.Errorf("Pipeline GET = %#v", replies[2])
+	}
+
+	server.CloseClients()
+
+	// 空闲连接已被服务器断开，首次请求失败后连接被丢弃，再次请求重新拨号
+	redis.Get("name")
+
+	if value, err := redis.Get("name"); err != nil || string(value) != "gredis" {
+		t.Errorf("Get after reconnect = %q, %v", value, err)
+	}
+
+	if count := server.Connections(); count != 2 {
+		t.Errorf("Connections = %d, want 2", count)
+	}
+}
+
+func TestClientResp3(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{Protocol: gredis.REDIS_PROTOCOL_RESP3})
+
+	redis.HMSet("user", "name", "alice", "age", 30)
+	redis.ZAddMembers("board", []gredis.ZMember{{Member: "a", Score: 1.5}, {Member: "b", Score: 2}})
+	redis.SAdd("tags", "x", "y")
+
+	// Do 返回 RESP3 原生类型，可确认 HELLO 3 协商成功
+	if reply, err := redis.Do("HGETALL", "test:user"); err != nil || !reflect.DeepEqual(reply, map[string]interface{}{"name": []byte("alice"), "age": []byte("30")}) {
+		t.Fatalf("Do HGETALL = %#v, %v", reply, err)
+	}
+
+	if score, err := redis.Do("ZSCORE", "test:board", "a"); err != nil || score != 1.5 {
+		t.Errorf("Do ZSCORE = %#v, %v", score, err)
+	}
+
+	if values, err := redis.HGetAll("user"); err != nil || !reflect.DeepEqual(values, map[string]string{"name": "alice", "age": "30"}) {
+		t.Errorf("HGetAll = %v, %v", values, err)
+	}
+
+	if members, err := redis.ZRangeWithScore("board", 0, -1); err != nil || !reflect.DeepEqual(members, []gredis.ZMember{{Member: "a", Score: 1.5}, {Member: "b", Score: 2}}) {
+		t.Errorf("ZRangeWithScore = %v, %v", members, err)
+	}
+
+	if score, err := redis.ZScore("board", "b"); err != nil || score != 2 {
+		t.Errorf("ZScore = %v, %v", score, err)
+	}
+
+	if members, err := redis.SMembers("tags"); err != nil || !reflect.DeepEqual(members, []string{"x", "y"}) {
+		t.Errorf("SMembers = %v, %v", members, err)
+	}
+
+	if members, err := redis.ZPopMin("board"); err != nil || !reflect.DeepEqual(members, []gredis.ZMember{{Member: "a", Score: 1.5}}) {
+		t.Errorf("ZPopMin = %v, %v", members, err)
+	}
+
+	if _, err := redis.Get("missing"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("Get of missing key err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientLists(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	if count, err := redis.LPushX("list", "a"); err != nil || count != 0 {
+		t.Errorf("LPushX on missing list = %d, %v, want 0", count, err)
+	}
+
+	redis.RPush("list", "a", "b", "c", "b")
+
+	if count, err := redis.LInsertBefore("list", "c", "x"); err != nil || count != 5 {
+		t.Errorf("LInsertBefore = %d, %v, want 5", count, err)
+	}
+
+	if index, err := redis.LPos("list", "b", gredis.LPosOption{Rank: -1}); err != nil || index != 4 {
+		t.Errorf("LPos rank -1 = %d, %v, want 4", index, err)
+	}
+
+	if indexes, err := redis.LPosCount("list", "b", 0); err != nil || !reflect.DeepEqual(indexes, []int{1, 4}) {
+		t.Errorf("LPosCount = %v, %v", indexes, err)
+	}
+
+	if _, err := redis.LPos("list", "missing"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("LPos missing err = %v, want ErrNotFound", err)
+	}
+
+	if value, err := redis.RPop("list"); err != nil || value != "b" {
+		t.Errorf("RPop = %q, %v", value, err)
+	}
+
+	if values, err := redis.LPopCount("list", 2); err != nil || !reflect.DeepEqual(values, []string{"a", "b"}) {
+		t.Errorf("LPopCount = %v, %v", values, err)
+	}
+
+	if value, err := redis.LMove("list", "done", gredis.REDIS_LIST_LEFT, gredis.REDIS_LIST_RIGHT); err != nil || value != "x" {
+		t.Errorf("LMove = %q, %v", value, err)
+	}
+
+	if key, values, err := redis.LMPop(gredis.REDIS_LIST_RIGHT, 5, "empty", "list", "done"); err != nil || key != "list" || !reflect.DeepEqual(values, []string{"c"}) {
+		t.Errorf("LMPop = %q, %v, %v", key, values, err)
+	}
+
+	if _, err := redis.RPopCount("list", 1); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("RPopCount on missing list err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientBlockingPop(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{Timeout: 1})
+
+	// 阻塞时长超过客户端读超时时，应等待服务器超时而不是读超时
+	start := time.Now()
+	if _, _, err := redis.BLPop(1200*time.Millisecond, "jobs"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Fatalf("BLPop on empty list err = %v, want ErrNotFound", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("BLPop returned after %v, want about 1.2s", elapsed)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		producer := server.NewRedis(gredis.RedisOption{Prefix: "test:"})
+		defer producer.Close()
+
+		producer.RPush("jobs", "job1")
+	}()
+
+	key, value, err := redis.BRPop(0, "other", "jobs")
+	if err != nil || key != "jobs" || value != "job1" {
+		t.Fatalf("BRPop = %q, %q, %v", key, value, err)
+	}
+
+	redis.RPush("jobs", "job2")
+
+	if value, err := redis.BLMove("jobs", "processing", gredis.REDIS_LIST_RIGHT, gredis.REDIS_LIST_LEFT, time.Second); err != nil || value != "job2" {
+		t.Errorf("BLMove = %q, %v", value, err)
+	}
+
+	if key, values, err := redis.BLMPop(time.Second, gredis.REDIS_LIST_LEFT, 0, "processing"); err != nil || key != "processing" || !reflect.DeepEqual(values, []string{"job2"}) {
+		t.Errorf("BLMPop = %q, %v, %v", key, values, err)
+	}
+}
+
+func TestClientSortedSets(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	members := []gredis.ZMember{{Member: "a", Score: 1.5}, {Member: "b", Score: 2}, {Member: "c", Score: 2.25}}
+	if count, err := redis.ZAddMembers("zset", members); err != nil || count != 3 {
+		t.Fatalf("ZAddMembers = %d, %v", count, err)
+	}
+
+	if count, _ := redis.ZAddMembers("zset", []gredis.ZMember{{Member: "a", Score: 1}, {Member: "d", Score: 9}}, gredis.ZAddOption{Xx: true, Gt: true, Ch: true}); count != 0 {
+		t.Errorf("ZAddMembers XX GT CH = %d, want 0", count)
+	}
+
+	if _, err := redis.ZAddIncr("zset", "d", 1, gredis.ZAddOption{Xx: true}); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("ZAddIncr XX on missing member err = %v, want ErrNotFound", err)
+	}
+
+	if score, err := redis.ZIncrBy("zset", "a", 0.25); err != nil || score != 1.75 {
+		t.Errorf("ZIncrBy = %v, %v, want 1.75", score, err)
+	}
+
+	if score, err := redis.ZScore("zset", "c"); err != nil || score != 2.25 {
+		t.Errorf("ZScore = %v, %v, want 2.25", score, err)
+	}
+
+	if scores, err := redis.ZMScore("zset", "b", "missing"); err != nil || !reflect.DeepEqual(scores, []float64{2, 0}) {
+		t.Errorf("ZMScore = %v, %v", scores, err)
+	}
+
+	want := []gredis.ZMember{{Member: "a", Score: 1.75}, {Member: "b", Score: 2}, {Member: "c", Score: 2.25}}
+	if values, err := redis.ZRangeWithScore("zset", 0, -1); err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("ZRangeWithScore = %v, %v", values, err)
+	}
+
+	if values, err := redis.ZRangeArgsWithScores("zset", "+inf", "(1.75", gredis.ZRangeOption{ByScore: true, Rev: true, Count: 1}); err != nil || !reflect.DeepEqual(values, want[2:]) {
+		t.Errorf("ZRangeArgsWithScores BYSCORE REV LIMIT = %v, %v", values, err)
+	}
+
+	redis.ZAddMembers("lex", []gredis.ZMember{{Member: "apple"}, {Member: "banana"}, {Member: "cherry"}})
+
+	if values, err := redis.ZRangeByLex("lex", "(apple", "+"); err != nil || !reflect.DeepEqual(values, []string{"banana", "cherry"}) {
+		t.Errorf("ZRangeByLex = %v, %v", values, err)
+	}
+
+	if values, err := redis.ZRevRangeByLex("lex", "-", "[banana", 0, 1); err != nil || !reflect.DeepEqual(values, []string{"banana"}) {
+		t.Errorf("ZRevRangeByLex = %v, %v", values, err)
+	}
+
+	if values, err := redis.ZRangeArgs("lex", "[b", "[c", gredis.ZRangeOption{ByLex: true}); err != nil || !reflect.DeepEqual(values, []string{"banana"}) {
+		t.Errorf("ZRangeArgs BYLEX = %v, %v", values, err)
+	}
+
+	redis.ZAddMembers("other", []gredis.ZMember{{Member: "b", Score: 10}, {Member: "x", Score: 1}})
+
+	if count, err := redis.ZUnionStore("union", []string{"zset", "other"}, gredis.ZStoreOption{Weights: []float64{2, 1}, Aggregate: gredis.REDIS_ZSET_AGGREGATE_MAX}); err != nil || count != 4 {
+		t.Errorf("ZUnionStore = %d, %v, want 4", count, err)
+	}
+
+	if score, _ := redis.ZScore("union", "b"); score != 10 {
+		t.Errorf("ZUnionStore score of b = %v, want 10", score)
+	}
+
+	if count, err := redis.ZInterStore("inter", []string{"zset", "other"}); err != nil || count != 1 {
+		t.Errorf("ZInterStore = %d, %v, want 1", count, err)
+	}
+
+	if score, _ := redis.ZScore("inter", "b"); score != 12 {
+		t.Errorf("ZInterStore score of b = %v, want 12", score)
+	}
+
+	if values, err := redis.ZDiffWithScores("zset", "other"); err != nil || !reflect.DeepEqual(values, []gredis.ZMember{want[0], want[2]}) {
+		t.Errorf("ZDiffWithScores = %v, %v", values, err)
+	}
+
+	if values, err := redis.ZRandMemberWithScores("zset", -5); err != nil || len(values) != 5 {
+		t.Errorf("ZRandMemberWithScores = %v, %v", values, err)
+	}
+
+	if values, err := redis.ZPopMin("zset"); err != nil || !reflect.DeepEqual(values, want[:1]) {
+		t.Errorf("ZPopMin = %v, %v", values, err)
+	}
+
+	if values, err := redis.ZPopMax("zset", 5); err != nil || !reflect.DeepEqual(values, []gredis.ZMember{want[2], want[1]}) {
+		t.Errorf("ZPopMax = %v, %v", values, err)
+	}
+
+	if _, _, err := redis.BZPopMin(100*time.Millisecond, "zset"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("BZPopMin on empty zset err = %v, want ErrNotFound", err)
+	}
+
+	if key, member, err := redis.BZPopMax(time.Second, "zset", "other"); err != nil || key != "other" || member != (gredis.ZMember{Member: "b", Score: 10}) {
+		t.Errorf("BZPopMax = %q, %v, %v", key, member, err)
+	}
+}
+
+func TestClientSets(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	if count, err := redis.SAdd("set1", 1, 2, 3, 3); err != nil || count != 3 {
+		t.Errorf("SAdd = %d, %v, want 3", count, err)
+	}
+
+	redis.SAdd("set2", 2, 3, 4)
+
+	if count, err := redis.SRem("set1", 3, 9); err != nil || count != 1 {
+		t.Errorf("SRem = %d, %v, want 1", count, err)
+	}
+
+	if isMoved, err := redis.SMove("set2", "set1", 4); err != nil || !isMoved {
+		t.Errorf("SMove = %v, %v, want true", isMoved, err)
+	}
+
+	if isMoved, _ := redis.SMove("set2", "set1", 9); isMoved {
+		t.Error("SMove of missing member = true")
+	}
+
+	if flags, err := redis.SMIsMember("set1", 1, 3, 4); err != nil || !reflect.DeepEqual(flags, []bool{true, false, true}) {
+		t.Errorf("SMIsMember = %v, %v", flags, err)
+	}
+
+	if count, err := redis.SInterCard(0, "set1", "set2"); err != nil || count != 1 {
+		t.Errorf("SInterCard = %d, %v, want 1", count, err)
+	}
+
+	if count, err := redis.SUnionStore("union", "set1", "set2"); err != nil || count != 4 {
+		t.Errorf("SUnionStore = %d, %v, want 4", count, err)
+	}
+
+	if count, _ := redis.SInterStore("inter", "set1", "set2"); count != 1 {
+		t.Errorf("SInterStore = %d, want 1", count)
+	}
+
+	if count, _ := redis.SDiffStore("diff", "set1", "set2"); count != 2 {
+		t.Errorf("SDiffStore = %d, want 2", count)
+	}
+
+	if values, err := gredis.As[int64](redis.SMembers("union")); err != nil || !reflect.DeepEqual(values, []int64{1, 2, 3, 4}) {
+		t.Errorf("As[int64](SMembers) = %v, %v", values, err)
+	}
+
+	if values, err := redis.SDiffInt("set1", "set2"); err != nil || !reflect.DeepEqual(values, []int{1, 4}) {
+		t.Errorf("SDiffInt = %v, %v", values, err)
+	}
+
+	redis.SAdd("words", "apple", "avocado", "banana")
+	if _, err := gredis.As[int](redis.SMembers("words")); !errors.Is(err, gredis.ErrUnexpectedReply) {
+		t.Errorf("As[int] of words err = %v, want ErrUnexpectedReply", err)
+	}
+
+	members := make([]string, 0)
+	cursor := uint64(0)
+	for {
+		next, items, err := redis.SScan("words", cursor, gredis.ScanOption{Match: "a*", Count: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		members = append(members, items...)
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if !reflect.DeepEqual(members, []string{"apple", "avocado"}) {
+		t.Errorf("SScan = %v", members)
+	}
+}
+
+func TestClientHashes(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	redis.HMSet("session", "user", "alice", "token", "abc", "csrf", "xyz")
+
+	if values, err := redis.HGetAll("session"); err != nil || !reflect.DeepEqual(values, map[string]string{"user": "alice", "token": "abc", "csrf": "xyz"}) {
+		t.Errorf("HGetAll = %v, %v", values, err)
+	}
+
+	if values, err := redis.HMGetValues("session", "user", "missing"); err != nil || len(values) != 2 || *values[0] != "alice" || values[1] != nil {
+		t.Errorf("HMGetValues = %v, %v", values, err)
+	}
+
+	if fields, err := redis.HRandField("session", 5); err != nil || len(fields) != 3 {
+		t.Errorf("HRandField = %v, %v, want 3 fields", fields, err)
+	}
+
+	if values, err := redis.HRandFieldWithValues("session", -2); err != nil || len(values) == 0 {
+		t.Errorf("HRandFieldWithValues = %v, %v", values, err)
+	}
+
+	scanned := make(map[string]string)
+	cursor := uint64(0)
+	for {
+		next, values, err := redis.HScan("session", cursor, gredis.ScanOption{Count: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for field, value := range values {
+			scanned[field] = value
+		}
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	if len(scanned) != 3 || scanned["token"] != "abc" {
+		t.Errorf("HScan = %v", scanned)
+	}
+
+	if results, err := redis.HExpire("session", time.Minute, "token", "missing"); err != nil || !reflect.DeepEqual(results, []int{1, -2}) {
+		t.Errorf("HExpire = %v, %v", results, err)
+	}
+
+	// 不足命令精度的过期时间不发送到服务器
+	for _, ttl := range []time.Duration{500 * time.Millisecond, 1900 * time.Millisecond} {
+		if _, err := redis.HExpire("session", ttl, "token"); !errors.Is(err, gredis.ErrInvalidTtl) {
+			t.Errorf("HExpire(%v) err = %v, want ErrInvalidTtl", ttl, err)
+		}
+	}
+
+	if _, err := redis.HSetEx("session", time.Minute, "otp", "1", "extra"); !errors.Is(err, gredis.ErrInvalidArgs) {
+		t.Errorf("HSetEx with an odd field list err = %v, want ErrInvalidArgs", err)
+	}
+
+	for _, err := range []error{
+		func() error { _, err := redis.HPexpire("session", time.Microsecond, "token"); return err }(),
+		func() error { _, err := redis.HGetEx("session", time.Microsecond, "token"); return err }(),
+		func() error { _, err := redis.HSetEx("session", time.Microsecond, "otp", "1"); return err }(),
+	} {
+		if !errors.Is(err, gredis.ErrInvalidTtl) {
+			t.Errorf("sub-millisecond ttl err = %v, want ErrInvalidTtl", err)
+		}
+	}
+
+	if results, err := redis.HTtl("session", "token", "user"); err != nil || !reflect.DeepEqual(results, []int{60, -1}) {
+		t.Errorf("HTtl = %v, %v", results, err)
+	}
+
+	if results, err := redis.HPexpire("session", 1500*time.Millisecond, "csrf"); err != nil || !reflect.DeepEqual(results, []int{1}) {
+		t.Errorf("HPexpire = %v, %v", results, err)
+	}
+
+	if values, err := redis.HGetEx("session", 10*time.Second, "csrf"); err != nil || len(values) != 1 || *values[0] != "xyz" {
+		t.Errorf("HGetEx = %v, %v", values, err)
+	}
+
+	if results, _ := redis.HPttl("session", "csrf"); len(results) != 1 || results[0] != 10000 {
+		t.Errorf("HPttl = %v, want [10000]", results)
+	}
+
+	server.Clock().Advance(30 * time.Second)
+
+	if values, _ := redis.HGetAll("session"); !reflect.DeepEqual(values, map[string]string{"user": "alice", "token": "abc"}) {
+		t.Errorf("HGetAll after csrf expired = %v", values)
+	}
+
+	if results, _ := redis.HPersist("session", "token", "user"); !reflect.DeepEqual(results, []int{1, -1}) {
+		t.Errorf("HPersist = %v", results)
+	}
+
+	if isSet, err := redis.HSetEx("session", time.Minute, "otp", "123456"); err != nil || !isSet {
+		t.Errorf("HSetEx = %v, %v", isSet, err)
+	}
+
+	server.Clock().Advance(2 * time.Minute)
+
+	if isExists, _ := redis.HExists("session", "otp"); isExists {
+		t.Error("otp field still exists after its ttl")
+	}
+
+	if values, err := redis.HGetDel("session", "user", "token"); err != nil || len(values) != 2 || *values[1] != "abc" {
+		t.Errorf("HGetDel = %v, %v", values, err)
+	}
+
+	if isExists, _ := redis.Exists("session"); isExists {
+		t.Error("session still exists after all fields were deleted")
+	}
+}
+
+func TestClientStrings(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{Prefix: "app:"})
+
+	if err := redis.Set("short", "v", 1500, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl, err := redis.Pttl("short"); err != nil || ttl != 1500 {
+		t.Errorf("Set millisecond Pttl = %d, %v, want 1500", ttl, err)
+	}
+
+	if isSet, err := redis.SetNx("lock", "a"); err != nil || !isSet {
+		t.Errorf("SetNx = %v, %v, want true", isSet, err)
+	}
+
+	if isSet, _ := redis.SetNx("lock", "b"); isSet {
+		t.Error("SetNx of existing key = true")
+	}
+
+	if isSet, err := redis.SetArgs("lock", "c", gredis.SetOption{Xx: true, Ttl: 10 * time.Second}); err != nil || !isSet {
+		t.Errorf("SetArgs Xx = %v, %v, want true", isSet, err)
+	}
+
+	if isSet, _ := redis.SetArgs("lock", "d", gredis.SetOption{Nx: true}); isSet {
+		t.Error("SetArgs Nx of existing key = true")
+	}
+
+	if ttl, _ := redis.Pttl("lock"); ttl != 10000 {
+		t.Errorf("SetArgs Ttl Pttl = %d, want 10000", ttl)
+	}
+
+	// 不足1毫秒的过期时间不发送到服务器
+	shortTtl := gredis.SetOption{Ttl: time.Microsecond}
+	for _, err := range []error{
+		func() error { _, err := redis.SetArgs("lock", "x", shortTtl); return err }(),
+		func() error { _, err := redis.SetGet("lock", "x", shortTtl); return err }(),
+		func() error { _, err := redis.GetEx("lock", time.Microsecond); return err }(),
+	} {
+		if !errors.Is(err, gredis.ErrInvalidTtl) {
+			t.Errorf("sub-millisecond ttl err = %v, want ErrInvalidTtl", err)
+		}
+	}
+
+	if value, _ := redis.Get("lock"); string(value) != "c" {
+		t.Errorf("value after rejected ttl = %q, want c", value)
+	}
+
+	if old, err := redis.SetGet("lock", "e", gredis.SetOption{KeepTtl: true}); err != nil || string(old) != "c" {
+		t.Errorf("SetGet = %q, %v, want c", old, err)
+	}
+
+	if ttl, _ := redis.Pttl("lock"); ttl != 10000 {
+		t.Errorf("SetGet KeepTtl Pttl = %d, want 10000", ttl)
+	}
+
+	if _, err := redis.SetGet("fresh", "v"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("SetGet of missing key err = %v, want ErrNotFound", err)
+	}
+
+	if err := redis.MSet(map[string]interface{}{"k1": "one", "k2": "two"}); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := redis.MGet("k1", "missing", "k2")
+	if err != nil || len(values) != 3 || string(values[0]) != "one" || values[1] != nil || string(values[2]) != "two" {
+		t.Errorf("MGet = %q, %v", values, err)
+	}
+
+	if isSet, _ := redis.MSetNx(map[string]interface{}{"k2": "x", "k3": "y"}); isSet {
+		t.Error("MSetNx with an existing key = true")
+	}
+
+	if isExists, _ := redis.Exists("k3"); isExists {
+		t.Error("MSetNx set k3 although k2 exists")
+	}
+
+	if value, err := redis.GetEx("k1", time.Minute); err != nil || string(value) != "one" {
+		t.Errorf("GetEx = %q, %v", value, err)
+	}
+
+	if ttl, _ := redis.Ttl("k1"); ttl != 60 {
+		t.Errorf("GetEx Ttl = %d, want 60", ttl)
+	}
+
+	if value, err := redis.GetDel("k2"); err != nil || string(value) != "two" {
+		t.Errorf("GetDel = %q, %v", value, err)
+	}
+
+	if _, err := redis.GetDel("k2"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("second GetDel err = %v, want ErrNotFound", err)
+	}
+
+	redis.Set("s1", "ohmytext")
+	redis.Set("s2", "mynewtext")
+
+	if value, err := redis.LCS("s1", "s2"); err != nil || value != "mytext" {
+		t.Errorf("LCS = %q, %v, want mytext", value, err)
+	}
+
+	if length, err := redis.LCSLen("s1", "s2"); err != nil || length != 6 {
+		t.Errorf("LCSLen = %d, %v, want 6", length, err)
+	}
+}
+
+func TestClientBitmaps(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+
+	if previous, err := redis.SetBit("bits", 7, 1); err != nil || previous != 0 {
+		t.Errorf("SetBit = %d, %v, want 0", previous, err)
+	}
+
+	redis.SetBit("bits", 8, 1)
+	redis.SetBit("bits", 23, 1)
+
+	if bit, _ := redis.GetBit("bits", 7); bit != 1 {
+		t.Errorf("GetBit = %d, want 1", bit)
+	}
+
+	if count, err := redis.BitCount("bits"); err != nil || count != 3 {
+		t.Errorf("BitCount = %d, %v, want 3", count, err)
+	}
+
+	if count, _ := redis.BitCount("bits", gredis.BitRangeOption{Start: 1, End: -1}); count != 2 {
+		t.Errorf("BitCount bytes 1..-1 = %d, want 2", count)
+	}
+
+	if count, _ := redis.BitCount("bits", gredis.BitRangeOption{Start: 0, End: 7, Bit: true}); count != 1 {
+		t.Errorf("BitCount bits 0..7 = %d, want 1", count)
+	}
+
+	if position, err := redis.BitPos("bits", 1); err != nil || position != 7 {
+		t.Errorf("BitPos = %d, %v, want 7", position, err)
+	}
+
+	if position, _ := redis.BitPos("bits", 1, gredis.BitRangeOption{Start: 9, End: -1, Bit: true}); position != 23 {
+		t.Errorf("BitPos from bit 9 = %d, want 23", position)
+	}
+
+	redis.Set("a", "\xf0")
+	redis.Set("b", "\x3c")
+
+	if size, err := redis.BitOp(gredis.REDIS_BITOP_AND, "and", "a", "b"); err != nil || size != 1 {
+		t.Errorf("BitOp AND = %d, %v, want 1", size, err)
+	}
+
+	if value, _ := redis.Get("and"); string(value) != "\x30" {
+		t.Errorf("BitOp AND result = %q, want \\x30", value)
+	}
+
+	redis.BitOp(gredis.REDIS_BITOP_NOT, "not", "a")
+	if value, _ := redis.Get("not"); string(value) != "\x0f" {
+		t.Errorf("BitOp NOT result = %q, want \\x0f", value)
+	}
+
+	results, err := redis.BitField("counters",
+		gredis.BitFieldSet("u8", 0, 250),
+		gredis.BitFieldIncrBy("u8", 0, 10),
+		gredis.BitFieldOverflow(gredis.REDIS_BITFIELD_OVERFLOW_SAT),
+		gredis.BitFieldIncrBy("i8", 8, -200),
+		gredis.BitFieldOverflow(gredis.REDIS_BITFIELD_OVERFLOW_FAIL),
+		gredis.BitFieldIncrBy("u8", 0, 300),
+		gredis.BitFieldGet("u8", 0),
+	)
+	if err != nil || len(results) != 5 {
+		t.Fatalf("BitField = %v, %v", results, err)
+	}
+
+	for index, want := range []*int64{int64Ptr(0), int64Ptr(4), int64Ptr(-128), nil, int64Ptr(4)} {
+		if got := results[index]; (got == nil) != (want == nil) || (got != nil && *got != *want) {
+			t.Errorf("BitField[%d] = %v, want %v", index, got, want)
+		}
+	}
+
+	if values, err := redis.BitFieldRo("counters", gredis.BitFieldGet("u8", 0), gredis.BitFieldGet("i8", 8)); err != nil || !reflect.DeepEqual(values, []int64{4, -128}) {
+		t.Errorf("BitFieldRo = %v, %v", values, err)
+	}
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func TestClientHyperLogLog(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{Prefix: "app:"})
+
+	if isChanged, err := redis.PfAdd("day1", "a", "b", "c"); err != nil || !isChanged {
+		t.Errorf("PfAdd = %v, %v, want true", isChanged, err)
+	}
+
+	if isChanged, _ := redis.PfAdd("day1", "a"); isChanged {
+		t.Error("PfAdd of existing element = true")
+	}
+
+	redis.PfAdd("day2", "c", "d")
+
+	if count, err := redis.PfCount("day1", "day2"); err != nil || count != 4 {
+		t.Errorf("PfCount = %d, %v, want 4", count, err)
+	}
+
+	if err := redis.PfMerge("week", "day1", "day2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, _ := redis.PfCount("week"); count != 4 {
+		t.Errorf("PfCount after PfMerge = %d, want 4", count)
+	}
+
+	redis.Set("plain", "value")
+	if _, err := redis.PfCount("plain"); !errors.Is(err, gredis.ErrWrongType) {
+		t.Errorf("PfCount of plain string err = %v, want ErrWrongType", err)
+	}
+}
+
+func TestClientGeo(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{Prefix: "app:"})
+
+	stores := []gredis.GeoLocation{
+		{Name: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		{Name: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+	}
+
+	if count, err := redis.GeoAdd("stores", stores); err != nil || count != 2 {
+		t.Errorf("GeoAdd = %d, %v, want 2", count, err)
+	}
+
+	if count, _ := redis.GeoAdd("stores", stores[:1], gredis.GeoAddOption{Nx: true}); count != 0 {
+		t.Errorf("GeoAdd Nx of existing member = %d, want 0", count)
+	}
+
+	locations, err := redis.GeoPos("stores", "Palermo", "missing")
+	if err != nil || len(locations) != 2 || locations[1] != nil {
+		t.Fatalf("GeoPos = %v, %v", locations, err)
+	}
+
+	if location := locations[0]; location.Name != "Palermo" || math.Abs(location.Longitude-13.361389) > 1e-5 || math.Abs(location.Latitude-38.115556) > 1e-5 {
+		t.Errorf("GeoPos[0] = %+v", location)
+	}
+
+	if dist, err := redis.GeoDist("stores", "Palermo", "Catania", gredis.REDIS_GEO_UNIT_KM); err != nil || dist != 166.2742 {
+		t.Errorf("GeoDist = %v, %v, want 166.2742", dist, err)
+	}
+
+	if _, err := redis.GeoDist("stores", "Palermo", "missing"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("GeoDist of missing member err = %v, want ErrNotFound", err)
+	}
+
+	if hashes, err := redis.GeoHash("stores", "Palermo", "Catania"); err != nil || !reflect.DeepEqual(hashes, []string{"sqc8b49rny0", "sqdtr74hyu0"}) {
+		t.Errorf("GeoHash = %v, %v", hashes, err)
+	}
+
+	query := gredis.GeoSearchQuery{
+		Longitude: 15,
+		Latitude:  37,
+		Radius:    200,
+		Unit:      gredis.REDIS_GEO_UNIT_KM,
+		Sort:      gredis.REDIS_GEO_SORT_ASC,
+		WithCoord: true,
+		WithDist:  true,
+		WithHash:  true,
+	}
+
+	results, err := redis.GeoSearch("stores", query)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("GeoSearch = %v, %v", results, err)
+	}
+
+	if first := results[0]; first.Name != "Catania" || first.Dist != 56.4413 || first.Hash != 3479447370796909 || math.Abs(first.Longitude-15.087269) > 1e-5 {
+		t.Errorf("GeoSearch[0] = %+v", first)
+	}
+
+	nearest, err := redis.GeoSearch("stores", gredis.GeoSearchQuery{Member: "Palermo", Width: 400, Height: 400, Unit: gredis.REDIS_GEO_UNIT_KM, Count: 1})
+	if err != nil || len(nearest) != 1 || nearest[0].Name != "Palermo" {
+		t.Errorf("GeoSearch by box = %v, %v", nearest, err)
+	}
+
+	if count, err := redis.GeoSearchStore("nearby", "stores", gredis.GeoSearchQuery{Longitude: 15, Latitude: 37, Radius: 100, Unit: gredis.REDIS_GEO_UNIT_KM, StoreDist: true}); err != nil || count != 1 {
+		t.Errorf("GeoSearchStore = %d, %v, want 1", count, err)
+	}
+
+	if score, err := redis.ZScore("nearby", "Catania"); err != nil || math.Abs(score-56.4413) > 1e-3 {
+		t.Errorf("ZScore of stored distance = %v, %v, want 56.4413", score, err)
+	}
+}
+
+func TestClientJSON(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{Prefix: "app:"})
+
+	type profile struct {
+		Name   string   `json:"name"`
+		Age    int      `json:"age"`
+		Tags   []string `json:"tags"`
+		Active bool     `json:"active"`
+	}
+
+	if isOk, err := redis.JSONSet("user:1", gredis.REDIS_JSON_ROOT_PATH, profile{Name: "Ann", Age: 30, Tags: []string{"a"}}); err != nil || !isOk {
+		t.Fatalf("JSONSet = %v, %v", isOk, err)
+	}
+
+	if _, err := redis.JSONSet("user:2", "$.name", "Bob"); err == nil {
+		t.Errorf("JSONSet of new key at non-root path err = nil")
+	}
+
+	if isOk, err := redis.JSONSet("user:1", "$.name", "Bob", gredis.JSONSetOption{Nx: true}); err != nil || isOk {
+		t.Errorf("JSONSet Nx of existing path = %v, %v, want false", isOk, err)
+	}
+
+	if isOk, err := redis.JSONSet("user:1", "$.city", "Paris"); err != nil || !isOk {
+		t.Errorf("JSONSet of new member = %v, %v", isOk, err)
+	}
+
+	if data, err := redis.JSONGet("user:1"); err != nil || string(data) != `{"name":"Ann","age":30,"tags":["a"],"active":false,"city":"Paris"}` {
+		t.Errorf("JSONGet = %s, %v", data, err)
+	}
+
+	if data, err := redis.JSONGet("user:1", "$.name", "$.missing"); err != nil || string(data) != `{"$.name":["Ann"],"$.missing":[]}` {
+		t.Errorf("JSONGet of multiple paths = %s, %v", data, err)
+	}
+
+	if _, err := redis.JSONGet("missing"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("JSONGet of missing key err = %v, want ErrNotFound", err)
+	}
+
+	if values, err := redis.JSONNumIncrBy("user:1", "$.age", 2); err != nil || len(values) != 1 || *values[0] != 32 {
+		t.Errorf("JSONNumIncrBy = %v, %v", values, err)
+	}
+
+	if values, err := redis.JSONNumIncrBy("user:1", ".age", 1); err != nil || len(values) != 1 || *values[0] != 33 {
+		t.Errorf("JSONNumIncrBy of legacy path = %v, %v", values, err)
+	}
+
+	if values, err := redis.JSONNumIncrBy("user:1", "$.name", 1); err != nil || len(values) != 1 || values[0] != nil {
+		t.Errorf("JSONNumIncrBy of string = %v, %v, want [nil]", values, err)
+	}
+
+	if lengths, err := redis.JSONArrAppend("user:1", "$.tags", "b", "c"); err != nil || len(lengths) != 1 || *lengths[0] != 3 {
+		t.Errorf("JSONArrAppend = %v, %v", lengths, err)
+	}
+
+	if lengths, err := redis.JSONArrAppend("user:1", ".tags", "d"); err != nil || len(lengths) != 1 || *lengths[0] != 4 {
+		t.Errorf("JSONArrAppend of legacy path = %v, %v", lengths, err)
+	}
+
+	var user profile
+	if err := redis.JSONGetData("user:1", gredis.REDIS_JSON_LEGACY_ROOT_PATH, &user); err != nil || user.Name != "Ann" || user.Age != 33 || !reflect.DeepEqual(user.Tags, []string{"a", "b", "c", "d"}) {
+		t.Errorf("JSONGetData = %+v, %v", user, err)
+	}
+
+	if count, err := redis.JSONDel("user:1", "$.tags[-1]"); err != nil || count != 1 {
+		t.Errorf("JSONDel = %d, %v, want 1", count, err)
+	}
+
+	redis.JSONSet("user:2", gredis.REDIS_JSON_ROOT_PATH, map[string]interface{}{"name": "Cid", "friends": []interface{}{map[string]interface{}{"name": "Dee"}}})
+
+	if data, err := redis.JSONGet("user:2", "$..name"); err != nil || string(data) != `["Cid","Dee"]` {
+		t.Errorf("JSONGet of recursive path = %s, %v", data, err)
+	}
+
+	if values, err := redis.JSONMGet("$.name", "user:1", "missing", "user:2"); err != nil || len(values) != 3 || string(values[0]) != `["Ann"]` || values[1] != nil || string(values[2]) != `["Cid"]` {
+		t.Errorf("JSONMGet = %q, %v", values, err)
+	}
+
+	if keyType, err := redis.Type("user:2"); err != nil || keyType != "ReJSON-RL" {
+		t.Errorf("Type = %q, %v, want ReJSON-RL", keyType, err)
+	}
+
+	if count, err := redis.JSONDel("user:2"); err != nil || count != 1 {
+		t.Errorf("JSONDel of root = %d, %v, want 1", count, err)
+	}
+
+	if isExists, _ := redis.Exists("user:2"); isExists {
+		t.Errorf("Exists after JSONDel of root = true")
+	}
+}
@@ -0,0 +1,569 @@
+package gredistest
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ================================================================================
+ * In-memory engine
+ * 纯内存的命令执行引擎，所有命令在引擎锁内串行执行
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	errWrongType    errorReply = "WRONGTYPE Operation against a key holding the wrong kind of value"
+	errSyntax       errorReply = "ERR syntax error"
+	errNotInteger   errorReply = "ERR value is not an integer or out of range"
+	errNotFloat     errorReply = "ERR value is not a valid float"
+	errNoSuchKey    errorReply = "ERR no such key"
+	errOutOfRange   errorReply = "ERR index out of range"
+	errDbIndex      errorReply = "ERR DB index is out of range"
+	errNoAuth       errorReply = "NOAUTH Authentication required."
+	errWrongPass    errorReply = "WRONGPASS invalid username-password pair or user is disabled."
+	errNotMinMax    errorReply = "ERR min or max is not a float"
+	errIncrOverflow errorReply = "ERR increment or decrement would overflow"
+	errNotLexRange  errorReply = "ERR min or max not valid string range item"
+	errNotPositive  errorReply = "ERR value is out of range, must be positive"
+	errBitOffset    errorReply = "ERR bit offset is not an integer or out of range"
+	errBitValue     errorReply = "ERR bit is not an integer or out of range"
+	errBitType      errorReply = "ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."
+	errBitOverflow  errorReply = "ERR Invalid OVERFLOW type specified"
+	errNotHll       errorReply = "WRONGTYPE Key is not a valid HyperLogLog string value."
+	errNoIndex      errorReply = "Unknown Index name"
+	errIndexExists  errorReply = "Index already exists"
+)
+
+const (
+	engineDatabases int = 16
+)
+
+type (
+	engine struct {
+		mutex    sync.Mutex
+		clock    *Clock
+		rand     *rand.Rand
+		password string
+		dbs      []*database
+		clientId int64
+		sessions map[int64]*session
+		scripts  map[string]string
+		changed  chan struct{}
+		done     chan struct{}
+		doneOnce sync.Once
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 数据库，indexes 为 FT.CREATE 创建的搜索索引，FLUSHDB 时一并删除
+	 * changes 为待发送失效通知的已修改键，每条命令执行后发送
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	database struct {
+		keys     map[string]*entry
+		versions map[string]uint64
+		indexes  map[string]*searchIndex
+		changes  []string
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 键值，value 为 []byte | *listValue | *hashValue | setValue | zsetValue | *jsonValue
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	entry struct {
+		value    interface{}
+		expireAt time.Time
+	}
+
+	listValue struct {
+		items [][]byte
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 哈希值，expires 为字段过期时间（HEXPIRE 等），过期字段在读取哈希时删除
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	hashValue struct {
+		fields  []string
+		values  map[string][]byte
+		expires map[string]time.Time
+	}
+
+	setValue map[string]struct{}
+
+	zsetValue map[string]float64
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 命令定义，arity 与 Redis 一致：正数为精确参数个数（含命令名），负数为最少参数个数
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	command struct {
+		handler func(c *commandContext, args [][]byte) interface{}
+		arity   int
+	}
+
+	commandContext struct {
+		engine  *engine
+		session *session
+		db      *database
+		now     time.Time
+	}
+)
+
+var (
+	commands = map[string]command{}
+)
+
+func newEngine(clock *Clock, password string, seed int64) *engine {
+	e := &engine{
+		clock:    clock,
+		rand:     rand.New(rand.NewSource(seed)),
+		password: password,
+		dbs:      make([]*database, engineDatabases),
+		sessions: make(map[int64]*session),
+		scripts:  make(map[string]string),
+		done:     make(chan struct{}),
+	}
+
+	for index := range e.dbs {
+		e.dbs[index] = newDatabase()
+	}
+
+	return e
+}
+
+func newDatabase() *database {
+	return &database{
+		keys:     make(map[string]*entry),
+		versions: make(map[string]uint64),
+		indexes:  make(map[string]*searchIndex),
+	}
+}
+
+func (e *engine) flushAll() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, db := range e.dbs {
+		db.flush()
+	}
+
+	e.invalidate()
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取数据变化通知，调用方需持有引擎锁
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (e *engine) wait() <-chan struct{} {
+	if e.changed == nil {
+		e.changed = make(chan struct{})
+	}
+
+	return e.changed
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 唤醒等待数据变化的阻塞命令，调用方需持有引擎锁
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (e *engine) notify() {
+	if e.changed != nil {
+		close(e.changed)
+		e.changed = nil
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 关闭引擎，结束全部阻塞命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (e *engine) close() {
+	e.doneOnce.Do(func() {
+		close(e.done)
+	})
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 注册命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func register(name string, arity int, handler func(c *commandContext, args [][]byte) interface{}) {
+	commands[name] = command{
+		handler: handler,
+		arity:   arity,
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 校验命令名称及参数个数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func lookupCommand(args [][]byte) (command, interface{}) {
+	name := strings.ToUpper(string(args[0]))
+
+	cmd, isOk := commands[name]
+	if !isOk {
+		return cmd, errorReply("ERR unknown command '" + string(args[0]) + "', with args beginning with: ")
+	}
+
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		return cmd, errorReply("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+	}
+
+	return cmd, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行命令，调用方需持有引擎锁
+ * 命令处理函数以 panic(errorReply) 返回错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (e *engine) call(s *session, cmd command, args [][]byte) (reply interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, isOk := r.(errorReply)
+			if !isOk {
+				panic(r)
+			}
+			reply = err
+		}
+	}()
+
+	c := &commandContext{
+		engine:  e,
+		session: s,
+		db:      e.dbs[s.db],
+		now:     e.clock.Now(),
+	}
+
+	return cmd.handler(c, args[1:])
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取未过期的键值，过期的键在访问时删除
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) lookup(key string) *entry {
+	return c.db.lookup(key, c.now)
+}
+
+func (db *database) lookup(key string, now time.Time) *entry {
+	item, isOk := db.keys[key]
+	if !isOk {
+		return nil
+	}
+
+	if !item.expireAt.IsZero() && !now.Before(item.expireAt) {
+		db.remove(key)
+		return nil
+	}
+
+	return item
+}
+
+func (db *database) remove(key string) bool {
+	if _, isOk := db.keys[key]; !isOk {
+		return false
+	}
+
+	delete(db.keys, key)
+	db.touch(key)
+
+	return true
+}
+
+func (db *database) touch(key string) {
+	db.versions[key]++
+	db.changes = append(db.changes, key)
+}
+
+func (db *database) flush() {
+	for key := range db.keys {
+		db.touch(key)
+	}
+
+	db.keys = make(map[string]*entry)
+	db.indexes = make(map[string]*searchIndex)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 写入键值并清除过期时间
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) set(key string, value interface{}) {
+	c.db.keys[key] = &entry{value: value}
+	c.db.touch(key)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 写入键值并保留过期时间
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) put(key string, value interface{}) {
+	if item := c.lookup(key); item != nil {
+		item.value = value
+		c.db.touch(key)
+		return
+	}
+
+	c.set(key, value)
+}
+
+func (c *commandContext) remove(key string) bool {
+	return c.db.remove(key)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 集合类型修改后调用，元素为空时删除键
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) modified(key string, size int) {
+	if size == 0 {
+		c.remove(key)
+		return
+	}
+
+	c.db.touch(key)
+}
+
+func (c *commandContext) stringValue(key string) ([]byte, bool) {
+	item := c.lookup(key)
+	if item == nil {
+		return nil, false
+	}
+
+	value, isOk := item.value.([]byte)
+	if !isOk {
+		panic(errWrongType)
+	}
+
+	return value, true
+}
+
+func (c *commandContext) listValue(key string, isCreate bool) *listValue {
+	item := c.lookup(key)
+	if item == nil {
+		if !isCreate {
+			return nil
+		}
+
+		value := &listValue{}
+		c.db.keys[key] = &entry{value: value}
+		return value
+	}
+
+	value, isOk := item.value.(*listValue)
+	if !isOk {
+		panic(errWrongType)
+	}
+
+	return value
+}
+
+func (c *commandContext) hashValue(key string, isCreate bool) *hashValue {
+	item := c.lookup(key)
+	if item == nil {
+		if !isCreate {
+			return nil
+		}
+
+		value := newHashValue()
+		c.db.keys[key] = &entry{value: value}
+		return value
+	}
+
+	value, isOk := item.value.(*hashValue)
+	if !isOk {
+		panic(errWrongType)
+	}
+
+	if value.expireFields(c.now) && len(value.fields) == 0 {
+		c.remove(key)
+		return c.hashValue(key, isCreate)
+	}
+
+	return value
+}
+
+func (c *commandContext) setValue(key string, isCreate bool) setValue {
+	item := c.lookup(key)
+	if item == nil {
+		if !isCreate {
+			return nil
+		}
+
+		value := make(setValue)
+		c.db.keys[key] = &entry{value: value}
+		return value
+	}
+
+	value, isOk := item.value.(setValue)
+	if !isOk {
+		panic(errWrongType)
+	}
+
+	return value
+}
+
+func (c *commandContext) zsetValue(key string, isCreate bool) zsetValue {
+	item := c.lookup(key)
+	if item == nil {
+		if !isCreate {
+			return nil
+		}
+
+		value := make(zsetValue)
+		c.db.keys[key] = &entry{value: value}
+		return value
+	}
+
+	value, isOk := item.value.(zsetValue)
+	if !isOk {
+		panic(errWrongType)
+	}
+
+	return value
+}
+
+func newHashValue() *hashValue {
+	return &hashValue{
+		values: make(map[string][]byte),
+	}
+}
+
+func (h *hashValue) set(field string, value []byte) bool {
+	_, isExists := h.values[field]
+	if !isExists {
+		h.fields = append(h.fields, field)
+	}
+
+	h.values[field] = value
+
+	return !isExists
+}
+
+func (h *hashValue) remove(field string) bool {
+	if _, isExists := h.values[field]; !isExists {
+		return false
+	}
+
+	delete(h.values, field)
+	delete(h.expires, field)
+	for index, name := range h.fields {
+		if name == field {
+			h.fields = append(h.fields[:index], h.fields[index+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 删除已过期的字段，返回是否有字段被删除
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (h *hashValue) expireFields(now time.Time) bool {
+	isExpired := false
+	for field, expireAt := range h.expires {
+		if !now.Before(expireAt) {
+			h.remove(field)
+			isExpired = true
+		}
+	}
+
+	return isExpired
+}
+
+func (h *hashValue) setExpire(field string, expireAt time.Time) {
+	if expireAt.IsZero() {
+		delete(h.expires, field)
+		return
+	}
+
+	if h.expires == nil {
+		h.expires = make(map[string]time.Time)
+	}
+
+	h.expires[field] = expireAt
+}
+
+func (s setValue) members() []string {
+	members := make([]string, 0, len(s))
+	for member := range s {
+		members = append(members, member)
+	}
+
+	sort.Strings(members)
+
+	return members
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 参数解析
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func parseInt(arg []byte) int64 {
+	value, err := strconv.ParseInt(string(arg), 10, 64)
+	if err != nil {
+		panic(errNotInteger)
+	}
+
+	return value
+}
+
+func parseFloat(arg []byte) float64 {
+	value, err := parseFloatString(string(arg))
+	if err != nil {
+		panic(errNotFloat)
+	}
+
+	return value
+}
+
+func parseFloatString(value string) (float64, error) {
+	switch strings.ToLower(value) {
+	case "inf", "+inf":
+		value = "+Inf"
+	case "-inf":
+		value = "-Inf"
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil || result != result {
+		return 0, errProtocol
+	}
+
+	return result, nil
+}
+
+func isOption(arg []byte, option string) bool {
+	return strings.EqualFold(string(arg), option)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 将 [start, stop] 索引（支持负数）转换为 [0, length) 内的区间
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func normalizeRange(start, stop int64, length int) (int, int, bool) {
+	size := int64(length)
+
+	if start < 0 {
+		start += size
+	}
+
+	if stop < 0 {
+		stop += size
+	}
+
+	if start < 0 {
+		start = 0
+	}
+
+	if stop >= size {
+		stop = size - 1
+	}
+
+	if start > stop || start >= size {
+		return 0, 0, false
+	}
+
+	return int(start), int(stop), true
+}
+
+func bulkStrings(values []string) []interface{} {
+	replies := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		replies = append(replies, value)
+	}
+
+	return replies
+}
@@ -0,0 +1,89 @@
+package gredistest
+
+import (
+	"net"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+)
+
+/* ================================================================================
+ * In-memory fake
+ * 用于单元测试的纯内存 Redis，通过内存连接驱动真实的 gredis 客户端，
+ * 因此 IRedis 的全部方法（含钩子、管道事务）行为与连接真实服务器一致
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 内存 Redis 选项
+	 * Clock: 过期时间使用的时钟，为nil时使用停留在当前时间的可控时钟
+	 * Password: 设置后需 AUTH 认证
+	 * Seed: SPOP | SRANDMEMBER 等随机命令的种子
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	FakeOption struct {
+		Clock    *Clock
+		Password string
+		Seed     int64
+	}
+
+	Fake struct {
+		engine *engine
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取内存 Redis
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewFake(args ...FakeOption) *Fake {
+	option := FakeOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	if option.Clock == nil {
+		option.Clock = NewClock(time.Now())
+	}
+
+	return &Fake{
+		engine: newEngine(option.Clock, option.Password, option.Seed),
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取连接到内存 Redis 的客户端，Ip | Port | NetDial 选项被忽略
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (f *Fake) NewRedis(args ...gredis.RedisOption) gredis.IRedis {
+	option := gredis.RedisOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	option.NetDial = f.dial
+
+	return gredis.NewRedisWithOption(option)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取时钟，用于推进时间以触发过期
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (f *Fake) Clock() *Clock {
+	return f.engine.clock
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 清空全部数据
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (f *Fake) FlushAll() {
+	f.engine.flushAll()
+}
+
+func (f *Fake) dial(network, address string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go f.engine.serve(server, nil)
+
+	return client, nil
+}
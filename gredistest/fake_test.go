@@ -0,0 +1,184 @@
+package gredistest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+)
+
+func newTestRedis(t *testing.T) (*Fake, gredis.IRedis) {
+	fake := NewFake()
+	redis := fake.NewRedis(gredis.RedisOption{Prefix: "test:"})
+	t.Cleanup(func() { redis.Close() })
+
+	return fake, redis
+}
+
+func TestStrings(t *testing.T) {
+	_, redis := newTestRedis(t)
+
+	if err := redis.Set("name", "gredis"); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := redis.Get("name")
+	if err != nil || string(value) != "gredis" {
+		t.Fatalf("Get = %q, %v", value, err)
+	}
+
+	if _, err := redis.Get("missing"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("Get missing err = %v, want ErrNotFound", err)
+	}
+
+	if count, err := redis.Incr("counter", 5); err != nil || count != 5 {
+		t.Errorf("Incr = %d, %v", count, err)
+	}
+
+	if _, err := redis.Incr("name"); err == nil {
+		t.Error("Incr on non-integer should fail")
+	}
+
+	if _, err := redis.LLen("name"); !errors.Is(err, gredis.ErrWrongType) {
+		t.Errorf("LLen on string err = %v, want ErrWrongType", err)
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	fake, redis := newTestRedis(t)
+
+	if err := redis.Set("session", "token", 60); err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl, _ := redis.Ttl("session"); ttl != 60 {
+		t.Errorf("Ttl = %d, want 60", ttl)
+	}
+
+	fake.Clock().Advance(59 * time.Second)
+	if isExists, _ := redis.Exists("session"); !isExists {
+		t.Fatal("session expired early")
+	}
+
+	fake.Clock().Advance(time.Second)
+	if isExists, _ := redis.Exists("session"); isExists {
+		t.Fatal("session should be expired")
+	}
+}
+
+func TestCollections(t *testing.T) {
+	_, redis := newTestRedis(t)
+
+	redis.RPush("list", "a", "b", "c")
+	if values, _ := redis.LRange("list", 0, -1); !reflect.DeepEqual(values, []string{"a", "b", "c"}) {
+		t.Errorf("LRange = %v", values)
+	}
+
+	redis.HMSet("hash", "f1", "v1", "f2", "v2")
+	if values, _ := redis.HMGet("hash", "f2", "f1"); !reflect.DeepEqual(values, []string{"v2", "v1"}) {
+		t.Errorf("HMGet = %v", values)
+	}
+
+	redis.SAdd("set1", "a", "b", "c")
+	redis.SAdd("set2", "b", "c", "d")
+	if values, _ := redis.SInter("set1", "set2"); !reflect.DeepEqual(values, []string{"b", "c"}) {
+		t.Errorf("SInter = %v", values)
+	}
+
+	redis.ZAdd("zset", 3, "c", 1, "a", 2, "b")
+	if values, _ := redis.ZRevRange("zset", 0, 1); !reflect.DeepEqual(values, []string{"c", "b"}) {
+		t.Errorf("ZRevRange = %v", values)
+	}
+
+	if values, _ := redis.ZRangeByScore("zset", "(1", "+inf"); !reflect.DeepEqual(values, []string{"b", "c"}) {
+		t.Errorf("ZRangeByScore = %v", values)
+	}
+}
+
+func TestPipelineWatch(t *testing.T) {
+	fake, redis := newTestRedis(t)
+	other := fake.NewRedis(gredis.RedisOption{Prefix: "test:"})
+	defer other.Close()
+
+	commands := []map[string][]interface{}{
+		{"SET": {"test:a", "1"}},
+		{"INCR": {"test:a"}},
+	}
+
+	reply, err := redis.Pipeline(commands)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if replies := reply.([]interface{}); replies[1] != int64(2) {
+		t.Errorf("EXEC replies = %v", replies)
+	}
+
+	if value, _ := other.Get("a"); string(value) != "2" {
+		t.Errorf("Get after pipeline = %q", value)
+	}
+}
+
+func TestWatchAbort(t *testing.T) {
+	fake := NewFake()
+	first, second := fake.engine.newSession(nil), fake.engine.newSession(nil)
+
+	command := func(s *session, args ...string) interface{} {
+		values := make([][]byte, 0, len(args))
+		for _, arg := range args {
+			values = append(values, []byte(arg))
+		}
+		return s.execute(values)
+	}
+
+	command(first, "WATCH", "key")
+	command(second, "SET", "key", "changed")
+	command(first, "MULTI")
+	command(first, "SET", "key", "mine")
+
+	if reply := command(first, "EXEC"); reply != (nullArray{}) {
+		t.Fatalf("EXEC = %v, want null array", reply)
+	}
+
+	if reply := command(first, "GET", "key"); string(reply.([]byte)) != "changed" {
+		t.Errorf("GET = %v", reply)
+	}
+}
+
+func TestScripting(t *testing.T) {
+	_, redis := newTestRedis(t)
+
+	script := gredis.NewScript(`
+redis.call('SET', KEYS[1], ARGV[1])
+return {redis.call('GET', KEYS[1]), redis.call('INCRBY', KEYS[2], ARGV[2]), redis.call('GET', 'missing')}
+`)
+
+	reply, err := redis.Eval(script, []string{"name", "counter"}, "gredis", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values := reply.([]interface{}); string(values[0].([]byte)) != "gredis" || values[1] != int64(5) || values[2] != nil {
+		t.Errorf("Eval = %#v", values)
+	}
+
+	wrongType := gredis.NewScript(`return redis.call('LLEN', KEYS[1])`)
+	if _, err := redis.Eval(wrongType, []string{"name"}); !errors.Is(err, gredis.ErrWrongType) {
+		t.Errorf("Eval err = %v, want ErrWrongType", err)
+	}
+
+	protected := gredis.NewScript(`
+local reply = redis.pcall('LLEN', KEYS[1])
+if type(reply) == 'table' and reply.err then
+	return redis.status_reply('CAUGHT')
+end
+return reply
+`)
+	if reply, err := redis.Eval(protected, []string{"name"}); err != nil || reply != "CAUGHT" {
+		t.Errorf("Eval pcall = %#v, %v", reply, err)
+	}
+}
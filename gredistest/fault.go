@@ -0,0 +1,103 @@
+package gredistest
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ================================================================================
+ * Fault injection
+ * 为测试服务器注入延迟、断开连接及错误回复
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	faults struct {
+		mutex    sync.Mutex
+		latency  time.Duration
+		latencys map[string]time.Duration
+		rules    []*faultRule
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 故障规则：命中 command（空字符串匹配任意命令）的后续 count 次请求
+	 * isDisconnect 为 true 时不执行命令并断开连接，否则回复 reply 错误
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	faultRule struct {
+		command      string
+		count        int
+		reply        errorReply
+		isDisconnect bool
+	}
+)
+
+func newFaults() *faults {
+	return &faults{
+		latencys: make(map[string]time.Duration),
+	}
+}
+
+func (f *faults) setLatency(command string, latency time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(command) == 0 {
+		f.latency = latency
+		return
+	}
+
+	f.latencys[strings.ToUpper(command)] = latency
+}
+
+func (f *faults) add(rule *faultRule) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	rule.command = strings.ToUpper(rule.command)
+	f.rules = append(f.rules, rule)
+}
+
+func (f *faults) reset() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.latency = 0
+	f.latencys = make(map[string]time.Duration)
+	f.rules = nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取命令的延迟及命中的故障规则
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (f *faults) match(args [][]byte) (time.Duration, *faultRule) {
+	if f == nil {
+		return 0, nil
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	name := strings.ToUpper(string(args[0]))
+
+	latency := f.latency
+	if commandLatency, isOk := f.latencys[name]; isOk {
+		latency = commandLatency
+	}
+
+	for index, rule := range f.rules {
+		if len(rule.command) > 0 && rule.command != name {
+			continue
+		}
+
+		rule.count--
+		if rule.count <= 0 {
+			f.rules = append(f.rules[:index], f.rules[index+1:]...)
+		}
+
+		return latency, rule
+	}
+
+	return latency, nil
+}
@@ -0,0 +1,489 @@
+package gredistest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+/* ================================================================================
+ * Geo commands
+ * 与 Redis 一致，坐标按 52 位 geohash 存为有序集合分数，距离使用 haversine 公式
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	geoStep        uint    = 26
+	geoLatMin      float64 = -85.05112878
+	geoLatMax      float64 = 85.05112878
+	geoLonMin      float64 = -180
+	geoLonMax      float64 = 180
+	geoEarthRadius float64 = 6372797.560856
+	geoAlphabet    string  = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * GEOSEARCH 匹配结果
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	geoPoint struct {
+		member    string
+		score     float64
+		longitude float64
+		latitude  float64
+		distance  float64
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * GEOSEARCH 查询参数，width | height 为0时按半径查询，距离单位均为米
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	geoQuery struct {
+		longitude   float64
+		latitude    float64
+		radius      float64
+		width       float64
+		height      float64
+		unit        float64
+		isDesc      bool
+		isSort      bool
+		count       int64
+		isAny       bool
+		isWithCoord bool
+		isWithDist  bool
+		isWithHash  bool
+		isStoreDist bool
+	}
+)
+
+func init() {
+	register("GEOADD", -5, geoAdd)
+	register("GEOPOS", -2, geoPos)
+	register("GEODIST", -4, geoDist)
+	register("GEOHASH", -2, geoHash)
+	register("GEOSEARCH", -7, geoSearch(false))
+	register("GEOSEARCHSTORE", -8, geoSearch(true))
+}
+
+func geoInterleave(lat, lon uint64) uint64 {
+	bits := uint64(0)
+	for index := uint(0); index < geoStep; index++ {
+		bits |= (lat >> index & 1) << (2 * index)
+		bits |= (lon >> index & 1) << (2*index + 1)
+	}
+
+	return bits
+}
+
+func geoEncode(longitude, latitude, latMin, latMax float64) uint64 {
+	scale := float64(uint64(1) << geoStep)
+	lat := uint64((latitude - latMin) / (latMax - latMin) * scale)
+	lon := uint64((longitude - geoLonMin) / (geoLonMax - geoLonMin) * scale)
+
+	return geoInterleave(lat, lon)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解码 geohash 为所在单元格的中心坐标
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func geoDecode(score float64) (float64, float64) {
+	bits := uint64(score)
+
+	lat, lon := uint64(0), uint64(0)
+	for index := uint(0); index < geoStep; index++ {
+		lat |= (bits >> (2 * index) & 1) << index
+		lon |= (bits >> (2*index + 1) & 1) << index
+	}
+
+	scale := float64(uint64(1) << geoStep)
+	latitude := geoLatMin + (float64(lat)+0.5)/scale*(geoLatMax-geoLatMin)
+	longitude := geoLonMin + (float64(lon)+0.5)/scale*(geoLonMax-geoLonMin)
+
+	return math.Max(geoLonMin, math.Min(geoLonMax, longitude)), math.Max(geoLatMin, math.Min(geoLatMax, latitude))
+}
+
+func geoDistance(lon1, lat1, lon2, lat2 float64) float64 {
+	lat1r, lat2r := lat1*math.Pi/180, lat2*math.Pi/180
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin((lon2 - lon1) * math.Pi / 180 / 2)
+
+	return 2 * geoEarthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1r)*math.Cos(lat2r)*v*v))
+}
+
+func parseGeoUnit(arg []byte) float64 {
+	switch strings.ToLower(string(arg)) {
+	case "m":
+		return 1
+	case "km":
+		return 1000
+	case "ft":
+		return 0.3048
+	case "mi":
+		return 1609.34
+	}
+
+	panic(errorReply("ERR unsupported unit provided. please use M, KM, FT, MI"))
+}
+
+func parseGeoPair(longitude, latitude []byte) (float64, float64) {
+	lon, lat := parseFloat(longitude), parseFloat(latitude)
+	if lon < geoLonMin || lon > geoLonMax || lat < geoLatMin || lat > geoLatMax {
+		panic(errorReply(fmt.Sprintf("ERR invalid longitude,latitude pair %f,%f", lon, lat)))
+	}
+
+	return lon, lat
+}
+
+func formatGeoDistance(distance float64) string {
+	return fmt.Sprintf("%.4f", distance)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * GEOADD key [NX | XX] [CH] longitude latitude member [longitude latitude member ...]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func geoAdd(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	isNx, isXx, isCh := false, false, false
+
+	index := 1
+	for isFlag := true; isFlag && index < len(args); index++ {
+		switch {
+		case isOption(args[index], "NX"):
+			isNx = true
+		case isOption(args[index], "XX"):
+			isXx = true
+		case isOption(args[index], "CH"):
+			isCh = true
+		default:
+			isFlag = false
+			index--
+		}
+	}
+
+	items := args[index:]
+	if len(items) == 0 || len(items)%3 != 0 {
+		panic(errSyntax)
+	}
+
+	if isNx && isXx {
+		panic(errorReply("ERR XX and NX options at the same time are not compatible"))
+	}
+
+	scores := make([]float64, 0, len(items)/3)
+	for i := 0; i < len(items); i += 3 {
+		longitude, latitude := parseGeoPair(items[i], items[i+1])
+		scores = append(scores, float64(geoEncode(longitude, latitude, geoLatMin, geoLatMax)))
+	}
+
+	zset := c.zsetValue(key, true)
+
+	added, changed := int64(0), int64(0)
+	for i := 0; i < len(items); i += 3 {
+		member, score := string(items[i+2]), scores[i/3]
+		current, isExists := zset[member]
+
+		if (isNx && isExists) || (isXx && !isExists) {
+			continue
+		}
+
+		zset[member] = score
+
+		if !isExists {
+			added++
+		} else if score != current {
+			changed++
+		}
+	}
+
+	c.modified(key, len(zset))
+
+	if isCh {
+		return added + changed
+	}
+
+	return added
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * GEOPOS key [member ...]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func geoPos(c *commandContext, args [][]byte) interface{} {
+	zset := c.zsetValue(string(args[0]), false)
+
+	replies := make([]interface{}, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		score, isExists := zset[string(arg)]
+		if !isExists {
+			replies = append(replies, nullArray{})
+			continue
+		}
+
+		longitude, latitude := geoDecode(score)
+		replies = append(replies, []interface{}{formatFloat(longitude), formatFloat(latitude)})
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * GEODIST key member1 member2 [M | KM | FT | MI]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func geoDist(c *commandContext, args [][]byte) interface{} {
+	if len(args) > 4 {
+		panic(errSyntax)
+	}
+
+	unit := float64(1)
+	if len(args) == 4 {
+		unit = parseGeoUnit(args[3])
+	}
+
+	zset := c.zsetValue(string(args[0]), false)
+
+	first, isFirst := zset[string(args[1])]
+	second, isSecond := zset[string(args[2])]
+	if !isFirst || !isSecond {
+		return nil
+	}
+
+	lon1, lat1 := geoDecode(first)
+	lon2, lat2 := geoDecode(second)
+
+	return formatGeoDistance(geoDistance(lon1, lat1, lon2, lat2) / unit)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * GEOHASH key [member ...]：返回 11 位标准 geohash 字符串
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func geoHash(c *commandContext, args [][]byte) interface{} {
+	zset := c.zsetValue(string(args[0]), false)
+
+	replies := make([]interface{}, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		score, isExists := zset[string(arg)]
+		if !isExists {
+			replies = append(replies, nil)
+			continue
+		}
+
+		longitude, latitude := geoDecode(score)
+		bits := geoEncode(longitude, latitude, -90, 90)
+
+		hash := make([]byte, 11)
+		for index := range hash {
+			position := 0
+			if index < 10 {
+				position = int(bits >> (52 - uint(index+1)*5) & 0x1f)
+			}
+			hash[index] = geoAlphabet[position]
+		}
+
+		replies = append(replies, string(hash))
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析 GEOSEARCH | GEOSEARCHSTORE 的查询参数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) parseGeoQuery(zset zsetValue, args [][]byte, isStore bool) geoQuery {
+	query := geoQuery{}
+	isFrom, isBy, isCount := false, false, false
+
+	for index := 0; index < len(args); index++ {
+		rest := len(args) - index - 1
+
+		switch {
+		case isOption(args[index], "FROMMEMBER") && rest >= 1 && !isFrom:
+			score, isExists := zset[string(args[index+1])]
+			if !isExists {
+				panic(errorReply("ERR could not decode requested zset member"))
+			}
+			query.longitude, query.latitude = geoDecode(score)
+			isFrom, index = true, index+1
+		case isOption(args[index], "FROMLONLAT") && rest >= 2 && !isFrom:
+			query.longitude, query.latitude = parseGeoPair(args[index+1], args[index+2])
+			isFrom, index = true, index+2
+		case isOption(args[index], "BYRADIUS") && rest >= 2 && !isBy:
+			query.unit = parseGeoUnit(args[index+2])
+			query.radius = parseFloat(args[index+1]) * query.unit
+			if query.radius < 0 {
+				panic(errorReply("ERR radius cannot be negative"))
+			}
+			isBy, index = true, index+2
+		case isOption(args[index], "BYBOX") && rest >= 3 && !isBy:
+			query.unit = parseGeoUnit(args[index+3])
+			query.width = parseFloat(args[index+1]) * query.unit
+			query.height = parseFloat(args[index+2]) * query.unit
+			if query.width < 0 || query.height < 0 {
+				panic(errorReply("ERR height or width cannot be negative"))
+			}
+			isBy, index = true, index+3
+		case isOption(args[index], "ASC"):
+			query.isSort, query.isDesc = true, false
+		case isOption(args[index], "DESC"):
+			query.isSort, query.isDesc = true, true
+		case isOption(args[index], "COUNT") && rest >= 1:
+			query.count = parseInt(args[index+1])
+			if query.count <= 0 {
+				panic(errorReply("ERR COUNT must be > 0"))
+			}
+			isCount, index = true, index+1
+		case isOption(args[index], "ANY"):
+			query.isAny = true
+		case isOption(args[index], "WITHCOORD") && !isStore:
+			query.isWithCoord = true
+		case isOption(args[index], "WITHDIST") && !isStore:
+			query.isWithDist = true
+		case isOption(args[index], "WITHHASH") && !isStore:
+			query.isWithHash = true
+		case isOption(args[index], "STOREDIST") && isStore:
+			query.isStoreDist = true
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	if !isFrom {
+		panic(errorReply("ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH"))
+	}
+
+	if !isBy {
+		panic(errorReply("ERR exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH"))
+	}
+
+	if query.isAny && !isCount {
+		panic(errorReply("ERR the ANY argument requires COUNT argument"))
+	}
+
+	// 与 Redis 一致，指定 COUNT 而未指定 ANY 及排序时按距离升序
+	if isCount && !query.isAny && !query.isSort {
+		query.isSort = true
+	}
+
+	return query
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 查找区域内的成员
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (q geoQuery) search(zset zsetValue) []geoPoint {
+	members := make([]string, 0, len(zset))
+	for member := range zset {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	points := make([]geoPoint, 0)
+	for _, member := range members {
+		score := zset[member]
+		longitude, latitude := geoDecode(score)
+		distance := geoDistance(q.longitude, q.latitude, longitude, latitude)
+
+		if q.width > 0 || q.height > 0 {
+			if geoEarthRadius*math.Abs(latitude-q.latitude)*math.Pi/180 > q.height/2 {
+				continue
+			}
+
+			if geoDistance(longitude, latitude, q.longitude, latitude) > q.width/2 {
+				continue
+			}
+		} else if distance > q.radius {
+			continue
+		}
+
+		points = append(points, geoPoint{
+			member:    member,
+			score:     score,
+			longitude: longitude,
+			latitude:  latitude,
+			distance:  distance,
+		})
+
+		if q.isAny && int64(len(points)) >= q.count {
+			break
+		}
+	}
+
+	if q.isSort {
+		sort.SliceStable(points, func(i, j int) bool {
+			if q.isDesc {
+				return points[i].distance > points[j].distance
+			}
+			return points[i].distance < points[j].distance
+		})
+	}
+
+	if q.count > 0 && int64(len(points)) > q.count {
+		points = points[:q.count]
+	}
+
+	return points
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * GEOSEARCH key FROMMEMBER member | FROMLONLAT longitude latitude
+ * BYRADIUS radius unit | BYBOX width height unit [ASC | DESC] [COUNT count [ANY]]
+ * [WITHCOORD] [WITHDIST] [WITHHASH]
+ * GEOSEARCHSTORE destination source ... [STOREDIST]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func geoSearch(isStore bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		destKey := ""
+		if isStore {
+			destKey, args = string(args[0]), args[1:]
+		}
+
+		zset := c.zsetValue(string(args[0]), false)
+		query := c.parseGeoQuery(zset, args[1:], isStore)
+		points := query.search(zset)
+
+		if isStore {
+			result := make(zsetValue, len(points))
+			for _, point := range points {
+				result[point.member] = point.score
+				if query.isStoreDist {
+					result[point.member] = point.distance / query.unit
+				}
+			}
+
+			if len(result) == 0 {
+				c.remove(destKey)
+			} else {
+				c.set(destKey, result)
+			}
+
+			return int64(len(result))
+		}
+
+		isPlain := !query.isWithDist && !query.isWithHash && !query.isWithCoord
+
+		replies := make([]interface{}, 0, len(points))
+		for _, point := range points {
+			if isPlain {
+				replies = append(replies, point.member)
+				continue
+			}
+
+			item := []interface{}{point.member}
+			if query.isWithDist {
+				item = append(item, formatGeoDistance(point.distance/query.unit))
+			}
+
+			if query.isWithHash {
+				item = append(item, int64(point.score))
+			}
+
+			if query.isWithCoord {
+				item = append(item, []interface{}{formatFloat(point.longitude), formatFloat(point.latitude)})
+			}
+
+			replies = append(replies, item)
+		}
+
+		return replies
+	}
+}
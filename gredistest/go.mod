@@ -0,0 +1,19 @@
+module github.com/sanxia/gredis/gredistest
+
+go 1.21
+
+replace github.com/sanxia/gredis => ../
+
+require (
+	github.com/sanxia/gredis v0.0.0-00010101000000-000000000000
+	github.com/yuin/gopher-lua v1.1.1
+)
+
+require (
+	github.com/bitly/go-simplejson v0.5.0 // indirect
+	github.com/boombuler/barcode v1.0.0 // indirect
+	github.com/garyburd/redigo v1.6.0 // indirect
+	github.com/mozillazg/request v0.8.0 // indirect
+	github.com/sanxia/glib v1.0.1 // indirect
+	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e // indirect
+)
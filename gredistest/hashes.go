@@ -0,0 +1,699 @@
+package gredistest
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+/* ================================================================================
+ * Hash commands
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+func init() {
+	register("HSET", -4, hSet(false))
+	register("HMSET", -4, hSet(true))
+	register("HSETNX", 4, hSetNx)
+	register("HGET", 3, hGet)
+	register("HMGET", -3, hMGet)
+	register("HGETALL", 2, hGetAll)
+	register("HKEYS", 2, hKeys)
+	register("HVALS", 2, hVals)
+	register("HINCRBY", 4, hIncrBy)
+	register("HINCRBYFLOAT", 4, hIncrByFloat)
+	register("HLEN", 2, hLen)
+	register("HSTRLEN", 3, hStrLen)
+	register("HEXISTS", 3, hExists)
+	register("HDEL", -3, hDel)
+	register("HRANDFIELD", -2, hRandField)
+	register("HSCAN", -3, hScan)
+	register("HEXPIRE", -6, hExpire(time.Second, false))
+	register("HPEXPIRE", -6, hExpire(time.Millisecond, false))
+	register("HEXPIREAT", -6, hExpire(time.Second, true))
+	register("HPEXPIREAT", -6, hExpire(time.Millisecond, true))
+	register("HTTL", -5, hTtl(time.Second))
+	register("HPTTL", -5, hTtl(time.Millisecond))
+	register("HPERSIST", -5, hPersist)
+	register("HGETDEL", -5, hGetDel)
+	register("HGETEX", -5, hGetEx)
+	register("HSETEX", -6, hSetEx)
+}
+
+func hSet(isStatus bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		if len(args)%2 != 1 {
+			if isStatus {
+				panic(errorReply("ERR wrong number of arguments for 'hmset' command"))
+			}
+			panic(errorReply("ERR wrong number of arguments for 'hset' command"))
+		}
+
+		key := string(args[0])
+		hash := c.hashValue(key, true)
+
+		added := int64(0)
+		for index := 1; index < len(args); index += 2 {
+			if hash.set(string(args[index]), args[index+1]) {
+				added++
+			}
+			hash.setExpire(string(args[index]), time.Time{})
+		}
+
+		c.modified(key, len(hash.fields))
+
+		if isStatus {
+			return statusReply("OK")
+		}
+
+		return added
+	}
+}
+
+func hSetNx(c *commandContext, args [][]byte) interface{} {
+	key, field := string(args[0]), string(args[1])
+	hash := c.hashValue(key, true)
+
+	if _, isExists := hash.values[field]; isExists {
+		return int64(0)
+	}
+
+	hash.set(field, args[2])
+	c.modified(key, len(hash.fields))
+
+	return int64(1)
+}
+
+func hGet(c *commandContext, args [][]byte) interface{} {
+	hash := c.hashValue(string(args[0]), false)
+	if hash == nil {
+		return nil
+	}
+
+	if value, isExists := hash.values[string(args[1])]; isExists {
+		return value
+	}
+
+	return nil
+}
+
+func hMGet(c *commandContext, args [][]byte) interface{} {
+	hash := c.hashValue(string(args[0]), false)
+
+	replies := make([]interface{}, 0, len(args)-1)
+	for _, field := range args[1:] {
+		if hash == nil {
+			replies = append(replies, nil)
+			continue
+		}
+
+		if value, isExists := hash.values[string(field)]; isExists {
+			replies = append(replies, value)
+		} else {
+			replies = append(replies, nil)
+		}
+	}
+
+	return replies
+}
+
+func hGetAll(c *commandContext, args [][]byte) interface{} {
+	hash := c.hashValue(string(args[0]), false)
+	if hash == nil {
+		return mapReply{}
+	}
+
+	replies := make(mapReply, 0, len(hash.fields)*2)
+	for _, field := range hash.fields {
+		replies = append(replies, field, hash.values[field])
+	}
+
+	return replies
+}
+
+func hKeys(c *commandContext, args [][]byte) interface{} {
+	hash := c.hashValue(string(args[0]), false)
+	if hash == nil {
+		return []interface{}{}
+	}
+
+	return bulkStrings(hash.fields)
+}
+
+func hVals(c *commandContext, args [][]byte) interface{} {
+	hash := c.hashValue(string(args[0]), false)
+	if hash == nil {
+		return []interface{}{}
+	}
+
+	replies := make([]interface{}, 0, len(hash.fields))
+	for _, field := range hash.fields {
+		replies = append(replies, hash.values[field])
+	}
+
+	return replies
+}
+
+func hIncrBy(c *commandContext, args [][]byte) interface{} {
+	key, field := string(args[0]), string(args[1])
+	step := parseInt(args[2])
+	hash := c.hashValue(key, true)
+
+	current := int64(0)
+	if value, isExists := hash.values[field]; isExists {
+		parsed, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			c.modified(key, len(hash.fields))
+			panic(errorReply("ERR hash value is not an integer"))
+		}
+		current = parsed
+	}
+
+	if (step > 0 && current > math.MaxInt64-step) || (step < 0 && current < math.MinInt64-step) {
+		c.modified(key, len(hash.fields))
+		panic(errIncrOverflow)
+	}
+
+	current += step
+	hash.set(field, []byte(strconv.FormatInt(current, 10)))
+	c.modified(key, len(hash.fields))
+
+	return current
+}
+
+func hIncrByFloat(c *commandContext, args [][]byte) interface{} {
+	key, field := string(args[0]), string(args[1])
+	step := parseFloat(args[2])
+	hash := c.hashValue(key, true)
+
+	current := float64(0)
+	if value, isExists := hash.values[field]; isExists {
+		parsed, err := parseFloatString(string(value))
+		if err != nil {
+			c.modified(key, len(hash.fields))
+			panic(errorReply("ERR hash value is not a float"))
+		}
+		current = parsed
+	}
+
+	current += step
+	if math.IsInf(current, 0) || math.IsNaN(current) {
+		c.modified(key, len(hash.fields))
+		panic(errorReply("ERR increment would produce NaN or Infinity"))
+	}
+
+	result := []byte(formatFloat(current))
+	hash.set(field, result)
+	c.modified(key, len(hash.fields))
+
+	return result
+}
+
+func hLen(c *commandContext, args [][]byte) interface{} {
+	hash := c.hashValue(string(args[0]), false)
+	if hash == nil {
+		return int64(0)
+	}
+
+	return int64(len(hash.fields))
+}
+
+func hStrLen(c *commandContext, args [][]byte) interface{} {
+	hash := c.hashValue(string(args[0]), false)
+	if hash == nil {
+		return int64(0)
+	}
+
+	return int64(len(hash.values[string(args[1])]))
+}
+
+func hExists(c *commandContext, args [][]byte) interface{} {
+	hash := c.hashValue(string(args[0]), false)
+	if hash == nil {
+		return int64(0)
+	}
+
+	if _, isExists := hash.values[string(args[1])]; isExists {
+		return int64(1)
+	}
+
+	return int64(0)
+}
+
+func hDel(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	hash := c.hashValue(key, false)
+	if hash == nil {
+		return int64(0)
+	}
+
+	removed := int64(0)
+	for _, field := range args[1:] {
+		if hash.remove(string(field)) {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		c.modified(key, len(hash.fields))
+	}
+
+	return removed
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HRANDFIELD key [count [WITHVALUES]]：count 为负数时允许重复
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hRandField(c *commandContext, args [][]byte) interface{} {
+	isWithValues := len(args) == 3
+	if len(args) > 3 || (isWithValues && !isOption(args[2], "WITHVALUES")) {
+		panic(errSyntax)
+	}
+
+	count, isCount := int64(1), len(args) >= 2
+	if isCount {
+		count = parseInt(args[1])
+	}
+
+	hash := c.hashValue(string(args[0]), false)
+	if hash == nil {
+		if isCount {
+			return []interface{}{}
+		}
+		return nil
+	}
+
+	isRepeat := count < 0
+	if isRepeat {
+		count = -count
+	}
+
+	candidates := make(setValue, len(hash.fields))
+	for _, field := range hash.fields {
+		candidates[field] = struct{}{}
+	}
+
+	fields := c.randomMembers(candidates, count, isRepeat)
+	if !isCount {
+		return fields[0]
+	}
+
+	replies := make([]interface{}, 0, len(fields)*2)
+	for _, field := range fields {
+		replies = append(replies, field)
+		if isWithValues {
+			replies = append(replies, hash.values[field])
+		}
+	}
+
+	if isWithValues {
+		return pairsReply(replies)
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HSCAN key cursor [MATCH pattern] [COUNT count] [NOVALUES]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hScan(c *commandContext, args [][]byte) interface{} {
+	isNoValues := false
+	if last := args[len(args)-1]; len(args) > 2 && isOption(last, "NOVALUES") {
+		isNoValues, args = true, args[:len(args)-1]
+	}
+
+	hash := c.hashValue(string(args[0]), false)
+
+	fields := make([]string, 0)
+	if hash != nil {
+		fields = append(fields, hash.fields...)
+	}
+
+	cursor, fields := scanNames(args[1:], fields)
+
+	replies := make([]interface{}, 0, len(fields)*2)
+	for _, field := range fields {
+		replies = append(replies, field)
+		if !isNoValues {
+			replies = append(replies, hash.values[field])
+		}
+	}
+
+	return []interface{}{strconv.FormatInt(cursor, 10), replies}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析 FIELDS numfields field [field ...]，isPair 为 true 时每个字段后跟一个值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func parseHashFields(args [][]byte, isPair bool) [][]byte {
+	if len(args) < 2 || !isOption(args[0], "FIELDS") {
+		panic(errorReply("ERR Mandatory argument FIELDS is missing or not at the right position"))
+	}
+
+	count := parseInt(args[1])
+	if count <= 0 {
+		panic(errorReply("ERR Parameter `numFields` should be greater than 0"))
+	}
+
+	width := int64(1)
+	if isPair {
+		width = 2
+	}
+
+	if int64(len(args)-2) != count*width {
+		panic(errorReply("ERR The `numfields` parameter must match the number of arguments"))
+	}
+
+	return args[2:]
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析 EX seconds | PX milliseconds | EXAT timestamp | PXAT timestamp 过期参数，
+ * 返回过期时间及消耗的参数个数，非过期参数时返回0
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func parseExpireOption(c *commandContext, args [][]byte) (time.Time, int) {
+	if len(args) < 2 {
+		return time.Time{}, 0
+	}
+
+	var unit time.Duration
+	isAt := false
+
+	switch {
+	case isOption(args[0], "EX"):
+		unit = time.Second
+	case isOption(args[0], "PX"):
+		unit = time.Millisecond
+	case isOption(args[0], "EXAT"):
+		unit, isAt = time.Second, true
+	case isOption(args[0], "PXAT"):
+		unit, isAt = time.Millisecond, true
+	default:
+		return time.Time{}, 0
+	}
+
+	value := parseInt(args[1])
+	if value <= 0 {
+		panic(errorReply("ERR invalid expire time"))
+	}
+
+	if isAt {
+		return time.Unix(0, 0).Add(time.Duration(value) * unit), 2
+	}
+
+	return c.now.Add(time.Duration(value) * unit), 2
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HEXPIRE | HPEXPIRE | HEXPIREAT | HPEXPIREAT key time [NX | XX | GT | LT] FIELDS numfields field [field ...]
+ * 每个字段返回：-2 字段不存在 | 0 条件不满足 | 1 已设置 | 2 过期时间已过，字段被删除
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hExpire(unit time.Duration, isAt bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		key := string(args[0])
+
+		value := parseInt(args[1])
+		if value < 0 {
+			panic(errorReply("ERR invalid expire time"))
+		}
+
+		expireAt := c.now.Add(time.Duration(value) * unit)
+		if isAt {
+			expireAt = time.Unix(0, 0).Add(time.Duration(value) * unit)
+		}
+
+		condition, rest := "", args[2:]
+		if len(rest) > 0 && !isOption(rest[0], "FIELDS") {
+			switch {
+			case isOption(rest[0], "NX"), isOption(rest[0], "XX"), isOption(rest[0], "GT"), isOption(rest[0], "LT"):
+				condition, rest = string(rest[0]), rest[1:]
+			default:
+				panic(errSyntax)
+			}
+		}
+
+		fields := parseHashFields(rest, false)
+		hash := c.hashValue(key, false)
+
+		replies := make([]interface{}, 0, len(fields))
+		for _, arg := range fields {
+			field := string(arg)
+
+			if hash == nil {
+				replies = append(replies, int64(-2))
+				continue
+			}
+
+			if _, isExists := hash.values[field]; !isExists {
+				replies = append(replies, int64(-2))
+				continue
+			}
+
+			current, hasTtl := hash.expires[field]
+
+			isSkip := false
+			switch {
+			case isOption([]byte(condition), "NX"):
+				isSkip = hasTtl
+			case isOption([]byte(condition), "XX"):
+				isSkip = !hasTtl
+			case isOption([]byte(condition), "GT"):
+				isSkip = !hasTtl || !expireAt.After(current)
+			case isOption([]byte(condition), "LT"):
+				isSkip = hasTtl && !expireAt.Before(current)
+			}
+
+			if isSkip {
+				replies = append(replies, int64(0))
+				continue
+			}
+
+			if !expireAt.After(c.now) {
+				hash.remove(field)
+				replies = append(replies, int64(2))
+				continue
+			}
+
+			hash.setExpire(field, expireAt)
+			replies = append(replies, int64(1))
+		}
+
+		if hash != nil {
+			c.modified(key, len(hash.fields))
+		}
+
+		return replies
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HTTL | HPTTL key FIELDS numfields field [field ...]
+ * 每个字段返回：-2 字段不存在 | -1 未设置过期时间 | 剩余时间
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hTtl(unit time.Duration) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		fields := parseHashFields(args[1:], false)
+		hash := c.hashValue(string(args[0]), false)
+
+		replies := make([]interface{}, 0, len(fields))
+		for _, arg := range fields {
+			field := string(arg)
+
+			if hash == nil {
+				replies = append(replies, int64(-2))
+				continue
+			}
+
+			if _, isExists := hash.values[field]; !isExists {
+				replies = append(replies, int64(-2))
+				continue
+			}
+
+			expireAt, hasTtl := hash.expires[field]
+			if !hasTtl {
+				replies = append(replies, int64(-1))
+				continue
+			}
+
+			replies = append(replies, int64((expireAt.Sub(c.now)+unit/2)/unit))
+		}
+
+		return replies
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HPERSIST key FIELDS numfields field [field ...]
+ * 每个字段返回：-2 字段不存在 | -1 未设置过期时间 | 1 已移除过期时间
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hPersist(c *commandContext, args [][]byte) interface{} {
+	fields := parseHashFields(args[1:], false)
+	hash := c.hashValue(string(args[0]), false)
+
+	replies := make([]interface{}, 0, len(fields))
+	for _, arg := range fields {
+		field := string(arg)
+
+		if hash == nil {
+			replies = append(replies, int64(-2))
+			continue
+		}
+
+		if _, isExists := hash.values[field]; !isExists {
+			replies = append(replies, int64(-2))
+			continue
+		}
+
+		if _, hasTtl := hash.expires[field]; !hasTtl {
+			replies = append(replies, int64(-1))
+			continue
+		}
+
+		hash.setExpire(field, time.Time{})
+		replies = append(replies, int64(1))
+	}
+
+	if hash != nil {
+		c.modified(string(args[0]), len(hash.fields))
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HGETDEL key FIELDS numfields field [field ...]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hGetDel(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	fields := parseHashFields(args[1:], false)
+	hash := c.hashValue(key, false)
+
+	replies := make([]interface{}, 0, len(fields))
+	for _, arg := range fields {
+		if hash == nil {
+			replies = append(replies, nil)
+			continue
+		}
+
+		value, isExists := hash.values[string(arg)]
+		if !isExists {
+			replies = append(replies, nil)
+			continue
+		}
+
+		hash.remove(string(arg))
+		replies = append(replies, value)
+	}
+
+	if hash != nil {
+		c.modified(key, len(hash.fields))
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HGETEX key [EX seconds | PX milliseconds | EXAT timestamp | PXAT timestamp | PERSIST]
+ * FIELDS numfields field [field ...]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hGetEx(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	rest := args[1:]
+
+	expireAt, consumed := parseExpireOption(c, rest)
+	isPersist := consumed == 0 && len(rest) > 0 && isOption(rest[0], "PERSIST")
+	if isPersist {
+		consumed = 1
+	}
+
+	fields := parseHashFields(rest[consumed:], false)
+	hash := c.hashValue(key, false)
+
+	replies := make([]interface{}, 0, len(fields))
+	for _, arg := range fields {
+		field := string(arg)
+
+		if hash == nil {
+			replies = append(replies, nil)
+			continue
+		}
+
+		value, isExists := hash.values[field]
+		if !isExists {
+			replies = append(replies, nil)
+			continue
+		}
+
+		replies = append(replies, value)
+
+		switch {
+		case isPersist:
+			hash.setExpire(field, time.Time{})
+		case consumed > 0 && !expireAt.After(c.now):
+			hash.remove(field)
+		case consumed > 0:
+			hash.setExpire(field, expireAt)
+		}
+	}
+
+	if hash != nil && consumed > 0 {
+		c.modified(key, len(hash.fields))
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HSETEX key [FNX | FXX] [EX seconds | PX milliseconds | EXAT timestamp | PXAT timestamp | KEEPTTL]
+ * FIELDS numfields field value [field value ...]
+ * FNX 时仅当全部字段都不存在、FXX 时仅当全部字段都存在才设置，返回是否已设置
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func hSetEx(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	rest := args[1:]
+
+	condition := ""
+	if len(rest) > 0 && (isOption(rest[0], "FNX") || isOption(rest[0], "FXX")) {
+		condition, rest = string(rest[0]), rest[1:]
+	}
+
+	expireAt, consumed := parseExpireOption(c, rest)
+	isKeepTtl := consumed == 0 && len(rest) > 0 && isOption(rest[0], "KEEPTTL")
+	if isKeepTtl {
+		consumed = 1
+	}
+
+	pairs := parseHashFields(rest[consumed:], true)
+	hash := c.hashValue(key, false)
+
+	for index := 0; index < len(pairs); index += 2 {
+		isExists := false
+		if hash != nil {
+			_, isExists = hash.values[string(pairs[index])]
+		}
+
+		if (isOption([]byte(condition), "FNX") && isExists) || (isOption([]byte(condition), "FXX") && !isExists) {
+			return int64(0)
+		}
+	}
+
+	hash = c.hashValue(key, true)
+	for index := 0; index < len(pairs); index += 2 {
+		field := string(pairs[index])
+		hash.set(field, pairs[index+1])
+
+		switch {
+		case isKeepTtl:
+		case consumed > 0 && !expireAt.After(c.now):
+			hash.remove(field)
+		default:
+			hash.setExpire(field, expireAt)
+		}
+	}
+
+	c.modified(key, len(hash.fields))
+
+	return int64(1)
+}
@@ -0,0 +1,136 @@
+package gredistest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+)
+
+/* ================================================================================
+ * HyperLogLog commands
+ * 假服务器按精确集合计数，以 "HYLL" 开头的字符串存储（元素按长度前缀编码），
+ * 因此 TYPE 为 string 且 GET 可读取，与真实 Redis 的表现一致
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	hllMagic string = "HYLL"
+)
+
+func init() {
+	register("PFADD", -2, pfAdd)
+	register("PFCOUNT", -2, pfCount)
+	register("PFMERGE", -2, pfMerge)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 读取 HyperLogLog，键不存在时返回 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) hllValue(key string) setValue {
+	value, isExists := c.stringValue(key)
+	if !isExists {
+		return nil
+	}
+
+	if !bytes.HasPrefix(value, []byte(hllMagic)) {
+		panic(errNotHll)
+	}
+
+	elements := make(setValue)
+	for data := value[len(hllMagic):]; len(data) > 0; {
+		size, count := binary.Uvarint(data)
+		if count <= 0 || uint64(len(data)-count) < size {
+			panic(errorReply("INVALIDOBJ Corrupted HLL object detected"))
+		}
+
+		data = data[count:]
+		elements[string(data[:size])] = struct{}{}
+		data = data[size:]
+	}
+
+	return elements
+}
+
+func (c *commandContext) storeHll(key string, elements setValue, isExists bool) {
+	names := make([]string, 0, len(elements))
+	for element := range elements {
+		names = append(names, element)
+	}
+	sort.Strings(names)
+
+	data := []byte(hllMagic)
+	for _, name := range names {
+		data = binary.AppendUvarint(data, uint64(len(name)))
+		data = append(data, name...)
+	}
+
+	if isExists {
+		c.put(key, data)
+	} else {
+		c.set(key, data)
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * PFADD key [element ...]：返回1表示创建了键或计数发生变化
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func pfAdd(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	elements := c.hllValue(key)
+	isExists := elements != nil
+	if !isExists {
+		elements = make(setValue)
+	}
+
+	size := len(elements)
+	for _, arg := range args[1:] {
+		elements[string(arg)] = struct{}{}
+	}
+
+	if isExists && len(elements) == size {
+		return int64(0)
+	}
+
+	c.storeHll(key, elements, isExists)
+
+	return int64(1)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * PFCOUNT key [key ...]：多个键时返回并集的基数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func pfCount(c *commandContext, args [][]byte) interface{} {
+	union := make(setValue)
+	for _, arg := range args {
+		for element := range c.hllValue(string(arg)) {
+			union[element] = struct{}{}
+		}
+	}
+
+	return int64(len(union))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * PFMERGE destkey [sourcekey ...]：目标键已存在时一并合并
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func pfMerge(c *commandContext, args [][]byte) interface{} {
+	destKey := string(args[0])
+
+	union := c.hllValue(destKey)
+	isExists := union != nil
+	if !isExists {
+		union = make(setValue)
+	}
+
+	for _, arg := range args[1:] {
+		for element := range c.hllValue(string(arg)) {
+			union[element] = struct{}{}
+		}
+	}
+
+	c.storeHll(destKey, union, isExists)
+
+	return statusReply("OK")
+}
@@ -0,0 +1,790 @@
+package gredistest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* ================================================================================
+ * RedisJSON commands
+ * 支持 JSON.SET | JSON.GET | JSON.MGET | JSON.DEL | JSON.NUMINCRBY | JSON.ARRAPPEND，
+ * 路径支持 $ 开头的 JSONPath（成员、下标、通配符、递归下降）及旧式路径（. 开头）
+ * JSONPath 返回全部匹配的数组，旧式路径返回第一个匹配
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	jsonSegmentChild int = iota
+	jsonSegmentIndex
+	jsonSegmentWildcard
+	jsonSegmentRecursive
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * JSON 文档，节点为 nil | bool | json.Number | string | *jsonArray | *jsonObject
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	jsonValue struct {
+		root interface{}
+	}
+
+	jsonArray struct {
+		items []interface{}
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * JSON 对象，与 RedisJSON 一致保留成员的插入顺序
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	jsonObject struct {
+		keys   []string
+		values map[string]interface{}
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 路径片段，递归下降片段的 inner 为其后的成员 | 下标 | 通配符片段
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	jsonSegment struct {
+		kind  int
+		name  string
+		index int
+		inner *jsonSegment
+	}
+
+	jsonPath struct {
+		text     string
+		isLegacy bool
+		segments []jsonSegment
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 路径匹配结果，parent 为 nil 时为文档根节点
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	jsonMatch struct {
+		parent interface{}
+		name   string
+		index  int
+		value  interface{}
+	}
+)
+
+func init() {
+	register("JSON.SET", -4, jsonSet)
+	register("JSON.GET", -2, jsonGet)
+	register("JSON.MGET", -3, jsonMGet)
+	register("JSON.DEL", -2, jsonDel)
+	register("JSON.NUMINCRBY", 4, jsonNumIncrBy)
+	register("JSON.ARRAPPEND", -4, jsonArrAppend)
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: make(map[string]interface{})}
+}
+
+func (o *jsonObject) set(key string, value interface{}) {
+	if _, isExists := o.values[key]; !isExists {
+		o.keys = append(o.keys, key)
+	}
+
+	o.values[key] = value
+}
+
+func (o *jsonObject) remove(key string) {
+	delete(o.values, key)
+
+	for index, name := range o.keys {
+		if name == key {
+			o.keys = append(o.keys[:index], o.keys[index+1:]...)
+			return
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 读取 JSON 文档，键不存在时返回 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) jsonValue(key string) *jsonValue {
+	item := c.lookup(key)
+	if item == nil {
+		return nil
+	}
+
+	value, isOk := item.value.(*jsonValue)
+	if !isOk {
+		panic(errWrongType)
+	}
+
+	return value
+}
+
+func parseJSON(data []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	value, err := decodeJSON(decoder)
+	if err == nil {
+		if _, err = decoder.Token(); err == io.EOF {
+			return value
+		}
+	}
+
+	panic(errorReply("ERR expected value at line 1 column 1"))
+}
+
+func decodeJSON(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := newJSONObject()
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			object.set(name.(string), value)
+		}
+
+		_, err := decoder.Token()
+		return object, err
+	case json.Delim('['):
+		array := &jsonArray{items: make([]interface{}, 0)}
+		for decoder.More() {
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			array.items = append(array.items, value)
+		}
+
+		_, err := decoder.Token()
+		return array, err
+	}
+
+	return token, nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 紧凑格式序列化，与 RedisJSON 默认输出一致
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func formatJSON(value interface{}) []byte {
+	buffer := &bytes.Buffer{}
+	writeJSON(buffer, value)
+
+	return buffer.Bytes()
+}
+
+func writeJSON(buffer *bytes.Buffer, value interface{}) {
+	switch data := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool:
+		buffer.WriteString(strconv.FormatBool(data))
+	case json.Number:
+		buffer.WriteString(string(data))
+	case string:
+		writeJSONString(buffer, data)
+	case *jsonArray:
+		buffer.WriteByte('[')
+		for index, item := range data.items {
+			if index > 0 {
+				buffer.WriteByte(',')
+			}
+			writeJSON(buffer, item)
+		}
+		buffer.WriteByte(']')
+	case *jsonObject:
+		buffer.WriteByte('{')
+		for index, key := range data.keys {
+			if index > 0 {
+				buffer.WriteByte(',')
+			}
+			writeJSONString(buffer, key)
+			buffer.WriteByte(':')
+			writeJSON(buffer, data.values[key])
+		}
+		buffer.WriteByte('}')
+	}
+}
+
+func writeJSONString(buffer *bytes.Buffer, value string) {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+
+	// Encode 会追加换行
+	buffer.Truncate(buffer.Len() - 1)
+}
+
+func cloneJSON(value interface{}) interface{} {
+	switch data := value.(type) {
+	case *jsonArray:
+		array := &jsonArray{items: make([]interface{}, 0, len(data.items))}
+		for _, item := range data.items {
+			array.items = append(array.items, cloneJSON(item))
+		}
+		return array
+	case *jsonObject:
+		object := newJSONObject()
+		for _, key := range data.keys {
+			object.set(key, cloneJSON(data.values[key]))
+		}
+		return object
+	}
+
+	return value
+}
+
+func jsonTypeName(value interface{}) string {
+	switch data := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if isJSONInteger(data) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case *jsonArray:
+		return "array"
+	}
+
+	return "object"
+}
+
+func isJSONInteger(value json.Number) bool {
+	return !strings.ContainsAny(string(value), ".eE")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 数值相加，均为整数且不溢出时结果为整数，否则为浮点数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func addJSONNumber(current, increment json.Number) json.Number {
+	if isJSONInteger(current) && isJSONInteger(increment) {
+		first, firstErr := current.Int64()
+		second, secondErr := increment.Int64()
+		if firstErr == nil && secondErr == nil && !((second > 0 && first > math.MaxInt64-second) || (second < 0 && first < math.MinInt64-second)) {
+			return json.Number(strconv.FormatInt(first+second, 10))
+		}
+	}
+
+	first, _ := current.Float64()
+	second, _ := increment.Float64()
+
+	result := strconv.FormatFloat(first+second, 'f', -1, 64)
+	if !strings.Contains(result, ".") {
+		result += ".0"
+	}
+
+	return json.Number(result)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析路径：$ 开头为 JSONPath，否则为旧式路径（. 表示根节点，可省略开头的 .）
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func parseJSONPath(text string) jsonPath {
+	path := jsonPath{text: text}
+
+	rest := text
+	if strings.HasPrefix(rest, "$") {
+		rest = rest[1:]
+	} else {
+		path.isLegacy = true
+		if rest == "." {
+			rest = ""
+		} else if !strings.HasPrefix(rest, ".") && !strings.HasPrefix(rest, "[") {
+			rest = "." + rest
+		}
+	}
+
+	for len(rest) > 0 {
+		var segment jsonSegment
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			var inner jsonSegment
+			if strings.HasPrefix(rest[2:], "[") {
+				inner, rest = parseJSONBracket(text, rest[2:])
+			} else {
+				inner, rest = parseJSONName(text, rest[2:])
+			}
+			segment = jsonSegment{kind: jsonSegmentRecursive, inner: &inner}
+		case rest[0] == '.':
+			segment, rest = parseJSONName(text, rest[1:])
+		case rest[0] == '[':
+			segment, rest = parseJSONBracket(text, rest)
+		default:
+			panic(errorReply("ERR invalid JSONPath '" + text + "'"))
+		}
+
+		path.segments = append(path.segments, segment)
+	}
+
+	return path
+}
+
+func parseJSONName(text, rest string) (jsonSegment, string) {
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		end = len(rest)
+	}
+
+	name := rest[:end]
+	if len(name) == 0 {
+		panic(errorReply("ERR invalid JSONPath '" + text + "'"))
+	}
+
+	if name == "*" {
+		return jsonSegment{kind: jsonSegmentWildcard}, rest[end:]
+	}
+
+	return jsonSegment{kind: jsonSegmentChild, name: name}, rest[end:]
+}
+
+func parseJSONBracket(text, rest string) (jsonSegment, string) {
+	end := strings.Index(rest, "]")
+	if end < 0 {
+		panic(errorReply("ERR invalid JSONPath '" + text + "'"))
+	}
+
+	content := strings.TrimSpace(rest[1:end])
+	rest = rest[end+1:]
+
+	if content == "*" {
+		return jsonSegment{kind: jsonSegmentWildcard}, rest
+	}
+
+	if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+		return jsonSegment{kind: jsonSegmentChild, name: content[1 : len(content)-1]}, rest
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		panic(errorReply("ERR invalid JSONPath '" + text + "'"))
+	}
+
+	return jsonSegment{kind: jsonSegmentIndex, index: index}, rest
+}
+
+func (p jsonPath) isRoot() bool {
+	return len(p.segments) == 0
+}
+
+func (p jsonPath) match(root interface{}) []jsonMatch {
+	return matchJSONSegments(p.segments, []jsonMatch{{value: root}})
+}
+
+func matchJSONSegments(segments []jsonSegment, matches []jsonMatch) []jsonMatch {
+	for _, segment := range segments {
+		next := make([]jsonMatch, 0)
+		for _, match := range matches {
+			next = append(next, segment.apply(match.value)...)
+		}
+		matches = next
+	}
+
+	return matches
+}
+
+func (s jsonSegment) apply(value interface{}) []jsonMatch {
+	matches := make([]jsonMatch, 0)
+
+	switch s.kind {
+	case jsonSegmentChild:
+		if object, isOk := value.(*jsonObject); isOk {
+			if item, isExists := object.values[s.name]; isExists {
+				matches = append(matches, jsonMatch{parent: object, name: s.name, value: item})
+			}
+		}
+	case jsonSegmentIndex:
+		if array, isOk := value.(*jsonArray); isOk {
+			index := s.index
+			if index < 0 {
+				index += len(array.items)
+			}
+
+			if index >= 0 && index < len(array.items) {
+				matches = append(matches, jsonMatch{parent: array, index: index, value: array.items[index]})
+			}
+		}
+	case jsonSegmentWildcard:
+		switch container := value.(type) {
+		case *jsonObject:
+			for _, key := range container.keys {
+				matches = append(matches, jsonMatch{parent: container, name: key, value: container.values[key]})
+			}
+		case *jsonArray:
+			for index, item := range container.items {
+				matches = append(matches, jsonMatch{parent: container, index: index, value: item})
+			}
+		}
+	case jsonSegmentRecursive:
+		matches = append(matches, s.inner.apply(value)...)
+
+		switch container := value.(type) {
+		case *jsonObject:
+			for _, key := range container.keys {
+				matches = append(matches, s.apply(container.values[key])...)
+			}
+		case *jsonArray:
+			for _, item := range container.items {
+				matches = append(matches, s.apply(item)...)
+			}
+		}
+	}
+
+	return matches
+}
+
+func (v *jsonValue) replace(match jsonMatch, value interface{}) {
+	switch parent := match.parent.(type) {
+	case nil:
+		v.root = value
+	case *jsonObject:
+		parent.set(match.name, value)
+	case *jsonArray:
+		parent.items[match.index] = value
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取路径的查询结果，JSONPath 为全部匹配的数组，旧式路径为第一个匹配
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p jsonPath) result(root interface{}, isLegacy bool) interface{} {
+	matches := p.match(root)
+
+	if !isLegacy {
+		array := &jsonArray{items: make([]interface{}, 0, len(matches))}
+		for _, match := range matches {
+			array.items = append(array.items, match.value)
+		}
+		return array
+	}
+
+	if len(matches) == 0 {
+		panic(errorReply(fmt.Sprintf("ERR Path '%s' does not exist", p.text)))
+	}
+
+	return matches[0].value
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * JSON.SET key path value [NX | XX]
+ * 路径不存在且最后一段为成员名时，在已存在的父对象中新增成员
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func jsonSet(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	path := parseJSONPath(string(args[1]))
+	value := parseJSON(args[2])
+
+	isNx, isXx := false, false
+	for _, arg := range args[3:] {
+		switch {
+		case isOption(arg, "NX") && !isXx:
+			isNx = true
+		case isOption(arg, "XX") && !isNx:
+			isXx = true
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	document := c.jsonValue(key)
+	if document == nil {
+		if !path.isRoot() {
+			panic(errorReply("ERR new objects must be created at the root"))
+		}
+
+		if isXx {
+			return nil
+		}
+
+		c.set(key, &jsonValue{root: value})
+		return statusReply("OK")
+	}
+
+	if matches := path.match(document.root); len(matches) > 0 {
+		if isNx {
+			return nil
+		}
+
+		for _, match := range matches {
+			document.replace(match, cloneJSON(value))
+		}
+
+		c.db.touch(key)
+		return statusReply("OK")
+	}
+
+	if isXx {
+		return nil
+	}
+
+	last := path.segments[len(path.segments)-1]
+	if last.kind != jsonSegmentChild {
+		return nil
+	}
+
+	count := 0
+	for _, parent := range matchJSONSegments(path.segments[:len(path.segments)-1], []jsonMatch{{value: document.root}}) {
+		if object, isOk := parent.value.(*jsonObject); isOk {
+			object.set(last.name, cloneJSON(value))
+			count++
+		}
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	c.db.touch(key)
+
+	return statusReply("OK")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * JSON.GET key [INDENT indent] [NEWLINE newline] [SPACE space] [path ...]
+ * 格式化参数被忽略，始终以紧凑格式返回；多个路径时返回以路径为键的对象
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func jsonGet(c *commandContext, args [][]byte) interface{} {
+	paths := make([]jsonPath, 0, len(args)-1)
+	for index := 1; index < len(args); index++ {
+		if isOption(args[index], "INDENT") || isOption(args[index], "NEWLINE") || isOption(args[index], "SPACE") {
+			index++
+			continue
+		}
+
+		paths = append(paths, parseJSONPath(string(args[index])))
+	}
+
+	document := c.jsonValue(string(args[0]))
+	if document == nil {
+		return nil
+	}
+
+	if len(paths) == 0 {
+		paths = append(paths, parseJSONPath("."))
+	}
+
+	if len(paths) == 1 {
+		return formatJSON(paths[0].result(document.root, paths[0].isLegacy))
+	}
+
+	// 任一路径为 JSONPath 时均按 JSONPath 返回
+	isLegacy := true
+	for _, path := range paths {
+		isLegacy = isLegacy && path.isLegacy
+	}
+
+	object := newJSONObject()
+	for _, path := range paths {
+		object.set(path.text, path.result(document.root, isLegacy))
+	}
+
+	return formatJSON(object)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * JSON.MGET key [key ...] path：键不存在、非 JSON 或旧式路径不存在时对应 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func jsonMGet(c *commandContext, args [][]byte) interface{} {
+	path := parseJSONPath(string(args[len(args)-1]))
+
+	replies := make([]interface{}, 0, len(args)-1)
+	for _, arg := range args[:len(args)-1] {
+		item := c.lookup(string(arg))
+		if item == nil {
+			replies = append(replies, nil)
+			continue
+		}
+
+		document, isOk := item.value.(*jsonValue)
+		if !isOk {
+			replies = append(replies, nil)
+			continue
+		}
+
+		if path.isLegacy && len(path.match(document.root)) == 0 {
+			replies = append(replies, nil)
+			continue
+		}
+
+		replies = append(replies, formatJSON(path.result(document.root, path.isLegacy)))
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * JSON.DEL key [path]：返回删除的值个数，删除根节点时删除键
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func jsonDel(c *commandContext, args [][]byte) interface{} {
+	if len(args) > 2 {
+		panic(errSyntax)
+	}
+
+	key := string(args[0])
+
+	path := parseJSONPath(".")
+	if len(args) == 2 {
+		path = parseJSONPath(string(args[1]))
+	}
+
+	document := c.jsonValue(key)
+	if document == nil {
+		return int64(0)
+	}
+
+	if path.isRoot() {
+		c.remove(key)
+		return int64(1)
+	}
+
+	matches := path.match(document.root)
+
+	// 同一数组中的元素从后往前删除，避免下标偏移
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].index > matches[j].index
+	})
+
+	for _, match := range matches {
+		switch parent := match.parent.(type) {
+		case *jsonObject:
+			parent.remove(match.name)
+		case *jsonArray:
+			parent.items = append(parent.items[:match.index], parent.items[match.index+1:]...)
+		}
+	}
+
+	if len(matches) > 0 {
+		c.db.touch(key)
+	}
+
+	return int64(len(matches))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * JSON.NUMINCRBY key path value
+ * JSONPath 返回新值的数组（非数值对应 null），旧式路径返回第一个匹配的新值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func jsonNumIncrBy(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	path := parseJSONPath(string(args[1]))
+
+	increment, isOk := parseJSON(args[2]).(json.Number)
+	if !isOk {
+		panic(errorReply("ERR expected value"))
+	}
+
+	document := c.jsonValue(key)
+	if document == nil {
+		panic(errorReply("ERR could not perform this operation on a key that doesn't exist"))
+	}
+
+	matches := path.match(document.root)
+	if path.isLegacy && len(matches) == 0 {
+		panic(errorReply(fmt.Sprintf("ERR Path '%s' does not exist", path.text)))
+	}
+
+	results := &jsonArray{items: make([]interface{}, 0, len(matches))}
+	for _, match := range matches {
+		current, isNumber := match.value.(json.Number)
+		if !isNumber {
+			if path.isLegacy {
+				panic(errorReply("WRONGTYPE wrong type of path value - expected a number but found " + jsonTypeName(match.value)))
+			}
+
+			results.items = append(results.items, nil)
+			continue
+		}
+
+		value := addJSONNumber(current, increment)
+		document.replace(match, value)
+		results.items = append(results.items, value)
+
+		if path.isLegacy {
+			break
+		}
+	}
+
+	c.db.touch(key)
+
+	if path.isLegacy {
+		return formatJSON(results.items[0])
+	}
+
+	return formatJSON(results)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * JSON.ARRAPPEND key path value [value ...]
+ * JSONPath 返回各数组的新长度（非数组对应 nil），旧式路径返回第一个匹配的新长度
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func jsonArrAppend(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	path := parseJSONPath(string(args[1]))
+
+	values := make([]interface{}, 0, len(args)-2)
+	for _, arg := range args[2:] {
+		values = append(values, parseJSON(arg))
+	}
+
+	document := c.jsonValue(key)
+	if document == nil {
+		panic(errorReply("ERR could not perform this operation on a key that doesn't exist"))
+	}
+
+	matches := path.match(document.root)
+	if path.isLegacy && len(matches) == 0 {
+		panic(errorReply(fmt.Sprintf("ERR Path '%s' does not exist", path.text)))
+	}
+
+	replies := make([]interface{}, 0, len(matches))
+	for _, match := range matches {
+		array, isArray := match.value.(*jsonArray)
+		if !isArray {
+			if path.isLegacy {
+				panic(errorReply("WRONGTYPE wrong type of path value - expected an array but found " + jsonTypeName(match.value)))
+			}
+
+			replies = append(replies, nil)
+			continue
+		}
+
+		for _, value := range values {
+			array.items = append(array.items, cloneJSON(value))
+		}
+		replies = append(replies, int64(len(array.items)))
+
+		if path.isLegacy {
+			break
+		}
+	}
+
+	c.db.touch(key)
+
+	if path.isLegacy {
+		return replies[0]
+	}
+
+	return replies
+}
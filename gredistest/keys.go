@@ -0,0 +1,563 @@
+package gredistest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ================================================================================
+ * Key and server commands
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	serverVersion string = "7.2.0"
+	dumpPrefix    string = "gredistest:1:"
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * DUMP | RESTORE 的序列化格式，仅在 gredistest 内部可用
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	dumpValue struct {
+		Type   string             `json:"type"`
+		String []byte             `json:"string,omitempty"`
+		List   [][]byte           `json:"list,omitempty"`
+		Fields []string           `json:"fields,omitempty"`
+		Hash   map[string][]byte  `json:"hash,omitempty"`
+		Set    []string           `json:"set,omitempty"`
+		Zset   map[string]float64 `json:"zset,omitempty"`
+		Json   []byte             `json:"json,omitempty"`
+	}
+)
+
+func init() {
+	register("DEL", -2, del)
+	register("UNLINK", -2, del)
+	register("EXISTS", -2, exists)
+	register("KEYS", 2, keys)
+	register("RENAME", 3, rename)
+	register("RENAMENX", 3, renameNx)
+	register("EXPIRE", -3, expire(time.Second, false))
+	register("PEXPIRE", -3, expire(time.Millisecond, false))
+	register("EXPIREAT", -3, expire(time.Second, true))
+	register("PEXPIREAT", -3, expire(time.Millisecond, true))
+	register("PERSIST", 2, persist)
+	register("TTL", 2, ttl(time.Second))
+	register("PTTL", 2, ttl(time.Millisecond))
+	register("TYPE", 2, typeOf)
+	register("DUMP", 2, dump)
+	register("RESTORE", -4, restore)
+	register("DBSIZE", 1, dbSize)
+	register("FLUSHDB", -1, flushDb)
+	register("FLUSHALL", -1, flushAll)
+	register("SELECT", 2, selectDb)
+	register("PING", -1, ping)
+	register("ECHO", 2, echo)
+	register("TIME", 1, serverTime)
+	register("INFO", -1, info)
+	register("BGSAVE", -1, bgSave)
+}
+
+func del(c *commandContext, args [][]byte) interface{} {
+	count := int64(0)
+	for _, arg := range args {
+		if c.lookup(string(arg)) != nil && c.remove(string(arg)) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func exists(c *commandContext, args [][]byte) interface{} {
+	count := int64(0)
+	for _, arg := range args {
+		if c.lookup(string(arg)) != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+func keys(c *commandContext, args [][]byte) interface{} {
+	pattern := string(args[0])
+
+	result := make([]string, 0)
+	for key := range c.db.keys {
+		if c.lookup(key) != nil && matchPattern(pattern, key) {
+			result = append(result, key)
+		}
+	}
+
+	sort.Strings(result)
+
+	return bulkStrings(result)
+}
+
+func rename(c *commandContext, args [][]byte) interface{} {
+	source, destination := string(args[0]), string(args[1])
+
+	item := c.lookup(source)
+	if item == nil {
+		panic(errNoSuchKey)
+	}
+
+	c.remove(source)
+	c.db.keys[destination] = item
+	c.db.touch(destination)
+
+	return statusReply("OK")
+}
+
+func renameNx(c *commandContext, args [][]byte) interface{} {
+	if c.lookup(string(args[0])) == nil {
+		panic(errNoSuchKey)
+	}
+
+	if c.lookup(string(args[1])) != nil {
+		return int64(0)
+	}
+
+	rename(c, args)
+
+	return int64(1)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * EXPIRE | PEXPIRE | EXPIREAT | PEXPIREAT key time [NX | XX | GT | LT]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func expire(unit time.Duration, isAt bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		key := string(args[0])
+		value := parseInt(args[1])
+
+		var expireAt time.Time
+		if isAt {
+			expireAt = time.Unix(0, 0).Add(time.Duration(value) * unit)
+		} else {
+			expireAt = c.now.Add(time.Duration(value) * unit)
+		}
+
+		item := c.lookup(key)
+		if item == nil {
+			return int64(0)
+		}
+
+		for _, arg := range args[2:] {
+			isPersistent := item.expireAt.IsZero()
+
+			switch {
+			case isOption(arg, "NX") && !isPersistent,
+				isOption(arg, "XX") && isPersistent,
+				isOption(arg, "GT") && (isPersistent || !expireAt.After(item.expireAt)),
+				isOption(arg, "LT") && !isPersistent && !expireAt.Before(item.expireAt):
+				return int64(0)
+			case !isOption(arg, "NX") && !isOption(arg, "XX") && !isOption(arg, "GT") && !isOption(arg, "LT"):
+				panic(errorReply("ERR Unsupported option " + string(arg)))
+			}
+		}
+
+		if !expireAt.After(c.now) {
+			c.remove(key)
+			return int64(1)
+		}
+
+		item.expireAt = expireAt
+		c.db.touch(key)
+
+		return int64(1)
+	}
+}
+
+func persist(c *commandContext, args [][]byte) interface{} {
+	item := c.lookup(string(args[0]))
+	if item == nil || item.expireAt.IsZero() {
+		return int64(0)
+	}
+
+	item.expireAt = time.Time{}
+	c.db.touch(string(args[0]))
+
+	return int64(1)
+}
+
+func ttl(unit time.Duration) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		item := c.lookup(string(args[0]))
+		if item == nil {
+			return int64(-2)
+		}
+
+		if item.expireAt.IsZero() {
+			return int64(-1)
+		}
+
+		remaining := item.expireAt.Sub(c.now)
+
+		return int64((remaining + unit/2) / unit)
+	}
+}
+
+func typeOf(c *commandContext, args [][]byte) interface{} {
+	item := c.lookup(string(args[0]))
+	if item == nil {
+		return statusReply("none")
+	}
+
+	return statusReply(valueType(item.value))
+}
+
+func valueType(value interface{}) string {
+	switch value.(type) {
+	case []byte:
+		return "string"
+	case *listValue:
+		return "list"
+	case *hashValue:
+		return "hash"
+	case setValue:
+		return "set"
+	case zsetValue:
+		return "zset"
+	case *jsonValue:
+		return "ReJSON-RL"
+	}
+
+	return "none"
+}
+
+func dump(c *commandContext, args [][]byte) interface{} {
+	item := c.lookup(string(args[0]))
+	if item == nil {
+		return nil
+	}
+
+	value := dumpValue{Type: valueType(item.value)}
+
+	switch data := item.value.(type) {
+	case []byte:
+		value.String = data
+	case *listValue:
+		value.List = data.items
+	case *hashValue:
+		value.Fields, value.Hash = data.fields, data.values
+	case setValue:
+		value.Set = data.members()
+	case zsetValue:
+		value.Zset = data
+	case *jsonValue:
+		value.Json = formatJSON(data.root)
+	}
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		panic(errorReply("ERR " + err.Error()))
+	}
+
+	return append([]byte(dumpPrefix), payload...)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * RESTORE key ttl serialized-value [REPLACE] [ABSTTL]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func restore(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	ttlValue := parseInt(args[1])
+
+	isReplace, isAbsTtl := false, false
+	for _, arg := range args[3:] {
+		switch {
+		case isOption(arg, "REPLACE"):
+			isReplace = true
+		case isOption(arg, "ABSTTL"):
+			isAbsTtl = true
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	if !isReplace && c.lookup(key) != nil {
+		panic(errorReply("BUSYKEY Target key name already exists."))
+	}
+
+	payload := args[2]
+	if !bytes.HasPrefix(payload, []byte(dumpPrefix)) {
+		panic(errorReply("ERR DUMP payload version or checksum are wrong"))
+	}
+
+	var value dumpValue
+	if err := json.Unmarshal(payload[len(dumpPrefix):], &value); err != nil {
+		panic(errorReply("ERR Bad data format"))
+	}
+
+	var data interface{}
+	switch value.Type {
+	case "string":
+		data = value.String
+	case "list":
+		data = &listValue{items: value.List}
+	case "hash":
+		data = &hashValue{fields: value.Fields, values: value.Hash}
+	case "set":
+		set := make(setValue)
+		for _, member := range value.Set {
+			set[member] = struct{}{}
+		}
+		data = set
+	case "zset":
+		data = zsetValue(value.Zset)
+	case "ReJSON-RL":
+		data = &jsonValue{root: parseJSON(value.Json)}
+	default:
+		panic(errorReply("ERR Bad data format"))
+	}
+
+	c.set(key, data)
+
+	if ttlValue > 0 {
+		if isAbsTtl {
+			c.db.keys[key].expireAt = time.Unix(0, 0).Add(time.Duration(ttlValue) * time.Millisecond)
+		} else {
+			c.db.keys[key].expireAt = c.now.Add(time.Duration(ttlValue) * time.Millisecond)
+		}
+	}
+
+	return statusReply("OK")
+}
+
+func dbSize(c *commandContext, args [][]byte) interface{} {
+	count := int64(0)
+	for key := range c.db.keys {
+		if c.lookup(key) != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+func flushDb(c *commandContext, args [][]byte) interface{} {
+	if len(args) > 1 || (len(args) == 1 && !isOption(args[0], "ASYNC") && !isOption(args[0], "SYNC")) {
+		panic(errSyntax)
+	}
+
+	c.db.flush()
+
+	return statusReply("OK")
+}
+
+func flushAll(c *commandContext, args [][]byte) interface{} {
+	if len(args) > 1 || (len(args) == 1 && !isOption(args[0], "ASYNC") && !isOption(args[0], "SYNC")) {
+		panic(errSyntax)
+	}
+
+	for _, db := range c.engine.dbs {
+		db.flush()
+	}
+
+	return statusReply("OK")
+}
+
+func selectDb(c *commandContext, args [][]byte) interface{} {
+	index := parseInt(args[0])
+	if index < 0 || index >= int64(len(c.engine.dbs)) {
+		panic(errDbIndex)
+	}
+
+	c.session.db = int(index)
+
+	return statusReply("OK")
+}
+
+func ping(c *commandContext, args [][]byte) interface{} {
+	switch len(args) {
+	case 0:
+		return statusReply("PONG")
+	case 1:
+		return args[0]
+	}
+
+	panic(errorReply("ERR wrong number of arguments for 'ping' command"))
+}
+
+func echo(c *commandContext, args [][]byte) interface{} {
+	return args[0]
+}
+
+func serverTime(c *commandContext, args [][]byte) interface{} {
+	return []interface{}{
+		fmt.Sprint(c.now.Unix()),
+		fmt.Sprint(c.now.Nanosecond() / 1000),
+	}
+}
+
+func info(c *commandContext, args [][]byte) interface{} {
+	var builder strings.Builder
+
+	builder.WriteString("# Server\r\n")
+	builder.WriteString("redis_version:" + serverVersion + "\r\n")
+	builder.WriteString("redis_mode:standalone\r\n")
+	builder.WriteString("\r\n# Keyspace\r\n")
+
+	for index, db := range c.engine.dbs {
+		count, expires := 0, 0
+		for key, item := range db.keys {
+			if db.lookup(key, c.now) == nil {
+				continue
+			}
+
+			count++
+			if !item.expireAt.IsZero() {
+				expires++
+			}
+		}
+
+		if count > 0 {
+			builder.WriteString(fmt.Sprintf("db%d:keys=%d,expires=%d,avg_ttl=0\r\n", index, count, expires))
+		}
+	}
+
+	return builder.String()
+}
+
+func bgSave(c *commandContext, args [][]byte) interface{} {
+	return statusReply("Background saving started")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析 cursor [MATCH pattern] [COUNT count] 并返回按名称排序的 names 中本次迭代的元素，
+ * 游标为下一次迭代的起始位置，迭代结束时为0
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func scanNames(args [][]byte, names []string) (int64, []string) {
+	cursor, err := strconv.ParseUint(string(args[0]), 10, 64)
+	if err != nil {
+		panic(errorReply("ERR invalid cursor"))
+	}
+
+	pattern, count := "", int64(10)
+	for index := 1; index < len(args); index += 2 {
+		switch {
+		case index+1 >= len(args):
+			panic(errSyntax)
+		case isOption(args[index], "MATCH"):
+			pattern = string(args[index+1])
+		case isOption(args[index], "COUNT"):
+			if count = parseInt(args[index+1]); count < 1 {
+				panic(errSyntax)
+			}
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	sort.Strings(names)
+
+	items := make([]string, 0)
+	if cursor >= uint64(len(names)) {
+		return 0, items
+	}
+
+	end := int64(cursor) + count
+	if end > int64(len(names)) {
+		end = int64(len(names))
+	}
+
+	for _, name := range names[cursor:end] {
+		if len(pattern) == 0 || matchPattern(pattern, name) {
+			items = append(items, name)
+		}
+	}
+
+	if end == int64(len(names)) {
+		end = 0
+	}
+
+	return end, items
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Redis 风格的 glob 匹配：* ? [abc] [^a] [a-z] \x
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func matchPattern(pattern, value string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+
+			if len(pattern) == 1 {
+				return true
+			}
+
+			for index := 0; index <= len(value); index++ {
+				if matchPattern(pattern[1:], value[index:]) {
+					return true
+				}
+			}
+
+			return false
+		case '?':
+			if len(value) == 0 {
+				return false
+			}
+			value = value[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(value) == 0 {
+				return false
+			}
+
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				return pattern == value
+			}
+
+			class := pattern[1 : end+1]
+			isNot := len(class) > 0 && class[0] == '^'
+			if isNot {
+				class = class[1:]
+			}
+
+			isMatch := false
+			for index := 0; index < len(class); index++ {
+				if index+2 < len(class) && class[index+1] == '-' {
+					if class[index] <= value[0] && value[0] <= class[index+2] {
+						isMatch = true
+					}
+					index += 2
+				} else if class[index] == value[0] {
+					isMatch = true
+				}
+			}
+
+			if isMatch == isNot {
+				return false
+			}
+
+			value = value[1:]
+			pattern = pattern[end+2:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(value) == 0 || value[0] != pattern[0] {
+				return false
+			}
+			value = value[1:]
+			pattern = pattern[1:]
+		}
+	}
+
+	return len(value) == 0
+}
@@ -0,0 +1,170 @@
+package gredistest_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+func newTestLeaderboard(t *testing.T, option gredis.LeaderboardOption) (gredis.IRedis, *gredis.Leaderboard) {
+	redis := newFakeRedis(t, gredistest.NewFake(), gredis.RedisOption{})
+
+	return redis, gredis.NewLeaderboard(redis, "board", option)
+}
+
+func entryRanks(entries []*gredis.LeaderboardEntry) []string {
+	ranks := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ranks = append(ranks, entry.Member+":"+strconv.Itoa(entry.Rank))
+	}
+
+	return ranks
+}
+
+func TestLeaderboardPolicies(t *testing.T) {
+	for _, c := range []struct {
+		policy string
+		want   float64
+	}{
+		{gredis.REDIS_LEADERBOARD_POLICY_BEST, 30},
+		{gredis.REDIS_LEADERBOARD_POLICY_LATEST, 20},
+		{gredis.REDIS_LEADERBOARD_POLICY_SUM, 60},
+	} {
+		_, board := newTestLeaderboard(t, gredis.LeaderboardOption{Policy: c.policy})
+
+		var score float64
+		for _, value := range []float64{10, 30, 20} {
+			var err error
+			if score, err = board.Submit("alice", value); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if score != c.want {
+			t.Errorf("%s: Submit = %v, want %v", c.policy, score, c.want)
+		}
+	}
+}
+
+func TestLeaderboardRanking(t *testing.T) {
+	// 同分成员按成员名逆序排列
+	scores := map[string]float64{"a": 50, "b": 40, "c": 40, "d": 30, "e": 20}
+
+	for _, c := range []struct {
+		ranking string
+		page    []string
+		around  []string
+	}{
+		{gredis.REDIS_LEADERBOARD_RANK_COMPETITION, []string{"c:2", "b:2"}, []string{"b:2", "d:4", "e:5"}},
+		{gredis.REDIS_LEADERBOARD_RANK_DENSE, []string{"c:2", "b:2"}, []string{"b:2", "d:3", "e:4"}},
+	} {
+		_, board := newTestLeaderboard(t, gredis.LeaderboardOption{Ranking: c.ranking})
+
+		for member, score := range scores {
+			board.Submit(member, score)
+		}
+		board.SetMetadata("d", `{"name":"Dave"}`)
+
+		if entries, err := board.Page(2, 1); err != nil || len(entries) != 1 || entries[0].Member != "c" || entries[0].Rank != 2 {
+			t.Errorf("%s: Page(2, 1) = %v, %v", c.ranking, entryRanks(entries), err)
+		}
+
+		if entries, _ := board.Top(3); len(entries) != 3 || entryRanks(entries)[1] != c.page[0] || entryRanks(entries)[2] != c.page[1] {
+			t.Errorf("%s: Top(3) = %v", c.ranking, entryRanks(entries))
+		}
+
+		entries, err := board.AroundMe("d", 1)
+		if err != nil || len(entries) != 3 {
+			t.Fatalf("%s: AroundMe = %v, %v", c.ranking, entryRanks(entries), err)
+		}
+
+		for index, want := range c.around {
+			if got := entryRanks(entries)[index]; got != want {
+				t.Errorf("%s: AroundMe[%d] = %s, want %s", c.ranking, index, got, want)
+			}
+		}
+
+		if entries[1].Metadata != `{"name":"Dave"}` {
+			t.Errorf("%s: metadata = %q", c.ranking, entries[1].Metadata)
+		}
+
+		if _, err := board.Entry("nobody"); !errors.Is(err, gredis.ErrNotFound) {
+			t.Errorf("%s: Entry of missing member err = %v, want ErrNotFound", c.ranking, err)
+		}
+	}
+}
+
+func TestLeaderboardDenseUpdates(t *testing.T) {
+	_, board := newTestLeaderboard(t, gredis.LeaderboardOption{
+		Policy:  gredis.REDIS_LEADERBOARD_POLICY_LATEST,
+		Ranking: gredis.REDIS_LEADERBOARD_RANK_DENSE,
+	})
+
+	for member, score := range map[string]float64{"a": 50, "b": 40, "c": 40, "d": 30} {
+		board.Submit(member, score)
+	}
+
+	// 去重分数随分数变化及成员移除同步更新
+	steps := []struct {
+		name   string
+		update func()
+		want   int
+	}{
+		{"initial", func() {}, 3},
+		{"b raised, c keeps 40", func() { board.Submit("b", 60) }, 4},
+		{"c removed", func() { board.Remove("c") }, 3},
+		{"a raised to a shared score", func() { board.Submit("a", 60) }, 2},
+	}
+
+	for _, step := range steps {
+		step.update()
+
+		if entry, err := board.Entry("d"); err != nil || entry.Rank != step.want {
+			t.Errorf("%s: Entry(d) = %+v, %v, want rank %d", step.name, entry, err, step.want)
+		}
+	}
+
+	if entries, _ := board.Top(2); len(entries) != 2 || entries[0].Rank != 1 || entries[1].Rank != 1 {
+		t.Errorf("Top(2) = %v, want both ranked 1", entryRanks(entries))
+	}
+}
+
+func TestLeaderboardWindow(t *testing.T) {
+	location := time.FixedZone("UTC+8", 8*3600)
+	redis, board := newTestLeaderboard(t, gredis.LeaderboardOption{
+		Window:   gredis.REDIS_LEADERBOARD_WINDOW_DAILY,
+		Location: location,
+	})
+
+	yesterday := board.At(time.Now().AddDate(0, 0, -1))
+	yesterday.Submit("alice", 10)
+	board.Submit("bob", 20)
+
+	if yesterday.Key() == board.Key() {
+		t.Fatalf("daily boards share key %q", board.Key())
+	}
+
+	if entries, _ := yesterday.Top(10); len(entries) != 1 || entries[0].Member != "alice" {
+		t.Errorf("yesterday Top = %v", entryRanks(entries))
+	}
+
+	ttl, err := redis.Ttl(board.Key())
+	if err != nil || ttl <= 24*3600 || ttl > 48*3600 {
+		t.Errorf("daily board Ttl = %d, %v, want between 1 and 2 days", ttl, err)
+	}
+
+	weekly := gredis.NewLeaderboard(redis, "board", gredis.LeaderboardOption{
+		Window:   gredis.REDIS_LEADERBOARD_WINDOW_WEEKLY,
+		Location: location,
+	}).At(time.Date(2024, 12, 30, 12, 0, 0, 0, location))
+
+	if key := weekly.Key(); key != "board:2025W01" {
+		t.Errorf("weekly Key = %q, want board:2025W01", key)
+	}
+}
@@ -0,0 +1,521 @@
+package gredistest
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+)
+
+/* ================================================================================
+ * List commands
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+func init() {
+	register("LPUSH", -3, push(true))
+	register("RPUSH", -3, push(false))
+	register("LPOP", -2, pop(true))
+	register("RPOP", -2, pop(false))
+	register("LRANGE", 4, lRange)
+	register("LINDEX", 3, lIndex)
+	register("LSET", 4, lSet)
+	register("LREM", 4, lRem)
+	register("LTRIM", 4, lTrim)
+	register("LLEN", 2, lLen)
+	register("LPUSHX", -3, pushX(true))
+	register("RPUSHX", -3, pushX(false))
+	register("LINSERT", 5, lInsert)
+	register("LPOS", -3, lPos)
+	register("LMOVE", 5, lMove)
+	register("RPOPLPUSH", 3, rPopLPush)
+	register("LMPOP", -4, lMPop)
+	register("BLMOVE", 6, blocking(false, lMove))
+	register("BRPOPLPUSH", 4, blocking(false, rPopLPush))
+	register("BLPOP", -3, blocking(false, bPop(true)))
+	register("BRPOP", -3, blocking(false, bPop(false)))
+	register("BLMPOP", -5, blocking(true, lMPop))
+}
+
+func push(isLeft bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		key := string(args[0])
+		list := c.listValue(key, true)
+
+		for _, value := range args[1:] {
+			if isLeft {
+				list.items = append([][]byte{value}, list.items...)
+			} else {
+				list.items = append(list.items, value)
+			}
+		}
+
+		c.modified(key, len(list.items))
+
+		return int64(len(list.items))
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * LPOP | RPOP key [count]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func pop(isLeft bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		if len(args) > 2 {
+			panic(errSyntax)
+		}
+
+		key := string(args[0])
+
+		count, isCount := int64(1), len(args) == 2
+		if isCount {
+			count = parseInt(args[1])
+			if count < 0 {
+				panic(errorReply("ERR value is out of range, must be positive"))
+			}
+		}
+
+		list := c.listValue(key, false)
+		if list == nil {
+			if isCount {
+				return nullArray{}
+			}
+			return nil
+		}
+
+		items := popItems(list, int(count), isLeft)
+		c.modified(key, len(list.items))
+
+		if !isCount {
+			return items[0]
+		}
+
+		replies := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			replies = append(replies, item)
+		}
+
+		return replies
+	}
+}
+
+func popItems(list *listValue, count int, isLeft bool) [][]byte {
+	if count > len(list.items) {
+		count = len(list.items)
+	}
+
+	items := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		if isLeft {
+			items = append(items, list.items[0])
+			list.items = list.items[1:]
+		} else {
+			last := len(list.items) - 1
+			items = append(items, list.items[last])
+			list.items = list.items[:last]
+		}
+	}
+
+	return items
+}
+
+func lRange(c *commandContext, args [][]byte) interface{} {
+	list := c.listValue(string(args[0]), false)
+	if list == nil {
+		return []interface{}{}
+	}
+
+	start, stop, isOk := normalizeRange(parseInt(args[1]), parseInt(args[2]), len(list.items))
+	if !isOk {
+		return []interface{}{}
+	}
+
+	replies := make([]interface{}, 0, stop-start+1)
+	for _, item := range list.items[start : stop+1] {
+		replies = append(replies, item)
+	}
+
+	return replies
+}
+
+func lIndex(c *commandContext, args [][]byte) interface{} {
+	index := parseInt(args[1])
+
+	list := c.listValue(string(args[0]), false)
+	if list == nil {
+		return nil
+	}
+
+	if index < 0 {
+		index += int64(len(list.items))
+	}
+
+	if index < 0 || index >= int64(len(list.items)) {
+		return nil
+	}
+
+	return list.items[index]
+}
+
+func lSet(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	index := parseInt(args[1])
+
+	list := c.listValue(key, false)
+	if list == nil {
+		panic(errNoSuchKey)
+	}
+
+	if index < 0 {
+		index += int64(len(list.items))
+	}
+
+	if index < 0 || index >= int64(len(list.items)) {
+		panic(errOutOfRange)
+	}
+
+	list.items[index] = args[2]
+	c.modified(key, len(list.items))
+
+	return statusReply("OK")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * LREM key count element：count > 0 从头部删除，count < 0 从尾部删除，0 删除全部
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func lRem(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	count := parseInt(args[1])
+
+	list := c.listValue(key, false)
+	if list == nil {
+		return int64(0)
+	}
+
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+
+	removed := int64(0)
+	items := make([][]byte, len(list.items))
+	copy(items, list.items)
+
+	if count >= 0 {
+		kept := items[:0]
+		for _, item := range items {
+			if bytes.Equal(item, args[2]) && (limit == 0 || removed < limit) {
+				removed++
+				continue
+			}
+			kept = append(kept, item)
+		}
+		items = kept
+	} else {
+		kept := make([][]byte, 0, len(items))
+		for index := len(items) - 1; index >= 0; index-- {
+			if bytes.Equal(items[index], args[2]) && removed < limit {
+				removed++
+				continue
+			}
+			kept = append([][]byte{items[index]}, kept...)
+		}
+		items = kept
+	}
+
+	if removed > 0 {
+		list.items = items
+		c.modified(key, len(list.items))
+	}
+
+	return removed
+}
+
+func lTrim(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	start, stop := parseInt(args[1]), parseInt(args[2])
+
+	list := c.listValue(key, false)
+	if list == nil {
+		return statusReply("OK")
+	}
+
+	from, to, isOk := normalizeRange(start, stop, len(list.items))
+	if !isOk {
+		list.items = nil
+	} else {
+		list.items = list.items[from : to+1]
+	}
+
+	c.modified(key, len(list.items))
+
+	return statusReply("OK")
+}
+
+func lLen(c *commandContext, args [][]byte) interface{} {
+	list := c.listValue(string(args[0]), false)
+	if list == nil {
+		return int64(0)
+	}
+
+	return int64(len(list.items))
+}
+
+func pushX(isLeft bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		if c.listValue(string(args[0]), false) == nil {
+			return int64(0)
+		}
+
+		return push(isLeft)(c, args)
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * LINSERT key BEFORE | AFTER pivot element
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func lInsert(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	isBefore := isOption(args[1], "BEFORE")
+	if !isBefore && !isOption(args[1], "AFTER") {
+		panic(errSyntax)
+	}
+
+	list := c.listValue(key, false)
+	if list == nil {
+		return int64(0)
+	}
+
+	for index, item := range list.items {
+		if !bytes.Equal(item, args[2]) {
+			continue
+		}
+
+		if !isBefore {
+			index++
+		}
+
+		list.items = append(list.items[:index], append([][]byte{args[3]}, list.items[index:]...)...)
+		c.modified(key, len(list.items))
+
+		return int64(len(list.items))
+	}
+
+	return int64(-1)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * LPOS key element [RANK rank] [COUNT count] [MAXLEN len]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func lPos(c *commandContext, args [][]byte) interface{} {
+	rank, count, maxLen, isCount := int64(1), int64(1), int64(0), false
+
+	for index := 2; index < len(args); index += 2 {
+		if index+1 >= len(args) {
+			panic(errSyntax)
+		}
+
+		value := parseInt(args[index+1])
+
+		switch {
+		case isOption(args[index], "RANK"):
+			if value == 0 {
+				panic(errorReply("ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list"))
+			}
+			rank = value
+		case isOption(args[index], "COUNT"):
+			if value < 0 {
+				panic(errorReply("ERR COUNT can't be negative"))
+			}
+			count, isCount = value, true
+		case isOption(args[index], "MAXLEN"):
+			if value < 0 {
+				panic(errorReply("ERR MAXLEN can't be negative"))
+			}
+			maxLen = value
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	positions := []interface{}{}
+
+	list := c.listValue(string(args[0]), false)
+	if list != nil {
+		length := int64(len(list.items))
+		skip := rank - 1
+		if rank < 0 {
+			skip = -rank - 1
+		}
+
+		for compared := int64(0); compared < length && (maxLen == 0 || compared < maxLen); compared++ {
+			index := compared
+			if rank < 0 {
+				index = length - 1 - compared
+			}
+
+			if !bytes.Equal(list.items[index], args[1]) {
+				continue
+			}
+
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			positions = append(positions, index)
+			if count > 0 && int64(len(positions)) >= count {
+				break
+			}
+		}
+	}
+
+	if isCount {
+		return positions
+	}
+
+	if len(positions) == 0 {
+		return nil
+	}
+
+	return positions[0]
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * LMOVE source destination LEFT | RIGHT LEFT | RIGHT
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func lMove(c *commandContext, args [][]byte) interface{} {
+	return move(c, string(args[0]), string(args[1]), parseDirection(args[2]), parseDirection(args[3]))
+}
+
+func rPopLPush(c *commandContext, args [][]byte) interface{} {
+	return move(c, string(args[0]), string(args[1]), false, true)
+}
+
+func move(c *commandContext, source, destination string, isFromLeft, isToLeft bool) interface{} {
+	list := c.listValue(source, false)
+	if list == nil {
+		return nil
+	}
+
+	// 目标类型错误时不弹出元素
+	c.listValue(destination, false)
+
+	item := popItems(list, 1, isFromLeft)[0]
+	c.modified(source, len(list.items))
+
+	push(isToLeft)(c, [][]byte{[]byte(destination), item})
+
+	return item
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * LMPOP numkeys key [key ...] LEFT | RIGHT [COUNT count]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func lMPop(c *commandContext, args [][]byte) interface{} {
+	numKeys := parseInt(args[0])
+	if numKeys <= 0 || numKeys+2 > int64(len(args)) {
+		panic(errorReply("ERR numkeys should be greater than 0"))
+	}
+
+	keys := args[1 : numKeys+1]
+	isLeft := parseDirection(args[numKeys+1])
+
+	count := int64(1)
+	options := args[numKeys+2:]
+	switch {
+	case len(options) == 2 && isOption(options[0], "COUNT"):
+		count = parseInt(options[1])
+		if count <= 0 {
+			panic(errorReply("ERR count should be greater than 0"))
+		}
+	case len(options) != 0:
+		panic(errSyntax)
+	}
+
+	for _, key := range keys {
+		list := c.listValue(string(key), false)
+		if list == nil {
+			continue
+		}
+
+		items := popItems(list, int(count), isLeft)
+		c.modified(string(key), len(list.items))
+
+		replies := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			replies = append(replies, item)
+		}
+
+		return []interface{}{key, replies}
+	}
+
+	return nullArray{}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BLPOP | BRPOP key [key ...] timeout，超时参数已由 blocking 移除
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func bPop(isLeft bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		for _, key := range args {
+			list := c.listValue(string(key), false)
+			if list == nil {
+				continue
+			}
+
+			item := popItems(list, 1, isLeft)[0]
+			c.modified(string(key), len(list.items))
+
+			return []interface{}{key, item}
+		}
+
+		return nullArray{}
+	}
+}
+
+func parseDirection(arg []byte) bool {
+	switch {
+	case isOption(arg, "LEFT"):
+		return true
+	case isOption(arg, "RIGHT"):
+		return false
+	}
+
+	panic(errSyntax)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 包装阻塞命令：isTimeoutFirst 为 true 时超时为首个参数（BLMPOP），否则为最后一个参数，
+ * 无可弹出元素时返回 blocked，由会话等待数据变化或超时后重试
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func blocking(isTimeoutFirst bool, handler func(c *commandContext, args [][]byte) interface{}) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		var arg []byte
+		if isTimeoutFirst {
+			arg, args = args[0], args[1:]
+		} else {
+			arg, args = args[len(args)-1], args[:len(args)-1]
+		}
+
+		timeout, err := strconv.ParseFloat(string(arg), 64)
+		if err != nil {
+			panic(errorReply("ERR timeout is not a float or out of range"))
+		}
+
+		if timeout < 0 {
+			panic(errorReply("ERR timeout is negative"))
+		}
+
+		reply := handler(c, args)
+		if reply != nil && reply != (nullArray{}) {
+			return reply
+		}
+
+		return blocked{
+			timeout: time.Duration(timeout * float64(time.Second)),
+			reply:   reply,
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package gredistest
+
+import (
+	"strconv"
+	"strings"
+)
+
+/* ================================================================================
+ * Pub/Sub and client tracking
+ * 频道订阅及 CLIENT TRACKING 广播模式（BCAST）：键被修改后向跟踪的 RESP3 连接推送
+ * invalidate 消息，或向 REDIRECT 指定的、订阅了 __redis__:invalidate 的连接发送频道消息
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	invalidateChannel string = "__redis__:invalidate"
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 客户端跟踪选项，redirect 为接收通知的连接Id，0表示本连接
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	tracking struct {
+		redirect int64
+		prefixes []string
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * CLIENT TRACKING ON|OFF [REDIRECT id] [PREFIX prefix ...] [BCAST]
+ * 仅支持广播模式，默认模式（按读取的键跟踪）返回错误
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) track(args [][]byte) interface{} {
+	if len(args) == 0 {
+		return errorReply("ERR wrong number of arguments for 'client|tracking' command")
+	}
+
+	s.engine.mutex.Lock()
+	defer s.engine.mutex.Unlock()
+
+	switch {
+	case isOption(args[0], "OFF"):
+		s.tracking = nil
+		return statusReply("OK")
+	case !isOption(args[0], "ON"):
+		return errSyntax
+	}
+
+	option := &tracking{}
+	isBroadcast := false
+
+	for index := 1; index < len(args); index++ {
+		switch {
+		case isOption(args[index], "BCAST"):
+			isBroadcast = true
+		case isOption(args[index], "REDIRECT") && index+1 < len(args):
+			id, err := strconv.ParseInt(string(args[index+1]), 10, 64)
+			if err != nil {
+				return errNotInteger
+			}
+
+			if _, isExists := s.engine.sessions[id]; !isExists && id != s.id {
+				return errorReply("ERR The client ID you want redirect to does not exist")
+			}
+
+			option.redirect = id
+			index++
+		case isOption(args[index], "PREFIX") && index+1 < len(args):
+			option.prefixes = append(option.prefixes, string(args[index+1]))
+			index++
+		default:
+			return errSyntax
+		}
+	}
+
+	if !isBroadcast {
+		if len(option.prefixes) > 0 {
+			return errorReply("ERR PREFIX option requires BCAST mode to be enabled")
+		}
+
+		return errorReply("ERR gredistest supports only BCAST tracking")
+	}
+
+	if option.redirect == s.id {
+		option.redirect = 0
+	}
+
+	s.tracking = option
+
+	return statusReply("OK")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 订阅频道，每个频道回复一条 subscribe 消息
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) subscribe(args [][]byte) interface{} {
+	if len(args) == 0 {
+		return errorReply("ERR wrong number of arguments for 'subscribe' command")
+	}
+
+	s.engine.mutex.Lock()
+	defer s.engine.mutex.Unlock()
+
+	if s.channels == nil {
+		s.channels = make(map[string]struct{})
+	}
+
+	for _, arg := range args {
+		s.channels[string(arg)] = struct{}{}
+		s.write(pushReply{"subscribe", string(arg), int64(len(s.channels))})
+	}
+
+	return noReply{}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 取消订阅，未指定频道时取消全部订阅
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) unsubscribe(args [][]byte) interface{} {
+	s.engine.mutex.Lock()
+	defer s.engine.mutex.Unlock()
+
+	channels := make([]string, 0, len(args))
+	for _, arg := range args {
+		channels = append(channels, string(arg))
+	}
+
+	if len(channels) == 0 {
+		for channel := range s.channels {
+			channels = append(channels, channel)
+		}
+	}
+
+	if len(channels) == 0 {
+		return pushReply{"unsubscribe", nil, int64(0)}
+	}
+
+	for _, channel := range channels {
+		delete(s.channels, channel)
+		s.write(pushReply{"unsubscribe", channel, int64(len(s.channels))})
+	}
+
+	return noReply{}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * RESP2 订阅状态下仅允许订阅相关命令及 PING
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) executeSubscribed(name string, args [][]byte) interface{} {
+	switch name {
+	case "SUBSCRIBE":
+		return s.subscribe(args[1:])
+	case "UNSUBSCRIBE":
+		return s.unsubscribe(args[1:])
+	case "PING":
+		message := ""
+		if len(args) > 1 {
+			message = string(args[1])
+		}
+		return pushReply{"pong", message}
+	}
+
+	return errorReply("ERR Can't execute '" + strings.ToLower(name) + "': only (P|S)SUBSCRIBE / (P|S)UNSUBSCRIBE / PING / QUIT / RESET are allowed in this context")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 发送命令修改的键的失效通知，调用方需持有引擎锁
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (e *engine) invalidate() {
+	keys := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, db := range e.dbs {
+		for _, key := range db.changes {
+			if _, isExists := seen[key]; !isExists {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+		db.changes = nil
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	for _, s := range e.sessions {
+		if s.tracking == nil {
+			continue
+		}
+
+		matches := s.tracking.match(keys)
+		if len(matches) == 0 {
+			continue
+		}
+
+		if s.tracking.redirect == 0 {
+			if s.proto == 3 {
+				s.write(pushReply{"invalidate", bulkStrings(matches)})
+			}
+			continue
+		}
+
+		if target := e.sessions[s.tracking.redirect]; target != nil {
+			target.invalidated(matches)
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 向订阅了失效频道的连接发送通知，调用方需持有引擎锁
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) invalidated(keys []string) {
+	if _, isOk := s.channels[invalidateChannel]; isOk {
+		s.write(pushReply{"message", invalidateChannel, bulkStrings(keys)})
+	}
+}
+
+func (t *tracking) match(keys []string) []string {
+	if len(t.prefixes) == 0 {
+		return keys
+	}
+
+	matches := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, prefix := range t.prefixes {
+			if strings.HasPrefix(key, prefix) {
+				matches = append(matches, key)
+				break
+			}
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,243 @@
+package gredistest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+func newTestQueue(t *testing.T, option gredis.QueueOption) (*gredistest.Fake, *gredis.Queue) {
+	fake := gredistest.NewFake()
+
+	return fake, gredis.NewQueue(newFakeRedis(t, fake, gredis.RedisOption{}), "jobs", option)
+}
+
+func TestQueueAck(t *testing.T) {
+	_, queue := newTestQueue(t, gredis.QueueOption{})
+
+	for _, payload := range []string{"a", "b"} {
+		if _, err := queue.Enqueue(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job, err := queue.Dequeue("worker1", time.Second)
+	if err != nil || string(job.Payload) != "a" || job.Attempts != 0 {
+		t.Fatalf("Dequeue = %+v, %v", job, err)
+	}
+
+	if err := queue.Ack(job); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := queue.Ack(job); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("second Ack err = %v, want ErrNotFound", err)
+	}
+
+	if stats, _ := queue.Stats(); stats.Pending != 1 {
+		t.Errorf("Stats = %+v, want 1 pending", stats)
+	}
+}
+
+func TestQueueNackAndDeadLetter(t *testing.T) {
+	_, queue := newTestQueue(t, gredis.QueueOption{MaxAttempts: 2})
+
+	id, _ := queue.Enqueue("payload")
+
+	job, err := queue.Dequeue("worker1", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := queue.Nack(job, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err = queue.Dequeue("worker1", time.Second)
+	if err != nil || job.Id != id || job.Attempts != 1 {
+		t.Fatalf("Dequeue after Nack = %+v, %v", job, err)
+	}
+
+	if err := queue.Nack(job, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := queue.Dequeue("worker1", 10*time.Millisecond); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("Dequeue err = %v, want ErrNotFound", err)
+	}
+
+	jobs, err := queue.DeadJobs()
+	if err != nil || len(jobs) != 1 || jobs[0].Id != id || string(jobs[0].Payload) != "payload" || jobs[0].Attempts != 2 {
+		t.Errorf("DeadJobs = %+v, %v", jobs, err)
+	}
+}
+
+func TestQueueDelayed(t *testing.T) {
+	fake, queue := newTestQueue(t, gredis.QueueOption{})
+
+	if _, err := queue.EnqueueIn("later", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := queue.Dequeue("worker1", 10*time.Millisecond); !errors.Is(err, gredis.ErrNotFound) {
+		t.Fatalf("Dequeue before due err = %v, want ErrNotFound", err)
+	}
+
+	fake.Clock().Advance(time.Minute)
+
+	job, err := queue.Dequeue("worker1", time.Second)
+	if err != nil || string(job.Payload) != "later" {
+		t.Errorf("Dequeue after due = %+v, %v", job, err)
+	}
+}
+
+func TestQueueReap(t *testing.T) {
+	fake, queue := newTestQueue(t, gredis.QueueOption{VisibilityTimeout: 30 * time.Second})
+
+	queue.Enqueue("a")
+	queue.Enqueue("b")
+
+	crashed, err := queue.Dequeue("crashed", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.Clock().Advance(10 * time.Second)
+	if count, _ := queue.Reap(); count != 0 {
+		t.Errorf("Reap before visibility timeout = %d, want 0", count)
+	}
+
+	// 存活的工作者保持心跳
+	fake.Clock().Advance(25 * time.Second)
+	if err := queue.Heartbeat("alive"); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := queue.Reap(); err != nil || count != 1 {
+		t.Fatalf("Reap = %d, %v, want 1", count, err)
+	}
+
+	if err := queue.Ack(crashed); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("Ack of reaped job err = %v, want ErrNotFound", err)
+	}
+
+	job, err := queue.Dequeue("alive", time.Second)
+	if err != nil || job.Id != crashed.Id || job.Attempts != 1 {
+		t.Errorf("Dequeue after Reap = %+v, %v, want reaped job first", job, err)
+	}
+}
+
+func TestQueueDequeueLongBlock(t *testing.T) {
+	fake, queue := newTestQueue(t, gredis.QueueOption{VisibilityTimeout: 100 * time.Millisecond})
+
+	// 服务器时钟随真实时间推进，回收器持续运行
+	ctx, cancel := context.WithCancel(context.Background())
+	reaped := make(chan struct{})
+	go func() {
+		defer close(reaped)
+
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fake.Clock().Advance(10 * time.Millisecond)
+				queue.Reap()
+			}
+		}
+	}()
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		queue.Enqueue("late")
+	}()
+
+	// 阻塞时长超过可见性超时，出队的任务不应被立即回收
+	job, err := queue.Dequeue("worker1", time.Second)
+	cancel()
+	<-reaped
+
+	if err != nil || string(job.Payload) != "late" {
+		t.Fatalf("Dequeue = %+v, %v", job, err)
+	}
+
+	fake.Clock().Advance(50 * time.Millisecond)
+	if count, err := queue.Reap(); err != nil || count != 0 {
+		t.Errorf("Reap within visibility timeout of dequeue = %d, %v, want 0", count, err)
+	}
+
+	// 工作者此后停止心跳，任务在可见性超时后可被回收
+	fake.Clock().Advance(100 * time.Millisecond)
+	if count, err := queue.Reap(); err != nil || count != 1 {
+		t.Errorf("Reap after visibility timeout = %d, %v, want 1", count, err)
+	}
+
+	if err := queue.Ack(job); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("Ack of reaped job err = %v, want ErrNotFound", err)
+	}
+
+	if _, err := queue.Dequeue("worker2", 120*time.Millisecond); err != nil {
+		t.Errorf("Dequeue of reaped job err = %v", err)
+	}
+
+	if _, err := queue.Dequeue("worker1", 120*time.Millisecond); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("Dequeue on empty queue err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestQueueRunReaperError(t *testing.T) {
+	redis := gredistest.NewFake().NewRedis(gredis.RedisOption{Prefix: "test:"})
+	queue := gredis.NewQueue(redis, "jobs")
+	redis.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := queue.RunReaper(ctx, 10*time.Millisecond); err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RunReaper on closed client err = %v, want the Redis error", err)
+	}
+}
+
+func TestQueueDequeueReleaseOnError(t *testing.T) {
+	server := newTestServer(t)
+	redis := newServerRedis(t, server, gredis.RedisOption{})
+	queue := gredis.NewQueue(redis, "jobs")
+
+	id, err := queue.Enqueue("payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 取得任务后的心跳及读取任务内容失败时，任务放回待处理列表而非滞留在处理中列表
+	for _, command := range []string{"EVALSHA", "HGET"} {
+		redis.AddHook(&afterCommandHook{command: "BLMOVE", action: func() {
+			server.FailNext(command, 1, "ERR boom")
+		}})
+
+		if job, err := queue.Dequeue("worker1", time.Second); err == nil {
+			t.Fatalf("Dequeue with failing %s = %+v, want error", command, job)
+		}
+
+		if stats, _ := queue.Stats(); stats.Pending != 1 {
+			t.Errorf("after failing %s Stats = %+v, want the job pending", command, stats)
+		}
+	}
+
+	job, err := queue.Dequeue("worker1", time.Second)
+	if err != nil || job.Id != id || string(job.Payload) != "payload" {
+		t.Fatalf("Dequeue after release = %+v, %v", job, err)
+	}
+
+	if err := queue.Ack(job); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,219 @@
+package gredistest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/* ================================================================================
+ * RESP reader / writer
+ * 服务端的请求解析及回复编码，HELLO 3 协商后按 RESP3 编码
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+var (
+	errProtocol = errors.New("gredistest: protocol error")
+)
+
+type (
+	statusReply string
+	errorReply  string
+	nullArray   struct{}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 推送消息（订阅消息、失效通知），不对应任何请求
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	pushReply []interface{}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 以下回复在 RESP2 下均为平铺数组：
+	 * mapReply 为键值交替的映射（RESP3 map），setReply 为集合（RESP3 set），
+	 * pairsReply 为成员与分值交替的数组（RESP3 下为 [[member, score], ...]）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	mapReply   []interface{}
+	setReply   []interface{}
+	pairsReply []interface{}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 命令已自行写入回复（如 SUBSCRIBE 每个频道一条回复）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	noReply struct{}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 读取一条命令，支持多条批量字符串数组及内联命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func readCommand(reader *bufio.Reader) ([][]byte, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, nil
+	}
+
+	if line[0] != '*' {
+		fields := strings.Fields(line)
+		args := make([][]byte, 0, len(fields))
+		for _, field := range fields {
+			args = append(args, []byte(field))
+		}
+		return args, nil
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < 0 {
+		return nil, errProtocol
+	}
+
+	args := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		line, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(line) == 0 || line[0] != '$' {
+			return nil, errProtocol
+		}
+
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, errProtocol
+		}
+
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg[:size])
+	}
+
+	return args, nil
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 编码回复，proto 为连接协商的协议版本 2 | 3
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func writeReply(writer *bytes.Buffer, reply interface{}, proto int) {
+	if proto == 3 && writeResp3Reply(writer, reply) {
+		return
+	}
+
+	switch value := reply.(type) {
+	case nil:
+		writer.WriteString("$-1\r\n")
+	case nullArray:
+		writer.WriteString("*-1\r\n")
+	case statusReply:
+		writer.WriteString("+" + string(value) + "\r\n")
+	case errorReply:
+		writer.WriteString("-" + string(value) + "\r\n")
+	case int64:
+		writer.WriteString(":" + strconv.FormatInt(value, 10) + "\r\n")
+	case int:
+		writer.WriteString(":" + strconv.Itoa(value) + "\r\n")
+	case bool:
+		if value {
+			writer.WriteString(":1\r\n")
+		} else {
+			writer.WriteString(":0\r\n")
+		}
+	case float64:
+		writeBulk(writer, []byte(formatFloat(value)))
+	case string:
+		writeBulk(writer, []byte(value))
+	case []byte:
+		writeBulk(writer, value)
+	case []interface{}:
+		writeAggregate(writer, '*', value, proto)
+	case pushReply:
+		writeAggregate(writer, '*', value, proto)
+	case mapReply:
+		writeAggregate(writer, '*', value, proto)
+	case setReply:
+		writeAggregate(writer, '*', value, proto)
+	case pairsReply:
+		writeAggregate(writer, '*', value, proto)
+	default:
+		writeReply(writer, errorReply("ERR unsupported reply type"), proto)
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 编码 RESP3 特有的类型，其余类型与 RESP2 相同时返回 false
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func writeResp3Reply(writer *bytes.Buffer, reply interface{}) bool {
+	switch value := reply.(type) {
+	case nil, nullArray:
+		writer.WriteString("_\r\n")
+	case float64:
+		writer.WriteString("," + formatFloat(value) + "\r\n")
+	case pushReply:
+		writeAggregate(writer, '>', value, 3)
+	case mapReply:
+		writer.WriteString("%" + strconv.Itoa(len(value)/2) + "\r\n")
+		for _, item := range value {
+			writeReply(writer, item, 3)
+		}
+	case setReply:
+		writeAggregate(writer, '~', value, 3)
+	case pairsReply:
+		writer.WriteString("*" + strconv.Itoa(len(value)/2) + "\r\n")
+		for index := 0; index+1 < len(value); index += 2 {
+			writeAggregate(writer, '*', value[index:index+2], 3)
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+func writeAggregate(writer *bytes.Buffer, kind byte, values []interface{}, proto int) {
+	writer.WriteByte(kind)
+	writer.WriteString(strconv.Itoa(len(values)) + "\r\n")
+	for _, item := range values {
+		writeReply(writer, item, proto)
+	}
+}
+
+func writeBulk(writer *bytes.Buffer, value []byte) {
+	writer.WriteString("$" + strconv.Itoa(len(value)) + "\r\n")
+	writer.Write(value)
+	writer.WriteString("\r\n")
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "inf"
+	}
+
+	if math.IsInf(value, -1) {
+		return "-inf"
+	}
+
+	// 与 Redis 一致，整数值不使用科学计数法
+	if value == math.Trunc(value) && math.Abs(value) < 1e17 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,207 @@
+package gredistest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+func newTestScheduler(t *testing.T, fake *gredistest.Fake, option gredis.SchedulerOption) *gredis.Scheduler {
+	return gredis.NewScheduler(newFakeRedis(t, fake, gredis.RedisOption{}), "cron", option)
+}
+
+func TestSchedulerClaimAndComplete(t *testing.T) {
+	fake := gredistest.NewFake()
+	scheduler := newTestScheduler(t, fake, gredis.SchedulerOption{ClaimTimeout: time.Minute})
+
+	id, err := scheduler.ScheduleIn(time.Minute, "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if jobs, err := scheduler.Claim(); err != nil || len(jobs) != 0 {
+		t.Fatalf("Claim before due = %v, %v", jobs, err)
+	}
+
+	fake.Clock().Advance(2 * time.Minute)
+
+	jobs, err := scheduler.Claim()
+	if err != nil || len(jobs) != 1 || jobs[0].Id != id || string(jobs[0].Payload) != "payload" {
+		t.Fatalf("Claim = %v, %v", jobs, err)
+	}
+
+	if again, _ := scheduler.Claim(); len(again) != 0 {
+		t.Errorf("Claim while leased = %v, want none", again)
+	}
+
+	fake.Clock().Advance(2 * time.Minute)
+
+	if again, _ := scheduler.Claim(); len(again) != 1 {
+		t.Errorf("Claim after lease expired = %v, want 1", again)
+	}
+
+	if err := scheduler.Complete(jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := scheduler.Complete(jobs[0]); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("second Complete err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSchedulerUniqueAndCancel(t *testing.T) {
+	fake := gredistest.NewFake()
+	scheduler := newTestScheduler(t, fake, gredis.SchedulerOption{})
+
+	id, err := scheduler.ScheduleIn(time.Minute, "a", gredis.ScheduleOption{UniqueKey: "report"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing, err := scheduler.ScheduleIn(time.Minute, "b", gredis.ScheduleOption{UniqueKey: "report"})
+	if !errors.Is(err, gredis.ErrDuplicateJob) || existing != id {
+		t.Errorf("duplicate = %q, %v, want %q, ErrDuplicateJob", existing, err, id)
+	}
+
+	if err := scheduler.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := scheduler.Cancel(id); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("second Cancel err = %v, want ErrNotFound", err)
+	}
+
+	if _, err := scheduler.ScheduleIn(time.Minute, "c", gredis.ScheduleOption{UniqueKey: "report"}); err != nil {
+		t.Errorf("schedule after Cancel err = %v", err)
+	}
+
+	fake.Clock().Advance(2 * time.Minute)
+
+	if jobs, _ := scheduler.Claim(); len(jobs) != 1 || string(jobs[0].Payload) != "c" {
+		t.Errorf("Claim = %v, want only c", jobs)
+	}
+}
+
+func TestSchedulerCron(t *testing.T) {
+	fake := gredistest.NewFake()
+	scheduler := newTestScheduler(t, fake, gredis.SchedulerOption{})
+
+	if _, err := scheduler.ScheduleCron("61 * * * *", "bad"); !errors.Is(err, gredis.ErrInvalidCron) {
+		t.Errorf("ScheduleCron err = %v, want ErrInvalidCron", err)
+	}
+
+	id, err := scheduler.ScheduleCron("@every 1m", "tick", gredis.ScheduleOption{Id: "tick"})
+	if err != nil || id != "tick" {
+		t.Fatalf("ScheduleCron = %q, %v", id, err)
+	}
+
+	for round := 0; round < 2; round++ {
+		fake.Clock().Advance(2 * time.Minute)
+
+		jobs, err := scheduler.Claim()
+		if err != nil || len(jobs) != 1 || jobs[0].Id != "tick" || jobs[0].Cron != "@every 1m" {
+			t.Fatalf("round %d Claim = %v, %v", round, jobs, err)
+		}
+
+		if err := scheduler.Complete(jobs[0]); err != nil {
+			t.Fatal(err)
+		}
+
+		if again, _ := scheduler.Claim(); len(again) != 0 {
+			t.Errorf("round %d Claim after Complete = %v, want none", round, again)
+		}
+	}
+}
+
+func TestSchedulerElect(t *testing.T) {
+	fake := gredistest.NewFake()
+	first := newTestScheduler(t, fake, gredis.SchedulerOption{LeaderTtl: 10 * time.Second})
+	second := newTestScheduler(t, fake, gredis.SchedulerOption{LeaderTtl: 10 * time.Second})
+
+	if isLeader, err := first.Elect(); err != nil || !isLeader {
+		t.Fatalf("first Elect = %v, %v", isLeader, err)
+	}
+
+	if isLeader, _ := second.Elect(); isLeader {
+		t.Error("second became leader while first holds the lock")
+	}
+
+	fake.Clock().Advance(11 * time.Second)
+
+	if isLeader, _ := second.Elect(); !isLeader {
+		t.Error("second did not take over after the lock expired")
+	}
+
+	if err := second.Resign(); err != nil {
+		t.Fatal(err)
+	}
+
+	if isLeader, _ := first.Elect(); !isLeader {
+		t.Error("first did not become leader after second resigned")
+	}
+}
+
+func TestSchedulerServerTime(t *testing.T) {
+	// 服务器时钟比客户端慢一小时，执行时间仍以服务器时间为准
+	fake := gredistest.NewFake(gredistest.FakeOption{Clock: gredistest.NewClock(time.Now().Add(-time.Hour))})
+	scheduler := newTestScheduler(t, fake, gredis.SchedulerOption{})
+
+	if _, err := scheduler.ScheduleIn(time.Minute, "once"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := scheduler.ScheduleCron("@every 1m", "tick"); err != nil {
+		t.Fatal(err)
+	}
+
+	if jobs, _ := scheduler.Claim(); len(jobs) != 0 {
+		t.Fatalf("Claim before due = %v", jobs)
+	}
+
+	fake.Clock().Advance(2 * time.Minute)
+
+	if jobs, err := scheduler.Claim(); err != nil || len(jobs) != 2 {
+		t.Errorf("Claim after server time passed = %v, %v, want 2 jobs", jobs, err)
+	}
+}
+
+func TestSchedulerRunErrors(t *testing.T) {
+	server := newTestServer(t)
+
+	var failed []string
+	scheduler := gredis.NewScheduler(newServerRedis(t, server, gredis.RedisOption{}), "cron", gredis.SchedulerOption{
+		PollInterval: 10 * time.Millisecond,
+		OnError: func(job *gredis.ScheduledJob, err error) {
+			failed = append(failed, job.Id+": "+err.Error())
+		},
+	})
+
+	id, err := scheduler.ScheduleIn(0, "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// 处理函数的错误交给 OnError，任务保留并在 ClaimTimeout 后再次被领取
+	err = scheduler.Run(ctx, func(job *gredis.ScheduledJob) error {
+		return errors.New("boom")
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || len(failed) != 1 || failed[0] != id+": boom" {
+		t.Errorf("Run = %v, failed = %v", err, failed)
+	}
+
+	// Redis 出错时 Run 返回该错误
+	server.FailNext("", 1, "ERR boom")
+	if err := scheduler.Run(context.Background(), func(job *gredis.ScheduledJob) error { return nil }); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Run with failing Redis err = %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+package gredistest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+import (
+	lua "github.com/yuin/gopher-lua"
+)
+
+/* ================================================================================
+ * Scripting commands
+ * 基于 gopher-lua 执行 EVAL 脚本，脚本在引擎锁内执行，因此与 Redis 一样是原子的
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+func init() {
+	register("EVAL", -3, eval)
+	register("EVALSHA", -3, evalSha)
+	register("SCRIPT", -2, script)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * EVAL script numkeys [key ...] [arg ...]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func eval(c *commandContext, args [][]byte) interface{} {
+	src := string(args[0])
+	c.engine.scripts[scriptHash(src)] = src
+
+	return runScript(c, src, args[1:])
+}
+
+func evalSha(c *commandContext, args [][]byte) interface{} {
+	src, isOk := c.engine.scripts[strings.ToLower(string(args[0]))]
+	if !isOk {
+		panic(errorReply("NOSCRIPT No matching script. Please use EVAL."))
+	}
+
+	return runScript(c, src, args[1:])
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * SCRIPT LOAD | EXISTS | FLUSH
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func script(c *commandContext, args [][]byte) interface{} {
+	switch strings.ToUpper(string(args[0])) {
+	case "LOAD":
+		if len(args) != 2 {
+			panic(errorReply("ERR wrong number of arguments for 'script|load' command"))
+		}
+
+		src := string(args[1])
+
+		state := lua.NewState(lua.Options{SkipOpenLibs: true})
+		defer state.Close()
+
+		if _, err := state.LoadString(src); err != nil {
+			panic(errorReply("ERR Error compiling script (new function): " + err.Error()))
+		}
+
+		hash := scriptHash(src)
+		c.engine.scripts[hash] = src
+
+		return hash
+	case "EXISTS":
+		replies := make([]interface{}, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			_, isExists := c.engine.scripts[strings.ToLower(string(arg))]
+			replies = append(replies, isExists)
+		}
+
+		return replies
+	case "FLUSH":
+		c.engine.scripts = make(map[string]string)
+		return statusReply("OK")
+	}
+
+	panic(errorReply("ERR unknown subcommand '" + string(args[0]) + "'."))
+}
+
+func scriptHash(src string) string {
+	hash := sha1.Sum([]byte(src))
+	return hex.EncodeToString(hash[:])
+}
+
+func runScript(c *commandContext, src string, args [][]byte) interface{} {
+	numKeys := parseInt(args[0])
+	if numKeys < 0 {
+		panic(errorReply("ERR Number of keys can't be negative"))
+	}
+
+	if numKeys > int64(len(args)-1) {
+		panic(errorReply("ERR Number of keys can't be greater than number of args"))
+	}
+
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer state.Close()
+
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		state.Push(state.NewFunction(lib.open))
+		state.Push(lua.LString(lib.name))
+		state.Call(1, 0)
+	}
+
+	state.SetGlobal("KEYS", luaStrings(state, args[1:numKeys+1]))
+	state.SetGlobal("ARGV", luaStrings(state, args[numKeys+1:]))
+	state.SetGlobal("redis", luaRedis(state, c))
+
+	function, err := state.LoadString(src)
+	if err != nil {
+		panic(errorReply("ERR Error compiling script (new function): " + err.Error()))
+	}
+
+	state.Push(function)
+	if err := state.PCall(0, 1, nil); err != nil {
+		if apiError, isOk := err.(*lua.ApiError); isOk {
+			if table, isOk := apiError.Object.(*lua.LTable); isOk {
+				if message, isOk := table.RawGetString("err").(lua.LString); isOk {
+					panic(errorReply(message))
+				}
+			}
+		}
+
+		panic(errorReply("ERR Error running script: " + err.Error()))
+	}
+
+	return fromLua(state.Get(-1))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * redis.call | redis.pcall | redis.error_reply | redis.status_reply
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func luaRedis(state *lua.LState, c *commandContext) *lua.LTable {
+	call := func(isProtected bool) lua.LGFunction {
+		return func(state *lua.LState) int {
+			reply := luaCall(state, c)
+
+			if err, isError := reply.(errorReply); isError {
+				table := state.NewTable()
+				table.RawSetString("err", lua.LString(err))
+
+				if !isProtected {
+					state.Error(table, 1)
+				}
+
+				state.Push(table)
+				return 1
+			}
+
+			state.Push(toLua(state, reply))
+			return 1
+		}
+	}
+
+	reply := func(field string) lua.LGFunction {
+		return func(state *lua.LState) int {
+			table := state.NewTable()
+			table.RawSetString(field, lua.LString(state.CheckString(1)))
+			state.Push(table)
+			return 1
+		}
+	}
+
+	redis := state.NewTable()
+	redis.RawSetString("call", state.NewFunction(call(false)))
+	redis.RawSetString("pcall", state.NewFunction(call(true)))
+	redis.RawSetString("error_reply", state.NewFunction(reply("err")))
+	redis.RawSetString("status_reply", state.NewFunction(reply("ok")))
+
+	return redis
+}
+
+func luaCall(state *lua.LState, c *commandContext) interface{} {
+	top := state.GetTop()
+	if top == 0 {
+		return errorReply("ERR Please specify at least one argument for this redis lib call")
+	}
+
+	args := make([][]byte, 0, top)
+	for index := 1; index <= top; index++ {
+		switch value := state.Get(index).(type) {
+		case lua.LString:
+			args = append(args, []byte(value))
+		case lua.LNumber:
+			args = append(args, []byte(strconv.FormatFloat(float64(value), 'g', 14, 64)))
+		default:
+			return errorReply("ERR Lua redis lib command arguments must be strings or integers")
+		}
+	}
+
+	switch strings.ToUpper(string(args[0])) {
+	case "EVAL", "EVALSHA", "SCRIPT", "MULTI", "EXEC", "WATCH":
+		return errorReply("ERR This Redis command is not allowed from script")
+	}
+
+	cmd, err := lookupCommand(args)
+	if err != nil {
+		return err
+	}
+
+	reply := c.engine.call(c.session, cmd, args)
+	if block, isBlocked := reply.(blocked); isBlocked {
+		reply = block.reply
+	}
+
+	return reply
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 命令回复转换为 Lua 值：整数为 number，空回复为 false，状态回复为 {ok=...}
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func toLua(state *lua.LState, reply interface{}) lua.LValue {
+	switch value := reply.(type) {
+	case nil, nullArray:
+		return lua.LFalse
+	case statusReply:
+		table := state.NewTable()
+		table.RawSetString("ok", lua.LString(value))
+		return table
+	case int64:
+		return lua.LNumber(value)
+	case int:
+		return lua.LNumber(value)
+	case bool:
+		if value {
+			return lua.LNumber(1)
+		}
+		return lua.LNumber(0)
+	case float64:
+		return lua.LString(formatFloat(value))
+	case string:
+		return lua.LString(value)
+	case []byte:
+		return lua.LString(value)
+	case []interface{}:
+		table := state.NewTable()
+		for _, item := range value {
+			table.Append(toLua(state, item))
+		}
+		return table
+	case mapReply:
+		return toLua(state, []interface{}(value))
+	case setReply:
+		return toLua(state, []interface{}(value))
+	case pairsReply:
+		return toLua(state, []interface{}(value))
+	}
+
+	return lua.LFalse
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * Lua 返回值转换为命令回复：number 截断为整数，false 为空回复，true 为1，
+ * 表按数组转换直到第一个 nil，{ok=...} | {err=...} 为状态及错误回复
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func fromLua(value lua.LValue) interface{} {
+	switch value := value.(type) {
+	case lua.LNumber:
+		return int64(value)
+	case lua.LString:
+		return string(value)
+	case lua.LBool:
+		if value {
+			return int64(1)
+		}
+		return nil
+	case *lua.LTable:
+		if message, isOk := value.RawGetString("err").(lua.LString); isOk {
+			return errorReply(message)
+		}
+
+		if message, isOk := value.RawGetString("ok").(lua.LString); isOk {
+			return statusReply(message)
+		}
+
+		replies := []interface{}{}
+		for index := 1; ; index++ {
+			item := value.RawGetInt(index)
+			if item == lua.LNil {
+				break
+			}
+			replies = append(replies, fromLua(item))
+		}
+
+		return replies
+	}
+
+	return nil
+}
+
+func luaStrings(state *lua.LState, values [][]byte) *lua.LTable {
+	table := state.NewTable()
+	for _, value := range values {
+		table.Append(lua.LString(value))
+	}
+
+	return table
+}
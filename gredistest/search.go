@@ -0,0 +1,1643 @@
+package gredistest
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/* ================================================================================
+ * RediSearch commands
+ * 支持 FT.CREATE | FT.SEARCH | FT.AGGREGATE | FT.DROPINDEX | FT.INFO，仅索引哈希（ON HASH），
+ * 查询时按前缀扫描全部哈希，不维护倒排索引
+ * 查询语法支持：词项（含前缀 term*）| 短语 | @text:(...) | @tag:{a | b} | @num:[min max] |
+ * @geo:[lon lat radius unit] | 交集 | 并集 | 否定 | 括号 | $param | =>[KNN k @vec $blob AS alias]
+ * 与真实 RediSearch 的差异：不做词干提取及停用词过滤，文本相关度为词频乘以字段权重，
+ * 相同分数按键名排序；GROUPBY 的分组按首次出现的顺序返回
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	searchSpecialChars string = "()|{}[]@\"-=:,.<>;!#%^&~+/'\\"
+)
+
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 搜索索引，prefixes 为空时索引全部哈希
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	searchIndex struct {
+		name     string
+		prefixes []string
+		fields   []*searchField
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 索引字段，identifier 为哈希字段名，attribute 为查询中使用的名称（AS 别名）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	searchField struct {
+		identifier      string
+		attribute       string
+		kind            string
+		weight          float64
+		separator       string
+		isSortable      bool
+		isNoIndex       bool
+		isNoStem        bool
+		isCaseSensitive bool
+		algorithm       string
+		dataType        string
+		dim             int
+		distance        string
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 待匹配的文档，extras 为查询生成的字段（如 KNN 距离别名）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	searchDocument struct {
+		key    string
+		hash   *hashValue
+		score  float64
+		extras map[string]string
+	}
+
+	searchNode interface {
+		match(index *searchIndex, document *searchDocument) bool
+	}
+
+	searchAll struct{}
+
+	searchTerm struct {
+		fields   []*searchField
+		term     string
+		isPrefix bool
+	}
+
+	searchTag struct {
+		field  *searchField
+		values []string
+	}
+
+	searchNumeric struct {
+		field          *searchField
+		min            float64
+		max            float64
+		isMinExclusive bool
+		isMaxExclusive bool
+	}
+
+	searchGeo struct {
+		field     *searchField
+		longitude float64
+		latitude  float64
+		radius    float64
+	}
+
+	searchAnd []searchNode
+
+	searchOr []searchNode
+
+	searchNot struct {
+		node searchNode
+	}
+
+	searchKnn struct {
+		k      int
+		field  *searchField
+		vector []float64
+		alias  string
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 查询解析器，terms 为参与相关度计算的词项（不含否定分支）
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	searchParser struct {
+		index    *searchIndex
+		params   map[string]string
+		text     string
+		position int
+		terms    []*searchTerm
+		isNot    bool
+	}
+
+	aggregateRow struct {
+		document *searchDocument
+		fields   []string
+		values   map[string]string
+	}
+
+	aggregateReducer struct {
+		function string
+		field    string
+		alias    string
+	}
+)
+
+func init() {
+	register("FT.CREATE", -5, ftCreate)
+	register("FT.SEARCH", -3, ftSearch)
+	register("FT.AGGREGATE", -3, ftAggregate)
+	register("FT.DROPINDEX", -2, ftDropIndex)
+	register("FT.INFO", 2, ftInfo)
+}
+
+func (c *commandContext) searchIndex(name string) *searchIndex {
+	index, isExists := c.db.indexes[name]
+	if !isExists {
+		panic(errNoIndex)
+	}
+
+	return index
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按属性名或哈希字段名查找索引字段
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (index *searchIndex) field(name string) *searchField {
+	for _, field := range index.fields {
+		if field.attribute == name {
+			return field
+		}
+	}
+
+	for _, field := range index.fields {
+		if field.identifier == name {
+			return field
+		}
+	}
+
+	return nil
+}
+
+func (index *searchIndex) textFields() []*searchField {
+	fields := make([]*searchField, 0)
+	for _, field := range index.fields {
+		if field.kind == "TEXT" && !field.isNoIndex {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取索引覆盖的全部文档，按键名排序
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) searchDocuments(index *searchIndex) []*searchDocument {
+	keys := make([]string, 0)
+	for key := range c.db.keys {
+		if index.isCover(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	documents := make([]*searchDocument, 0, len(keys))
+	for _, key := range keys {
+		item := c.lookup(key)
+		if item == nil {
+			continue
+		}
+
+		if _, isHash := item.value.(*hashValue); !isHash {
+			continue
+		}
+
+		if hash := c.hashValue(key, false); hash != nil {
+			documents = append(documents, &searchDocument{key: key, hash: hash, extras: make(map[string]string)})
+		}
+	}
+
+	return documents
+}
+
+func (index *searchIndex) isCover(key string) bool {
+	if len(index.prefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range index.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取文档字段值，name 为属性名、哈希字段名或查询生成的字段名
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (d *searchDocument) value(index *searchIndex, name string) (string, bool) {
+	if value, isExists := d.extras[name]; isExists {
+		return value, true
+	}
+
+	if field := index.field(name); field != nil {
+		name = field.identifier
+	}
+
+	value, isExists := d.hash.values[name]
+
+	return string(value), isExists
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 文本分词：按非字母数字字符切分并转为小写
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func searchTokens(value string) []string {
+	return strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+func (t *searchTerm) count(index *searchIndex, document *searchDocument) float64 {
+	score := float64(0)
+	for _, field := range t.fields {
+		value, isExists := document.value(index, field.identifier)
+		if !isExists {
+			continue
+		}
+
+		for _, token := range searchTokens(value) {
+			if token == t.term || (t.isPrefix && strings.HasPrefix(token, t.term)) {
+				score += field.weight
+			}
+		}
+	}
+
+	return score
+}
+
+func (searchAll) match(index *searchIndex, document *searchDocument) bool {
+	return true
+}
+
+func (t *searchTerm) match(index *searchIndex, document *searchDocument) bool {
+	return t.count(index, document) > 0
+}
+
+func (t *searchTag) match(index *searchIndex, document *searchDocument) bool {
+	value, isExists := document.value(index, t.field.identifier)
+	if !isExists {
+		return false
+	}
+
+	for _, item := range strings.Split(value, t.field.separator) {
+		item = strings.TrimSpace(item)
+		for _, tag := range t.values {
+			if item == tag || (!t.field.isCaseSensitive && strings.EqualFold(item, tag)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (n *searchNumeric) match(index *searchIndex, document *searchDocument) bool {
+	value, isExists := document.value(index, n.field.identifier)
+	if !isExists {
+		return false
+	}
+
+	number, err := parseFloatString(value)
+	if err != nil {
+		return false
+	}
+
+	if number < n.min || (n.isMinExclusive && number == n.min) {
+		return false
+	}
+
+	return number < n.max || (!n.isMaxExclusive && number == n.max)
+}
+
+func (g *searchGeo) match(index *searchIndex, document *searchDocument) bool {
+	value, isExists := document.value(index, g.field.identifier)
+	if !isExists {
+		return false
+	}
+
+	longitude, latitude, isOk := parseSearchGeo(value)
+
+	return isOk && geoDistance(g.longitude, g.latitude, longitude, latitude) <= g.radius
+}
+
+func (a searchAnd) match(index *searchIndex, document *searchDocument) bool {
+	for _, node := range a {
+		if !node.match(index, document) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (o searchOr) match(index *searchIndex, document *searchDocument) bool {
+	for _, node := range o {
+		if node.match(index, document) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *searchNot) match(index *searchIndex, document *searchDocument) bool {
+	return !n.node.match(index, document)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 地理位置字段值格式为 "longitude,latitude"
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func parseSearchGeo(value string) (float64, float64, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return longitude, latitude, true
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解码小端序的 FLOAT32 | FLOAT64 向量，长度与维度不符时返回 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func decodeSearchVector(field *searchField, data []byte) []float64 {
+	size := 4
+	if field.dataType == "FLOAT64" {
+		size = 8
+	}
+
+	if len(data) != field.dim*size {
+		return nil
+	}
+
+	vector := make([]float64, field.dim)
+	for index := range vector {
+		if size == 4 {
+			vector[index] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[index*4:])))
+		} else {
+			vector[index] = math.Float64frombits(binary.LittleEndian.Uint64(data[index*8:]))
+		}
+	}
+
+	return vector
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 向量距离：L2 为欧氏距离的平方，IP 为 1 - 内积，COSINE 为 1 - 余弦相似度
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func searchVectorDistance(metric string, a, b []float64) float64 {
+	dot, normA, normB, squared := float64(0), float64(0), float64(0), float64(0)
+	for index := range a {
+		dot += a[index] * b[index]
+		normA += a[index] * a[index]
+		normB += b[index] * b[index]
+		squared += (a[index] - b[index]) * (a[index] - b[index])
+	}
+
+	switch metric {
+	case "L2":
+		return squared
+	case "IP":
+		return 1 - dot
+	}
+
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+
+	return 1 - dot/math.Sqrt(normA*normB)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析查询：query [=>[KNN k @field $blob [AS alias]]]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *searchParser) parse() (searchNode, *searchKnn) {
+	node := p.parseUnion(nil)
+
+	var knn *searchKnn
+	p.skipSpace()
+	if strings.HasPrefix(p.text[p.position:], "=>") {
+		p.position += 2
+		p.skipSpace()
+		knn = p.parseKnn()
+		p.skipSpace()
+	}
+
+	if p.position < len(p.text) {
+		p.fail()
+	}
+
+	return node, knn
+}
+
+func (p *searchParser) fail() {
+	panic(errorReply("Syntax error at offset " + strconv.Itoa(p.position) + " near " + p.text[p.position:]))
+}
+
+func (p *searchParser) skipSpace() {
+	for p.position < len(p.text) && unicode.IsSpace(rune(p.text[p.position])) {
+		p.position++
+	}
+}
+
+func (p *searchParser) peek() byte {
+	if p.position >= len(p.text) {
+		return 0
+	}
+
+	return p.text[p.position]
+}
+
+func (p *searchParser) expect(char byte) {
+	p.skipSpace()
+	if p.peek() != char {
+		p.fail()
+	}
+
+	p.position++
+}
+
+func (p *searchParser) parseUnion(fields []*searchField) searchNode {
+	nodes := searchOr{p.parseIntersect(fields)}
+	for {
+		p.skipSpace()
+		if p.peek() != '|' {
+			break
+		}
+
+		p.position++
+		nodes = append(nodes, p.parseIntersect(fields))
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+
+	return nodes
+}
+
+func (p *searchParser) parseIntersect(fields []*searchField) searchNode {
+	nodes := searchAnd{}
+	for {
+		p.skipSpace()
+		if char := p.peek(); char == 0 || char == ')' || char == '|' || strings.HasPrefix(p.text[p.position:], "=>") {
+			break
+		}
+
+		nodes = append(nodes, p.parseUnary(fields))
+	}
+
+	if len(nodes) == 0 {
+		p.fail()
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+
+	return nodes
+}
+
+func (p *searchParser) parseUnary(fields []*searchField) searchNode {
+	if p.peek() != '-' {
+		return p.parseAtom(fields)
+	}
+
+	p.position++
+
+	isNot := p.isNot
+	p.isNot = true
+	node := p.parseUnary(fields)
+	p.isNot = isNot
+
+	return &searchNot{node: node}
+}
+
+func (p *searchParser) parseAtom(fields []*searchField) searchNode {
+	switch p.peek() {
+	case '*':
+		p.position++
+		return searchAll{}
+	case '(':
+		p.position++
+		node := p.parseUnion(fields)
+		p.expect(')')
+		return node
+	case '"':
+		return p.parsePhrase(fields)
+	case '@':
+		p.position++
+		return p.parseField()
+	}
+
+	return p.newTerm(fields, p.readTerm())
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 短语按全部词项的交集匹配，不校验词项的相邻顺序
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *searchParser) parsePhrase(fields []*searchField) searchNode {
+	p.position++
+
+	end := strings.IndexByte(p.text[p.position:], '"')
+	if end < 0 {
+		p.fail()
+	}
+
+	words := searchTokens(p.text[p.position : p.position+end])
+	p.position += end + 1
+
+	if len(words) == 0 {
+		p.fail()
+	}
+
+	nodes := searchAnd{}
+	for _, word := range words {
+		nodes = append(nodes, p.newTerm(fields, word))
+	}
+
+	return nodes
+}
+
+func (p *searchParser) parseField() searchNode {
+	start := p.position
+	for p.position < len(p.text) && (unicode.IsLetter(rune(p.text[p.position])) || unicode.IsDigit(rune(p.text[p.position])) || p.text[p.position] == '_') {
+		p.position++
+	}
+
+	name := p.text[start:p.position]
+	field := p.index.field(name)
+	if field == nil {
+		panic(errorReply("Unknown field at offset " + strconv.Itoa(start) + " near " + name))
+	}
+
+	p.expect(':')
+	p.skipSpace()
+
+	switch p.peek() {
+	case '{':
+		if field.kind != "TAG" {
+			p.fail()
+		}
+		return p.parseTag(field)
+	case '[':
+		switch field.kind {
+		case "NUMERIC":
+			return p.parseNumeric(field)
+		case "GEO":
+			return p.parseGeo(field)
+		}
+		p.fail()
+	case '(':
+		if field.kind != "TEXT" {
+			p.fail()
+		}
+
+		p.position++
+		node := p.parseUnion([]*searchField{field})
+		p.expect(')')
+		return node
+	}
+
+	if field.kind != "TEXT" {
+		p.fail()
+	}
+
+	if p.peek() == '"' {
+		return p.parsePhrase([]*searchField{field})
+	}
+
+	return p.newTerm([]*searchField{field}, p.readTerm())
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 读取词项，支持反斜杠转义，以 $ 开头时替换为参数值
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *searchParser) readTerm() string {
+	term := make([]byte, 0)
+	for p.position < len(p.text) {
+		char := p.text[p.position]
+		if char == '\\' && p.position+1 < len(p.text) {
+			term = append(term, p.text[p.position+1])
+			p.position += 2
+			continue
+		}
+
+		if unicode.IsSpace(rune(char)) || strings.IndexByte(searchSpecialChars, char) >= 0 {
+			break
+		}
+
+		term = append(term, char)
+		p.position++
+	}
+
+	if len(term) == 0 {
+		p.fail()
+	}
+
+	return p.param(string(term))
+}
+
+func (p *searchParser) param(value string) string {
+	if !strings.HasPrefix(value, "$") {
+		return value
+	}
+
+	param, isExists := p.params[value[1:]]
+	if !isExists {
+		panic(errorReply("No such parameter `" + value[1:] + "`"))
+	}
+
+	return param
+}
+
+func (p *searchParser) newTerm(fields []*searchField, text string) searchNode {
+	if fields == nil {
+		fields = p.index.textFields()
+	}
+
+	term := &searchTerm{fields: fields, term: strings.ToLower(text)}
+	if strings.HasSuffix(term.term, "*") {
+		term.term = strings.TrimSuffix(term.term, "*")
+		term.isPrefix = true
+	}
+
+	if !p.isNot {
+		p.terms = append(p.terms, term)
+	}
+
+	return term
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 读取 [...] 的原始内容，不替换参数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *searchParser) readBracket() string {
+	p.position++
+	return p.readUntil(']')
+}
+
+func (p *searchParser) readUntil(char byte) string {
+	start := p.position
+	for p.position < len(p.text) && p.text[p.position] != char {
+		if p.text[p.position] == '\\' {
+			p.position++
+		}
+		p.position++
+	}
+
+	if p.position >= len(p.text) {
+		p.fail()
+	}
+
+	content := p.text[start:p.position]
+	p.position++
+
+	return content
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * @field:{tag | tag ...}
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *searchParser) parseTag(field *searchField) searchNode {
+	p.position++
+	content := p.readUntil('}')
+
+	node := &searchTag{field: field}
+
+	value, isEscaped := make([]byte, 0), false
+	for index := 0; index <= len(content); index++ {
+		if index == len(content) || (content[index] == '|' && !isEscaped) {
+			if tag := strings.TrimSpace(string(value)); len(tag) > 0 {
+				node.values = append(node.values, p.param(tag))
+			}
+			value = value[:0]
+			continue
+		}
+
+		if content[index] == '\\' && !isEscaped {
+			isEscaped = true
+			continue
+		}
+
+		value = append(value, content[index])
+		isEscaped = false
+	}
+
+	if len(node.values) == 0 {
+		p.fail()
+	}
+
+	return node
+}
+
+func (p *searchParser) bracketParts() []string {
+	parts := strings.Fields(p.readBracket())
+	for index, part := range parts {
+		parts[index] = p.param(part)
+	}
+
+	return parts
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * @field:[min max]，( 前缀表示开区间，支持 -inf | +inf
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *searchParser) parseNumeric(field *searchField) searchNode {
+	parts := p.bracketParts()
+	if len(parts) != 2 {
+		p.fail()
+	}
+
+	node := &searchNumeric{field: field}
+
+	var err error
+	if node.min, node.isMinExclusive, err = parseSearchBound(parts[0]); err != nil {
+		panic(errorReply("Bad lower range: " + parts[0]))
+	}
+
+	if node.max, node.isMaxExclusive, err = parseSearchBound(parts[1]); err != nil {
+		panic(errorReply("Bad upper range: " + parts[1]))
+	}
+
+	return node
+}
+
+func parseSearchBound(value string) (float64, bool, error) {
+	isExclusive := strings.HasPrefix(value, "(")
+	number, err := parseFloatString(strings.TrimPrefix(value, "("))
+
+	return number, isExclusive, err
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * @field:[longitude latitude radius m | km | mi | ft]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *searchParser) parseGeo(field *searchField) searchNode {
+	parts := p.bracketParts()
+	if len(parts) != 4 {
+		p.fail()
+	}
+
+	node := &searchGeo{field: field}
+	node.longitude, node.latitude = parseGeoPair([]byte(parts[0]), []byte(parts[1]))
+	node.radius = parseFloat([]byte(parts[2])) * parseGeoUnit([]byte(parts[3]))
+
+	return node
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * [KNN k @field $blob [EF_RUNTIME n] [AS alias]]，别名默认为 __{field}_score
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (p *searchParser) parseKnn() *searchKnn {
+	if p.peek() != '[' {
+		p.fail()
+	}
+
+	parts := strings.Fields(p.readBracket())
+	if len(parts) < 4 || !strings.EqualFold(parts[0], "KNN") || !strings.HasPrefix(parts[2], "@") || !strings.HasPrefix(parts[3], "$") {
+		p.fail()
+	}
+
+	k, err := strconv.Atoi(p.param(parts[1]))
+	if err != nil || k < 0 {
+		panic(errorReply("Invalid K value"))
+	}
+
+	field := p.index.field(parts[2][1:])
+	if field == nil || field.kind != "VECTOR" {
+		panic(errorReply("Expected a VECTOR field at offset " + strconv.Itoa(p.position)))
+	}
+
+	knn := &searchKnn{k: k, field: field, alias: "__" + parts[2][1:] + "_score"}
+	if knn.vector = decodeSearchVector(field, []byte(p.param(parts[3]))); knn.vector == nil {
+		panic(errorReply("Error parsing vector similarity query: query vector blob size does not match index's expected size."))
+	}
+
+	for index := 4; index < len(parts); index += 2 {
+		if index+1 >= len(parts) {
+			p.fail()
+		}
+
+		switch strings.ToUpper(parts[index]) {
+		case "AS":
+			knn.alias = parts[index+1]
+		case "EF_RUNTIME", "EPSILON":
+		default:
+			p.fail()
+		}
+	}
+
+	return knn
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行查询，返回匹配的文档：KNN 按距离升序取前 k 个，否则按相关度降序
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) searchQuery(index *searchIndex, query string, params map[string]string) ([]*searchDocument, *searchKnn) {
+	parser := &searchParser{index: index, params: params, text: query}
+	node, knn := parser.parse()
+
+	documents := make([]*searchDocument, 0)
+	for _, document := range c.searchDocuments(index) {
+		if !node.match(index, document) {
+			continue
+		}
+
+		document.score = 0
+		for _, term := range parser.terms {
+			document.score += term.count(index, document)
+		}
+
+		if len(parser.terms) == 0 {
+			document.score = 1
+		}
+
+		documents = append(documents, document)
+	}
+
+	if knn == nil {
+		sort.SliceStable(documents, func(i, j int) bool {
+			return documents[i].score > documents[j].score
+		})
+
+		return documents, nil
+	}
+
+	distances := make(map[*searchDocument]float64)
+	candidates := make([]*searchDocument, 0, len(documents))
+	for _, document := range documents {
+		vector := decodeSearchVector(knn.field, document.hash.values[knn.field.identifier])
+		if vector == nil {
+			continue
+		}
+
+		distances[document] = searchVectorDistance(knn.field.distance, knn.vector, vector)
+		candidates = append(candidates, document)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distances[candidates[i]] < distances[candidates[j]]
+	})
+
+	if len(candidates) > knn.k {
+		candidates = candidates[:knn.k]
+	}
+
+	for _, document := range candidates {
+		document.extras[knn.alias] = strconv.FormatFloat(distances[document], 'g', -1, 32)
+	}
+
+	return candidates, knn
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 比较字段值：均为数值时按数值比较，否则按字符串比较，缺失值排在最后
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func compareSearchValues(a string, isExistsA bool, b string, isExistsB bool) int {
+	if !isExistsA || !isExistsB {
+		switch {
+		case isExistsA:
+			return -1
+		case isExistsB:
+			return 1
+		}
+		return 0
+	}
+
+	numberA, errA := parseFloatString(a)
+	numberB, errB := parseFloatString(b)
+	if errA == nil && errB == nil {
+		switch {
+		case numberA < numberB:
+			return -1
+		case numberA > numberB:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(a, b)
+}
+
+func parseSearchParams(args [][]byte, index int) (map[string]string, int) {
+	if index+1 >= len(args) {
+		panic(errSyntax)
+	}
+
+	count := int(parseInt(args[index+1]))
+	if count < 0 || count%2 != 0 || index+2+count > len(args) {
+		panic(errorReply("Parameters must be specified in PARAM VALUE pairs"))
+	}
+
+	params := make(map[string]string)
+	for offset := index + 2; offset < index+2+count; offset += 2 {
+		params[string(args[offset])] = string(args[offset+1])
+	}
+
+	return params, index + 2 + count
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * FT.CREATE index [ON HASH] [PREFIX count prefix ...] [LANGUAGE language] [STOPWORDS count word ...]
+ * SCHEMA field [AS alias] TEXT [WEIGHT weight] [NOSTEM] | TAG [SEPARATOR sep] [CASESENSITIVE] |
+ * NUMERIC | GEO | VECTOR FLAT | HNSW count TYPE type DIM dim DISTANCE_METRIC metric ... [SORTABLE] [NOINDEX] ...
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func ftCreate(c *commandContext, args [][]byte) interface{} {
+	name := string(args[0])
+	if _, isExists := c.db.indexes[name]; isExists {
+		panic(errIndexExists)
+	}
+
+	index := &searchIndex{name: name}
+
+	position := 1
+	for ; position < len(args) && !isOption(args[position], "SCHEMA"); position++ {
+		switch {
+		case isOption(args[position], "ON") && position+1 < len(args):
+			if !isOption(args[position+1], "HASH") {
+				panic(errorReply("ERR only ON HASH indexes are supported"))
+			}
+			position++
+		case (isOption(args[position], "PREFIX") || isOption(args[position], "STOPWORDS")) && position+1 < len(args):
+			count := int(parseInt(args[position+1]))
+			if count < 0 || position+1+count >= len(args) {
+				panic(errSyntax)
+			}
+
+			if isOption(args[position], "PREFIX") {
+				for _, prefix := range args[position+2 : position+2+count] {
+					index.prefixes = append(index.prefixes, string(prefix))
+				}
+			}
+			position += 1 + count
+		case (isOption(args[position], "LANGUAGE") || isOption(args[position], "SCORE")) && position+1 < len(args):
+			position++
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	if position >= len(args)-1 {
+		panic(errorReply("Fields arguments are missing"))
+	}
+
+	for position++; position < len(args); {
+		field := &searchField{identifier: string(args[position]), weight: 1, separator: ","}
+		field.attribute = field.identifier
+		position++
+
+		if position+1 < len(args) && isOption(args[position], "AS") {
+			field.attribute = string(args[position+1])
+			position += 2
+		}
+
+		if position >= len(args) {
+			panic(errorReply("Field `" + field.identifier + "` does not have a type"))
+		}
+
+		field.kind = strings.ToUpper(string(args[position]))
+		position++
+
+		switch field.kind {
+		case "TEXT", "TAG", "NUMERIC", "GEO":
+		case "VECTOR":
+			position = parseSearchVectorField(field, args, position)
+		default:
+			panic(errorReply("Invalid field type for field `" + field.identifier + "`"))
+		}
+
+		position = parseSearchFieldOptions(field, args, position)
+
+		if index.field(field.attribute) != nil {
+			panic(errorReply("Duplicate field in schema - " + field.attribute))
+		}
+
+		index.fields = append(index.fields, field)
+	}
+
+	c.db.indexes[name] = index
+
+	return statusReply("OK")
+}
+
+func parseSearchVectorField(field *searchField, args [][]byte, position int) int {
+	if position+1 >= len(args) {
+		panic(errSyntax)
+	}
+
+	field.algorithm = strings.ToUpper(string(args[position]))
+	if field.algorithm != "FLAT" && field.algorithm != "HNSW" {
+		panic(errorReply("Bad arguments for vector similarity algorithm"))
+	}
+
+	count := int(parseInt(args[position+1]))
+	position += 2
+	if count < 0 || count%2 != 0 || position+count > len(args) {
+		panic(errorReply("Bad arguments for vector similarity number of parameters"))
+	}
+
+	for offset := position; offset < position+count; offset += 2 {
+		value := strings.ToUpper(string(args[offset+1]))
+		switch {
+		case isOption(args[offset], "TYPE"):
+			if value != "FLOAT32" && value != "FLOAT64" {
+				panic(errorReply("Bad arguments for vector similarity FLAT index type"))
+			}
+			field.dataType = value
+		case isOption(args[offset], "DIM"):
+			field.dim = int(parseInt(args[offset+1]))
+		case isOption(args[offset], "DISTANCE_METRIC"):
+			if value != "L2" && value != "IP" && value != "COSINE" {
+				panic(errorReply("Bad arguments for vector similarity metric"))
+			}
+			field.distance = value
+		}
+	}
+
+	if len(field.dataType) == 0 || field.dim <= 0 || len(field.distance) == 0 {
+		panic(errorReply("Missing mandatory parameter: cannot create FLAT index without specifying TYPE, DIM and DISTANCE_METRIC"))
+	}
+
+	return position + count
+}
+
+func parseSearchFieldOptions(field *searchField, args [][]byte, position int) int {
+	for position < len(args) {
+		switch {
+		case isOption(args[position], "SORTABLE"):
+			field.isSortable = true
+		case isOption(args[position], "NOINDEX"):
+			field.isNoIndex = true
+		case isOption(args[position], "NOSTEM") && field.kind == "TEXT":
+			field.isNoStem = true
+		case isOption(args[position], "CASESENSITIVE") && field.kind == "TAG":
+			field.isCaseSensitive = true
+		case isOption(args[position], "UNF") || isOption(args[position], "WITHSUFFIXTRIE") || isOption(args[position], "INDEXEMPTY") || isOption(args[position], "INDEXMISSING"):
+		case isOption(args[position], "WEIGHT") && field.kind == "TEXT" && position+1 < len(args):
+			field.weight = parseFloat(args[position+1])
+			position++
+		case isOption(args[position], "SEPARATOR") && field.kind == "TAG" && position+1 < len(args):
+			if len(args[position+1]) != 1 {
+				panic(errorReply("Tag separator must be a single character"))
+			}
+			field.separator = string(args[position+1])
+			position++
+		case isOption(args[position], "PHONETIC") && field.kind == "TEXT" && position+1 < len(args):
+			position++
+		default:
+			return position
+		}
+
+		position++
+	}
+
+	return position
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * FT.SEARCH index query [NOCONTENT] [WITHSCORES] [RETURN count field ...]
+ * [SORTBY field [ASC | DESC]] [LIMIT offset num] [PARAMS count name value ...] [DIALECT dialect]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func ftSearch(c *commandContext, args [][]byte) interface{} {
+	index := c.searchIndex(string(args[0]))
+
+	isNoContent, isWithScores := false, false
+	returns := []string(nil)
+	sortBy, isDesc := "", false
+	offset, limit := 0, 10
+	params := map[string]string{}
+
+	for position := 2; position < len(args); {
+		switch {
+		case isOption(args[position], "NOCONTENT"):
+			isNoContent = true
+			position++
+		case isOption(args[position], "WITHSCORES"):
+			isWithScores = true
+			position++
+		case isOption(args[position], "VERBATIM") || isOption(args[position], "NOSTOPWORDS"):
+			position++
+		case isOption(args[position], "RETURN") && position+1 < len(args):
+			count := int(parseInt(args[position+1]))
+			if count < 0 || position+2+count > len(args) {
+				panic(errSyntax)
+			}
+
+			returns = make([]string, 0, count)
+			for _, field := range args[position+2 : position+2+count] {
+				returns = append(returns, string(field))
+			}
+			position += 2 + count
+		case isOption(args[position], "SORTBY") && position+1 < len(args):
+			sortBy = string(args[position+1])
+			position += 2
+			if position < len(args) && (isOption(args[position], "ASC") || isOption(args[position], "DESC")) {
+				isDesc = isOption(args[position], "DESC")
+				position++
+			}
+		case isOption(args[position], "LIMIT") && position+2 < len(args):
+			offset, limit = int(parseInt(args[position+1])), int(parseInt(args[position+2]))
+			if offset < 0 || limit < 0 {
+				panic(errorReply("LIMIT exceeds maximum of 1000000"))
+			}
+			position += 3
+		case isOption(args[position], "PARAMS"):
+			params, position = parseSearchParams(args, position)
+		case isOption(args[position], "DIALECT") && position+1 < len(args):
+			parseInt(args[position+1])
+			position += 2
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	documents, knn := c.searchQuery(index, string(args[1]), params)
+
+	if len(sortBy) > 0 {
+		if index.field(sortBy) == nil && (knn == nil || knn.alias != sortBy) {
+			panic(errorReply("Property `" + sortBy + "` not loaded nor in schema"))
+		}
+
+		sort.SliceStable(documents, func(i, j int) bool {
+			valueI, isExistsI := documents[i].value(index, sortBy)
+			valueJ, isExistsJ := documents[j].value(index, sortBy)
+
+			result := compareSearchValues(valueI, isExistsI, valueJ, isExistsJ)
+			if isDesc && isExistsI && isExistsJ {
+				return result > 0
+			}
+
+			return result < 0
+		})
+	}
+
+	replies := []interface{}{int64(len(documents))}
+	for position := offset; position < len(documents) && position < offset+limit; position++ {
+		document := documents[position]
+		replies = append(replies, document.key)
+
+		if isWithScores {
+			replies = append(replies, formatFloat(document.score))
+		}
+
+		if isNoContent {
+			continue
+		}
+
+		fields := make([]interface{}, 0)
+		if returns == nil {
+			for _, name := range document.hash.fields {
+				fields = append(fields, name, document.hash.values[name])
+			}
+
+			extras := make([]string, 0, len(document.extras))
+			for name := range document.extras {
+				extras = append(extras, name)
+			}
+			sort.Strings(extras)
+
+			for _, name := range extras {
+				fields = append(fields, name, document.extras[name])
+			}
+		} else {
+			for _, name := range returns {
+				if value, isExists := document.value(index, name); isExists {
+					fields = append(fields, name, value)
+				}
+			}
+		}
+
+		replies = append(replies, fields)
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * FT.AGGREGATE index query [LOAD count field ... | LOAD *]
+ * [GROUPBY count field ... [REDUCE function count arg ... [AS name]] ...]
+ * [SORTBY count field [ASC | DESC] ... [MAX num]] [LIMIT offset num] [PARAMS ...] [DIALECT dialect]
+ * 归约函数支持 COUNT | COUNT_DISTINCT | SUM | AVG | MIN | MAX
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func ftAggregate(c *commandContext, args [][]byte) interface{} {
+	index := c.searchIndex(string(args[0]))
+
+	params := map[string]string{}
+	steps := make([]func(rows []*aggregateRow) []*aggregateRow, 0)
+
+	for position := 2; position < len(args); {
+		switch {
+		case isOption(args[position], "LOAD") && position+1 < len(args):
+			var fields []string
+			if string(args[position+1]) == "*" {
+				position += 2
+			} else {
+				count := int(parseInt(args[position+1]))
+				if count < 0 || position+2+count > len(args) {
+					panic(errSyntax)
+				}
+
+				fields = aggregateFields(args[position+2 : position+2+count])
+				position += 2 + count
+			}
+
+			steps = append(steps, aggregateLoad(index, fields))
+		case isOption(args[position], "GROUPBY") && position+1 < len(args):
+			count := int(parseInt(args[position+1]))
+			if count < 0 || position+2+count > len(args) {
+				panic(errSyntax)
+			}
+
+			fields := aggregateFields(args[position+2 : position+2+count])
+			position += 2 + count
+
+			reducers := make([]aggregateReducer, 0)
+			for position < len(args) && isOption(args[position], "REDUCE") {
+				var reducer aggregateReducer
+				reducer, position = parseAggregateReducer(args, position)
+				reducers = append(reducers, reducer)
+			}
+
+			steps = append(steps, aggregateGroupBy(index, fields, reducers))
+		case isOption(args[position], "SORTBY") && position+1 < len(args):
+			count := int(parseInt(args[position+1]))
+			if count <= 0 || position+2+count > len(args) {
+				panic(errSyntax)
+			}
+
+			keys := args[position+2 : position+2+count]
+			position += 2 + count
+
+			max := 0
+			if position+1 < len(args) && isOption(args[position], "MAX") {
+				max = int(parseInt(args[position+1]))
+				position += 2
+			}
+
+			steps = append(steps, aggregateSortBy(index, keys, max))
+		case isOption(args[position], "LIMIT") && position+2 < len(args):
+			offset, limit := int(parseInt(args[position+1])), int(parseInt(args[position+2]))
+			position += 3
+
+			steps = append(steps, func(rows []*aggregateRow) []*aggregateRow {
+				if offset >= len(rows) {
+					return rows[:0]
+				}
+
+				if offset+limit < len(rows) {
+					return rows[offset : offset+limit]
+				}
+
+				return rows[offset:]
+			})
+		case isOption(args[position], "PARAMS"):
+			params, position = parseSearchParams(args, position)
+		case isOption(args[position], "DIALECT") && position+1 < len(args):
+			parseInt(args[position+1])
+			position += 2
+		case isOption(args[position], "VERBATIM"):
+			position++
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	documents, _ := c.searchQuery(index, string(args[1]), params)
+
+	rows := make([]*aggregateRow, 0, len(documents))
+	for _, document := range documents {
+		rows = append(rows, &aggregateRow{document: document, values: make(map[string]string)})
+	}
+
+	for _, step := range steps {
+		rows = step(rows)
+	}
+
+	replies := []interface{}{int64(len(rows))}
+	for _, row := range rows {
+		fields := make([]interface{}, 0, len(row.fields)*2)
+		for _, name := range row.fields {
+			fields = append(fields, name, row.values[name])
+		}
+
+		replies = append(replies, fields)
+	}
+
+	return replies
+}
+
+func aggregateFields(args [][]byte) []string {
+	fields := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(string(arg), "@") {
+			panic(errorReply("Bad arguments for GROUPBY: Unknown property `" + string(arg) + "`. Did you mean `@" + string(arg) + "`?"))
+		}
+
+		fields = append(fields, string(arg)[1:])
+	}
+
+	return fields
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取行的字段值，未加载的字段从文档读取
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (r *aggregateRow) value(index *searchIndex, name string) (string, bool) {
+	if value, isExists := r.values[name]; isExists {
+		return value, true
+	}
+
+	if r.document == nil {
+		return "", false
+	}
+
+	return r.document.value(index, name)
+}
+
+func (r *aggregateRow) set(name, value string) {
+	if _, isExists := r.values[name]; !isExists {
+		r.fields = append(r.fields, name)
+	}
+
+	r.values[name] = value
+}
+
+func aggregateLoad(index *searchIndex, fields []string) func(rows []*aggregateRow) []*aggregateRow {
+	return func(rows []*aggregateRow) []*aggregateRow {
+		for _, row := range rows {
+			names := fields
+			if names == nil && row.document != nil {
+				names = row.document.hash.fields
+			}
+
+			for _, name := range names {
+				if value, isExists := row.value(index, name); isExists {
+					row.set(name, value)
+				}
+			}
+		}
+
+		return rows
+	}
+}
+
+func parseAggregateReducer(args [][]byte, position int) (aggregateReducer, int) {
+	if position+2 >= len(args) {
+		panic(errSyntax)
+	}
+
+	reducer := aggregateReducer{function: strings.ToUpper(string(args[position+1]))}
+
+	count := int(parseInt(args[position+2]))
+	position += 3
+	if count < 0 || position+count > len(args) {
+		panic(errSyntax)
+	}
+
+	switch reducer.function {
+	case "COUNT":
+		if count != 0 {
+			panic(errorReply("Bad arguments for COUNT: Count accepts 0 values only"))
+		}
+	case "COUNT_DISTINCT", "SUM", "AVG", "MIN", "MAX":
+		if count != 1 {
+			panic(errorReply("Bad arguments for " + reducer.function + ": Requires a single property"))
+		}
+		reducer.field = aggregateFields(args[position : position+1])[0]
+	default:
+		panic(errorReply("No such reducer `" + strings.ToLower(reducer.function) + "`"))
+	}
+
+	position += count
+
+	reducer.alias = "__generated_alias" + strings.ToLower(reducer.function) + strings.ToLower(reducer.field)
+	if position+1 < len(args) && isOption(args[position], "AS") {
+		reducer.alias = string(args[position+1])
+		position += 2
+	}
+
+	return reducer, position
+}
+
+func aggregateGroupBy(index *searchIndex, fields []string, reducers []aggregateReducer) func(rows []*aggregateRow) []*aggregateRow {
+	return func(rows []*aggregateRow) []*aggregateRow {
+		groups := make([]*aggregateRow, 0)
+		members := make(map[string][]*aggregateRow)
+
+		for _, row := range rows {
+			values := make([]string, len(fields))
+			for position, name := range fields {
+				values[position], _ = row.value(index, name)
+			}
+
+			groupKey := strings.Join(values, "\x00")
+			if _, isExists := members[groupKey]; !isExists {
+				group := &aggregateRow{values: make(map[string]string)}
+				for position, name := range fields {
+					group.set(name, values[position])
+				}
+
+				groups = append(groups, group)
+			}
+
+			members[groupKey] = append(members[groupKey], row)
+		}
+
+		for _, group := range groups {
+			values := make([]string, len(fields))
+			for position, name := range fields {
+				values[position] = group.values[name]
+			}
+
+			for _, reducer := range reducers {
+				group.set(reducer.alias, reducer.reduce(index, members[strings.Join(values, "\x00")]))
+			}
+		}
+
+		return groups
+	}
+}
+
+func (r aggregateReducer) reduce(index *searchIndex, rows []*aggregateRow) string {
+	if r.function == "COUNT" {
+		return strconv.Itoa(len(rows))
+	}
+
+	if r.function == "COUNT_DISTINCT" {
+		distinct := make(map[string]struct{})
+		for _, row := range rows {
+			if value, isExists := row.value(index, r.field); isExists {
+				distinct[value] = struct{}{}
+			}
+		}
+
+		return strconv.Itoa(len(distinct))
+	}
+
+	sum, count := float64(0), 0
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, row := range rows {
+		value, isExists := row.value(index, r.field)
+		if !isExists {
+			continue
+		}
+
+		number, err := parseFloatString(value)
+		if err != nil {
+			continue
+		}
+
+		sum += number
+		count++
+		min, max = math.Min(min, number), math.Max(max, number)
+	}
+
+	switch r.function {
+	case "AVG":
+		if count == 0 {
+			return "nan"
+		}
+		return formatFloat(sum / float64(count))
+	case "MIN":
+		return formatFloat(min)
+	case "MAX":
+		return formatFloat(max)
+	}
+
+	return formatFloat(sum)
+}
+
+func aggregateSortBy(index *searchIndex, keys [][]byte, max int) func(rows []*aggregateRow) []*aggregateRow {
+	fields, isDescs := make([]string, 0), make([]bool, 0)
+	for position := 0; position < len(keys); position++ {
+		fields = append(fields, aggregateFields(keys[position : position+1])[0])
+
+		isDesc := false
+		if position+1 < len(keys) && (isOption(keys[position+1], "ASC") || isOption(keys[position+1], "DESC")) {
+			isDesc = isOption(keys[position+1], "DESC")
+			position++
+		}
+		isDescs = append(isDescs, isDesc)
+	}
+
+	return func(rows []*aggregateRow) []*aggregateRow {
+		sort.SliceStable(rows, func(i, j int) bool {
+			for position, name := range fields {
+				valueI, isExistsI := rows[i].value(index, name)
+				valueJ, isExistsJ := rows[j].value(index, name)
+
+				result := compareSearchValues(valueI, isExistsI, valueJ, isExistsJ)
+				if result == 0 {
+					continue
+				}
+
+				if isDescs[position] && isExistsI && isExistsJ {
+					return result > 0
+				}
+
+				return result < 0
+			}
+
+			return false
+		})
+
+		if max > 0 && len(rows) > max {
+			rows = rows[:max]
+		}
+
+		return rows
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * FT.DROPINDEX index [DD]：DD 时一并删除索引覆盖的文档
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func ftDropIndex(c *commandContext, args [][]byte) interface{} {
+	index := c.searchIndex(string(args[0]))
+
+	if len(args) > 2 || (len(args) == 2 && !isOption(args[1], "DD")) {
+		panic(errSyntax)
+	}
+
+	if len(args) == 2 {
+		for _, document := range c.searchDocuments(index) {
+			c.remove(document.key)
+		}
+	}
+
+	delete(c.db.indexes, index.name)
+
+	return statusReply("OK")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * FT.INFO index：返回索引名称、定义、字段及文档数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func ftInfo(c *commandContext, args [][]byte) interface{} {
+	index := c.searchIndex(string(args[0]))
+
+	prefixes := make([]interface{}, 0, len(index.prefixes))
+	for _, prefix := range index.prefixes {
+		prefixes = append(prefixes, prefix)
+	}
+
+	if len(prefixes) == 0 {
+		prefixes = append(prefixes, "")
+	}
+
+	attributes := make([]interface{}, 0, len(index.fields))
+	for _, field := range index.fields {
+		attribute := []interface{}{"identifier", field.identifier, "attribute", field.attribute, "type", field.kind}
+
+		switch field.kind {
+		case "TEXT":
+			attribute = append(attribute, "WEIGHT", formatFloat(field.weight))
+			if field.isNoStem {
+				attribute = append(attribute, "NOSTEM")
+			}
+		case "TAG":
+			attribute = append(attribute, "SEPARATOR", field.separator)
+			if field.isCaseSensitive {
+				attribute = append(attribute, "CASESENSITIVE")
+			}
+		case "VECTOR":
+			attribute = append(attribute, "algorithm", field.algorithm, "data_type", field.dataType, "dim", int64(field.dim), "distance_metric", field.distance)
+		}
+
+		if field.isSortable {
+			attribute = append(attribute, "SORTABLE")
+		}
+
+		if field.isNoIndex {
+			attribute = append(attribute, "NOINDEX")
+		}
+
+		attributes = append(attributes, attribute)
+	}
+
+	return []interface{}{
+		"index_name", index.name,
+		"index_options", []interface{}{},
+		"index_definition", []interface{}{"key_type", "HASH", "prefixes", prefixes, "default_score", "1"},
+		"attributes", attributes,
+		"num_docs", int64(len(c.searchDocuments(index))),
+		"percent_indexed", "1",
+	}
+}
@@ -0,0 +1,169 @@
+package gredistest_test
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+type searchProduct struct {
+	Name      string  `redis:"name" search:"text,weight=2"`
+	Brand     string  `redis:"brand" search:"tag"`
+	Price     float64 `redis:"price" search:"numeric,sortable"`
+	Location  string  `redis:"location" search:"geo"`
+	Embedding []byte  `redis:"embedding" search:"vector,dim=2,distance=l2"`
+	Note      string  `redis:"note"`
+}
+
+func newSearchCatalog(t *testing.T) gredis.IRedis {
+	redis := newFakeRedis(t, gredistest.NewFake(), gredis.RedisOption{Prefix: "shop:"})
+
+	fields, err := gredis.SearchSchema(&searchProduct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := redis.FTCreate("products", fields, gredis.SearchIndexOption{Prefixes: []string{"product:"}}); err != nil {
+		t.Fatalf("FTCreate = %v", err)
+	}
+
+	products := map[string]searchProduct{
+		"product:1": {Name: "Apple iPhone phone", Brand: "apple", Price: 999, Location: "2.35,48.85", Embedding: gredis.EncodeVector([]float32{1, 0})},
+		"product:2": {Name: "Galaxy phone", Brand: "samsung", Price: 799, Location: "13.40,52.52", Embedding: gredis.EncodeVector([]float32{0.9, 0.1})},
+		"product:3": {Name: "MacBook laptop", Brand: "apple", Price: 1999, Location: "2.30,48.86", Embedding: gredis.EncodeVector([]float32{0, 1})},
+		"product:4": {Name: "Galaxy tablet", Brand: "samsung", Price: 499, Location: "-0.12,51.50", Embedding: gredis.EncodeVector([]float32{0.2, 0.8})},
+	}
+
+	for key, product := range products {
+		if err := redis.HSetData(product, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return redis
+}
+
+func TestSearchSchema(t *testing.T) {
+	fields, err := gredis.SearchSchema(searchProduct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []gredis.SearchField{
+		{Name: "name", Type: gredis.REDIS_SEARCH_FIELD_TEXT, Weight: 2},
+		{Name: "brand", Type: gredis.REDIS_SEARCH_FIELD_TAG},
+		{Name: "price", Type: gredis.REDIS_SEARCH_FIELD_NUMERIC, Sortable: true},
+		{Name: "location", Type: gredis.REDIS_SEARCH_FIELD_GEO},
+		{Name: "embedding", Type: gredis.REDIS_SEARCH_FIELD_VECTOR, Algorithm: gredis.REDIS_SEARCH_VECTOR_FLAT, Dim: 2, Distance: gredis.REDIS_SEARCH_DISTANCE_L2},
+	}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("SearchSchema = %+v, want %+v", fields, expected)
+	}
+
+	if _, err := gredis.SearchSchema(struct {
+		Embedding []byte `search:"vector"`
+	}{}); err == nil {
+		t.Error("SearchSchema of vector without dim err = nil")
+	}
+
+	query := gredis.NewSearchQuery().Text("wi-fi phone").Tag("brand", "apple", "b&o").NumericRange("price", math.Inf(-1), 1000)
+	if text := query.String(); text != `wi\-fi phone @brand:{apple | b\&o} @price:[-inf 1000]` {
+		t.Errorf("String = %q", text)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	redis := newSearchCatalog(t)
+
+	result, err := redis.FTSearch("products", gredis.NewSearchQuery().Text("phone").SortBy("price", gredis.REDIS_SEARCH_SORT_DESC))
+	if err != nil || result.Total != 2 || len(result.Documents) != 2 {
+		t.Fatalf("FTSearch = %+v, %v", result, err)
+	}
+
+	if result.Documents[0].Id != "product:1" || result.Documents[1].Id != "product:2" {
+		t.Errorf("FTSearch ids = %s, %s", result.Documents[0].Id, result.Documents[1].Id)
+	}
+
+	products, err := gredis.ScanDocuments[searchProduct](redis.FTSearch("products", gredis.NewSearchQuery().Tag("brand", "apple").NumericRange("price", 1000, math.Inf(1))))
+	if err != nil || len(products) != 1 || products[0].Name != "MacBook laptop" || products[0].Price != 1999 {
+		t.Errorf("ScanDocuments = %+v, %v", products, err)
+	}
+
+	nearby, err := redis.FTSearch("products", gredis.NewSearchQuery().Geo("location", 2.35, 48.85, 10, gredis.REDIS_GEO_UNIT_KM).NoContent())
+	if err != nil || nearby.Total != 2 || nearby.Documents[0].Fields != nil {
+		t.Errorf("FTSearch by geo = %+v, %v", nearby, err)
+	}
+
+	page, err := redis.FTSearch("products", gredis.NewSearchQuery().SortBy("price").Return("name").Limit(1, 2))
+	if err != nil || page.Total != 4 || len(page.Documents) != 2 || page.Documents[0].Fields["name"] != "Galaxy phone" || len(page.Documents[0].Fields) != 1 {
+		t.Errorf("FTSearch page = %+v, %v", page, err)
+	}
+
+	knn, err := redis.FTSearch("products", gredis.NewSearchQuery().Tag("brand", "samsung").Knn("embedding", 1, gredis.EncodeVector([]float32{1, 0})).Return("name", "__embedding_score"))
+	if err != nil || knn.Total != 1 || knn.Documents[0].Id != "product:2" {
+		t.Fatalf("FTSearch knn = %+v, %v", knn, err)
+	}
+
+	if score, err := strconv.ParseFloat(knn.Documents[0].Fields["__embedding_score"], 64); err != nil || math.Abs(score-0.02) > 1e-6 {
+		t.Errorf("knn score = %v, %v, want 0.02", score, err)
+	}
+
+	if _, err := redis.FTSearch("missing", nil); err == nil {
+		t.Error("FTSearch of missing index err = nil")
+	}
+}
+
+func TestSearchAggregate(t *testing.T) {
+	redis := newSearchCatalog(t)
+
+	query := gredis.NewAggregateQuery().
+		GroupBy([]string{"brand"}, gredis.ReduceCount("count"), gredis.ReduceAvg("price", "avg_price")).
+		SortBy("avg_price", gredis.REDIS_SEARCH_SORT_DESC)
+
+	rows, err := redis.FTAggregate("products", query)
+	expected := []map[string]string{
+		{"brand": "apple", "count": "2", "avg_price": "1499"},
+		{"brand": "samsung", "count": "2", "avg_price": "649"},
+	}
+
+	if err != nil || !reflect.DeepEqual(rows, expected) {
+		t.Errorf("FTAggregate = %v, %v", rows, err)
+	}
+
+	rows, err = redis.FTAggregate("products", gredis.NewAggregateQuery(gredis.NewSearchQuery().Text("galaxy")).Load("name").SortBy("name").Limit(0, 1))
+	if err != nil || len(rows) != 1 || rows[0]["name"] != "Galaxy phone" {
+		t.Errorf("FTAggregate with filter = %v, %v", rows, err)
+	}
+}
+
+func TestSearchIndexInfo(t *testing.T) {
+	redis := newSearchCatalog(t)
+
+	info, err := redis.FTInfo("products")
+	if err != nil || info.Name != "products" || info.NumDocs != 4 || !reflect.DeepEqual(info.Prefixes, []string{"product:"}) || len(info.Fields) != 5 {
+		t.Fatalf("FTInfo = %+v, %v", info, err)
+	}
+
+	if field := info.Fields[4]; field.Name != "embedding" || field.Dim != 2 || field.Distance != gredis.REDIS_SEARCH_DISTANCE_L2 {
+		t.Errorf("FTInfo vector field = %+v", field)
+	}
+
+	if err := redis.FTDropIndex("products", true); err != nil {
+		t.Fatalf("FTDropIndex = %v", err)
+	}
+
+	if isExists, _ := redis.Exists("product:1"); isExists {
+		t.Error("Exists after FTDropIndex DD = true")
+	}
+
+	if _, err := redis.FTInfo("products"); err == nil {
+		t.Error("FTInfo after FTDropIndex err = nil")
+	}
+}
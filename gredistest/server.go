@@ -0,0 +1,218 @@
+package gredistest
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+)
+
+/* ================================================================================
+ * Embedded RESP server
+ * 进程内的 RESP 服务器，监听本地端口或 unix socket，与内存 Redis 共用引擎，
+ * 用于覆盖拨号、连接池、AUTH、SELECT 及超时等真实网络路径
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 测试服务器选项
+	 * Network | Address: 监听地址，默认 tcp 127.0.0.1:0（随机端口），unix 时 Address 为 socket 路径
+	 * Clock | Password | Seed: 同 FakeOption
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	ServerOption struct {
+		Network  string
+		Address  string
+		Clock    *Clock
+		Password string
+		Seed     int64
+	}
+
+	Server struct {
+		engine      *engine
+		faults      *faults
+		listener    net.Listener
+		mutex       sync.Mutex
+		conns       map[net.Conn]struct{}
+		connections int64
+		wg          sync.WaitGroup
+	}
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 启动测试服务器
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func NewServer(args ...ServerOption) (*Server, error) {
+	option := ServerOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	if len(option.Network) == 0 {
+		option.Network = "tcp"
+	}
+
+	if len(option.Address) == 0 && option.Network == "tcp" {
+		option.Address = "127.0.0.1:0"
+	}
+
+	if option.Clock == nil {
+		option.Clock = NewClock(time.Now())
+	}
+
+	listener, err := net.Listen(option.Network, option.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{
+		engine:   newEngine(option.Clock, option.Password, option.Seed),
+		faults:   newFaults(),
+		listener: listener,
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	server.wg.Add(1)
+	go server.accept()
+
+	return server, nil
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		atomic.AddInt64(&s.connections, 1)
+
+		s.mutex.Lock()
+		s.conns[conn] = struct{}{}
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			s.engine.serve(conn, s.faults)
+
+			s.mutex.Lock()
+			delete(s.conns, conn)
+			s.mutex.Unlock()
+		}()
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 监听地址
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 获取连接到测试服务器的客户端，tcp 时设置 Ip | Port，unix 时设置 NetDial
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) NewRedis(args ...gredis.RedisOption) gredis.IRedis {
+	option := gredis.RedisOption{}
+	if len(args) > 0 {
+		option = args[0]
+	}
+
+	switch addr := s.listener.Addr().(type) {
+	case *net.TCPAddr:
+		option.Ip = addr.IP.String()
+		option.Port = addr.Port
+	default:
+		network, address := addr.Network(), addr.String()
+		option.NetDial = func(string, string) (net.Conn, error) {
+			return net.Dial(network, address)
+		}
+	}
+
+	return gredis.NewRedisWithOption(option)
+}
+
+func (s *Server) Clock() *Clock {
+	return s.engine.clock
+}
+
+func (s *Server) FlushAll() {
+	s.engine.flushAll()
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 累计接受的连接数
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) Connections() int {
+	return int(atomic.LoadInt64(&s.connections))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 设置回复延迟，command 为空时作用于所有命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) SetLatency(command string, latency time.Duration) {
+	s.faults.setLatency(command, latency)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 后续 count 次 command 请求回复错误 message（如 "LOADING Redis is loading"），
+ * command 为空时匹配任意命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) FailNext(command string, count int, message string) {
+	s.faults.add(&faultRule{
+		command: command,
+		count:   count,
+		reply:   errorReply(message),
+	})
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 后续 count 次 command 请求不执行并断开连接
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) DisconnectNext(command string, count int) {
+	s.faults.add(&faultRule{
+		command:      command,
+		count:        count,
+		isDisconnect: true,
+	})
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 清除全部故障注入
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) ResetFaults() {
+	s.faults.reset()
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 断开全部客户端连接
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) CloseClients() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 关闭服务器及全部连接
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.engine.close()
+	s.CloseClients()
+	s.wg.Wait()
+
+	return err
+}
@@ -0,0 +1,476 @@
+package gredistest
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ================================================================================
+ * Client session
+ * 连接级状态：认证、数据库、连接名称、MULTI/EXEC/WATCH 事务及订阅、客户端跟踪
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	session struct {
+		engine          *engine
+		output          *asyncWriter
+		id              int64
+		proto           int
+		name            string
+		db              int
+		isAuthenticated bool
+		isMulti         bool
+		isMultiError    bool
+		isClosed        bool
+		queue           [][][]byte
+		watches         map[watchKey]uint64
+		channels        map[string]struct{}
+		tracking        *tracking
+	}
+
+	watchKey struct {
+		db  int
+		key string
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 阻塞命令无数据时的返回值，timeout 为0时永久阻塞，超时后回复 reply
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	blocked struct {
+		timeout time.Duration
+		reply   interface{}
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 异步写入，避免客户端批量发送时双方互相阻塞
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	asyncWriter struct {
+		conn     net.Conn
+		mutex    sync.Mutex
+		cond     *sync.Cond
+		buffer   []byte
+		isClosed bool
+	}
+)
+
+func (e *engine) newSession(output *asyncWriter) *session {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.clientId++
+	s := &session{
+		engine:          e,
+		output:          output,
+		id:              e.clientId,
+		proto:           2,
+		isAuthenticated: len(e.password) == 0,
+	}
+	e.sessions[s.id] = s
+
+	return s
+}
+
+func (e *engine) closeSession(s *session) {
+	e.mutex.Lock()
+	delete(e.sessions, s.id)
+	e.mutex.Unlock()
+
+	s.output.close()
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 处理连接上的请求，直到连接关闭
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (e *engine) serve(conn net.Conn, faults *faults) {
+	s := e.newSession(newAsyncWriter(conn))
+	defer e.closeSession(s)
+
+	reader := bufio.NewReader(conn)
+
+	for !s.isClosed {
+		args, err := readCommand(reader)
+		if err != nil {
+			if err == errProtocol {
+				s.write(errorReply("ERR Protocol error"))
+			}
+			return
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		latency, rule := faults.match(args)
+		if latency > 0 {
+			time.Sleep(latency)
+		}
+
+		if rule != nil && rule.isDisconnect {
+			return
+		}
+
+		if rule != nil {
+			s.write(rule.reply)
+		} else {
+			s.write(s.execute(args))
+		}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 写入一条完整的回复，推送消息由其它连接的命令写入，因此每条回复一次性写入
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) write(reply interface{}) {
+	if _, isOk := reply.(noReply); isOk {
+		return
+	}
+
+	buffer := &bytes.Buffer{}
+	writeReply(buffer, reply, s.proto)
+
+	s.output.Write(buffer.Bytes())
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行一条命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) execute(args [][]byte) interface{} {
+	name := strings.ToUpper(string(args[0]))
+
+	switch name {
+	case "AUTH":
+		return s.auth(args[1:])
+	case "HELLO":
+		return s.hello(args[1:])
+	case "QUIT":
+		s.isClosed = true
+		return statusReply("OK")
+	}
+
+	if !s.isAuthenticated {
+		return errNoAuth
+	}
+
+	if len(s.channels) > 0 && s.proto == 2 {
+		return s.executeSubscribed(name, args)
+	}
+
+	switch name {
+	case "SUBSCRIBE":
+		return s.subscribe(args[1:])
+	case "UNSUBSCRIBE":
+		return s.unsubscribe(args[1:])
+	case "MULTI":
+		if s.isMulti {
+			return errorReply("ERR MULTI calls can not be nested")
+		}
+		s.isMulti = true
+		return statusReply("OK")
+	case "EXEC":
+		return s.exec()
+	case "DISCARD":
+		if !s.isMulti {
+			return errorReply("ERR DISCARD without MULTI")
+		}
+		s.reset()
+		return statusReply("OK")
+	case "WATCH":
+		return s.watch(args[1:])
+	case "UNWATCH":
+		s.watches = nil
+		return statusReply("OK")
+	case "CLIENT":
+		return s.client(args[1:])
+	}
+
+	cmd, err := lookupCommand(args)
+	if err != nil {
+		if s.isMulti {
+			s.isMultiError = true
+		}
+		return err
+	}
+
+	if s.isMulti {
+		s.queue = append(s.queue, args)
+		return statusReply("QUEUED")
+	}
+
+	return s.call(cmd, args)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行命令，阻塞命令无数据时等待数据变化后重试，直到超时
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) call(cmd command, args [][]byte) interface{} {
+	var timeout <-chan time.Time
+
+	for {
+		s.engine.mutex.Lock()
+		reply := s.engine.call(s, cmd, args)
+
+		block, isBlocked := reply.(blocked)
+		if !isBlocked {
+			s.engine.notify()
+			s.engine.invalidate()
+			s.engine.mutex.Unlock()
+			return reply
+		}
+
+		changed := s.engine.wait()
+		s.engine.mutex.Unlock()
+
+		if timeout == nil && block.timeout > 0 {
+			timer := time.NewTimer(block.timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case <-changed:
+		case <-timeout:
+			return block.reply
+		case <-s.engine.done:
+			return block.reply
+		}
+	}
+}
+
+func (s *session) auth(args [][]byte) interface{} {
+	if len(args) < 1 || len(args) > 2 {
+		return errorReply("ERR wrong number of arguments for 'auth' command")
+	}
+
+	if len(s.engine.password) == 0 {
+		return errorReply("ERR AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?")
+	}
+
+	if string(args[len(args)-1]) != s.engine.password {
+		return errWrongPass
+	}
+
+	s.isAuthenticated = true
+
+	return statusReply("OK")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * HELLO [protover [AUTH username password] [SETNAME clientname]]，支持 RESP2 | RESP3
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) hello(args [][]byte) interface{} {
+	proto := s.proto
+	if len(args) > 0 {
+		switch string(args[0]) {
+		case "2":
+			proto = 2
+		case "3":
+			proto = 3
+		default:
+			return errorReply("NOPROTO unsupported protocol version")
+		}
+	}
+
+	for index := 1; index < len(args); index++ {
+		switch {
+		case isOption(args[index], "AUTH") && index+2 < len(args):
+			if reply := s.auth(args[index+1 : index+3]); reply != statusReply("OK") {
+				return reply
+			}
+			index += 2
+		case isOption(args[index], "SETNAME") && index+1 < len(args):
+			s.name = string(args[index+1])
+			index++
+		default:
+			return errSyntax
+		}
+	}
+
+	if !s.isAuthenticated {
+		return errNoAuth
+	}
+
+	s.engine.mutex.Lock()
+	s.proto = proto
+	s.engine.mutex.Unlock()
+
+	return mapReply{
+		"server", "redis",
+		"version", serverVersion,
+		"proto", int64(proto),
+		"id", s.id,
+		"mode", "standalone",
+		"role", "master",
+		"modules", []interface{}{},
+	}
+}
+
+func (s *session) client(args [][]byte) interface{} {
+	if len(args) == 0 {
+		return errorReply("ERR wrong number of arguments for 'client' command")
+	}
+
+	switch strings.ToUpper(string(args[0])) {
+	case "ID":
+		return s.id
+	case "SETNAME":
+		if len(args) != 2 {
+			return errorReply("ERR wrong number of arguments for 'client|setname' command")
+		}
+		s.name = string(args[1])
+		return statusReply("OK")
+	case "GETNAME":
+		if len(s.name) == 0 {
+			return nil
+		}
+		return s.name
+	case "TRACKING":
+		return s.track(args[1:])
+	}
+
+	return errorReply("ERR unknown subcommand '" + string(args[0]) + "'.")
+}
+
+func (s *session) watch(args [][]byte) interface{} {
+	if len(args) == 0 {
+		return errorReply("ERR wrong number of arguments for 'watch' command")
+	}
+
+	if s.isMulti {
+		return errorReply("ERR WATCH inside MULTI is not allowed")
+	}
+
+	s.engine.mutex.Lock()
+	defer s.engine.mutex.Unlock()
+
+	if s.watches == nil {
+		s.watches = make(map[watchKey]uint64)
+	}
+
+	db := s.engine.dbs[s.db]
+	now := s.engine.clock.Now()
+
+	for _, arg := range args {
+		key := string(arg)
+		db.lookup(key, now)
+		s.watches[watchKey{db: s.db, key: key}] = db.versions[key]
+	}
+
+	return statusReply("OK")
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 执行事务：WATCH 的键被修改时返回空数组，入队出错时返回 EXECABORT
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (s *session) exec() interface{} {
+	if !s.isMulti {
+		return errorReply("ERR EXEC without MULTI")
+	}
+
+	defer s.reset()
+
+	if s.isMultiError {
+		return errorReply("EXECABORT Transaction discarded because of previous errors.")
+	}
+
+	s.engine.mutex.Lock()
+	defer s.engine.mutex.Unlock()
+
+	now := s.engine.clock.Now()
+	for watch, version := range s.watches {
+		db := s.engine.dbs[watch.db]
+		db.lookup(watch.key, now)
+
+		if db.versions[watch.key] != version {
+			return nullArray{}
+		}
+	}
+
+	replies := make([]interface{}, 0, len(s.queue))
+	for _, args := range s.queue {
+		cmd, _ := lookupCommand(args)
+
+		// 事务中的阻塞命令不阻塞
+		reply := s.engine.call(s, cmd, args)
+		if block, isBlocked := reply.(blocked); isBlocked {
+			reply = block.reply
+		}
+
+		replies = append(replies, reply)
+	}
+
+	s.engine.notify()
+	s.engine.invalidate()
+
+	return replies
+}
+
+func (s *session) reset() {
+	s.isMulti = false
+	s.isMultiError = false
+	s.queue = nil
+	s.watches = nil
+}
+
+func newAsyncWriter(conn net.Conn) *asyncWriter {
+	w := &asyncWriter{
+		conn: conn,
+	}
+	w.cond = sync.NewCond(&w.mutex)
+
+	go w.run()
+
+	return w
+}
+
+func (w *asyncWriter) Write(data []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.isClosed {
+		return 0, net.ErrClosed
+	}
+
+	w.buffer = append(w.buffer, data...)
+	w.cond.Signal()
+
+	return len(data), nil
+}
+
+func (w *asyncWriter) run() {
+	for {
+		w.mutex.Lock()
+		for len(w.buffer) == 0 && !w.isClosed {
+			w.cond.Wait()
+		}
+
+		data := w.buffer
+		isClosed := w.isClosed
+		w.buffer = nil
+		w.mutex.Unlock()
+
+		if len(data) > 0 {
+			if _, err := w.conn.Write(data); err != nil {
+				return
+			}
+		}
+
+		if isClosed {
+			w.conn.Close()
+			return
+		}
+	}
+}
+
+func (w *asyncWriter) close() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.isClosed = true
+	w.cond.Signal()
+}
@@ -0,0 +1,321 @@
+package gredistest
+
+import (
+	"strconv"
+)
+
+/* ================================================================================
+ * Set commands
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+const (
+	setUnion int = iota
+	setInter
+	setDiff
+)
+
+func init() {
+	register("SADD", -3, sAdd)
+	register("SREM", -3, sRem)
+	register("SMOVE", 4, sMove)
+	register("SPOP", -2, sPop)
+	register("SCARD", 2, sCard)
+	register("SISMEMBER", 3, sIsMember)
+	register("SMEMBERS", 2, sMembers)
+	register("SRANDMEMBER", -2, sRandMember)
+	register("SUNION", -2, setOperation(setUnion, false))
+	register("SINTER", -2, setOperation(setInter, false))
+	register("SDIFF", -2, setOperation(setDiff, false))
+	register("SUNIONSTORE", -3, setOperation(setUnion, true))
+	register("SINTERSTORE", -3, setOperation(setInter, true))
+	register("SDIFFSTORE", -3, setOperation(setDiff, true))
+	register("SINTERCARD", -3, sInterCard)
+	register("SMISMEMBER", -3, sMIsMember)
+	register("SSCAN", -3, sScan)
+}
+
+func sAdd(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	set := c.setValue(key, true)
+
+	added := int64(0)
+	for _, member := range args[1:] {
+		if _, isExists := set[string(member)]; !isExists {
+			set[string(member)] = struct{}{}
+			added++
+		}
+	}
+
+	c.modified(key, len(set))
+
+	return added
+}
+
+func sRem(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	set := c.setValue(key, false)
+	if set == nil {
+		return int64(0)
+	}
+
+	removed := int64(0)
+	for _, member := range args[1:] {
+		if _, isExists := set[string(member)]; isExists {
+			delete(set, string(member))
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		c.modified(key, len(set))
+	}
+
+	return removed
+}
+
+func sMove(c *commandContext, args [][]byte) interface{} {
+	source, destination, member := string(args[0]), string(args[1]), string(args[2])
+
+	sourceSet := c.setValue(source, false)
+	c.setValue(destination, false)
+
+	if sourceSet == nil {
+		return int64(0)
+	}
+
+	if _, isExists := sourceSet[member]; !isExists {
+		return int64(0)
+	}
+
+	delete(sourceSet, member)
+	c.modified(source, len(sourceSet))
+
+	destinationSet := c.setValue(destination, true)
+	destinationSet[member] = struct{}{}
+	c.modified(destination, len(destinationSet))
+
+	return int64(1)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * SPOP key [count]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func sPop(c *commandContext, args [][]byte) interface{} {
+	if len(args) > 2 {
+		panic(errSyntax)
+	}
+
+	key := string(args[0])
+
+	count, isCount := int64(1), len(args) == 2
+	if isCount {
+		count = parseInt(args[1])
+		if count < 0 {
+			panic(errorReply("ERR value is out of range, must be positive"))
+		}
+	}
+
+	set := c.setValue(key, false)
+	if set == nil {
+		if isCount {
+			return []interface{}{}
+		}
+		return nil
+	}
+
+	members := c.randomMembers(set, count, false)
+	for _, member := range members {
+		delete(set, member)
+	}
+
+	c.modified(key, len(set))
+
+	if !isCount {
+		return members[0]
+	}
+
+	return bulkStrings(members)
+}
+
+func sCard(c *commandContext, args [][]byte) interface{} {
+	return int64(len(c.setValue(string(args[0]), false)))
+}
+
+func sIsMember(c *commandContext, args [][]byte) interface{} {
+	if _, isExists := c.setValue(string(args[0]), false)[string(args[1])]; isExists {
+		return int64(1)
+	}
+
+	return int64(0)
+}
+
+func sMembers(c *commandContext, args [][]byte) interface{} {
+	return setReply(bulkStrings(c.setValue(string(args[0]), false).members()))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * SRANDMEMBER key [count]：count 为负数时允许重复
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func sRandMember(c *commandContext, args [][]byte) interface{} {
+	if len(args) > 2 {
+		panic(errSyntax)
+	}
+
+	count, isCount := int64(1), len(args) == 2
+	if isCount {
+		count = parseInt(args[1])
+	}
+
+	set := c.setValue(string(args[0]), false)
+	if len(set) == 0 {
+		if isCount {
+			return []interface{}{}
+		}
+		return nil
+	}
+
+	isRepeat := count < 0
+	if isRepeat {
+		count = -count
+	}
+
+	members := c.randomMembers(set, count, isRepeat)
+	if !isCount {
+		return members[0]
+	}
+
+	return bulkStrings(members)
+}
+
+func (c *commandContext) randomMembers(set setValue, count int64, isRepeat bool) []string {
+	candidates := set.members()
+
+	if isRepeat {
+		members := make([]string, 0, count)
+		for i := int64(0); i < count; i++ {
+			members = append(members, candidates[c.engine.rand.Intn(len(candidates))])
+		}
+		return members
+	}
+
+	c.engine.rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	if count < int64(len(candidates)) {
+		candidates = candidates[:count]
+	}
+
+	return candidates
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * SUNION | SINTER | SDIFF 及对应的 STORE 命令
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func setOperation(operation int, isStore bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		keys := args
+		if isStore {
+			keys = args[1:]
+		}
+
+		sets := make([]setValue, 0, len(keys))
+		for _, key := range keys {
+			sets = append(sets, c.setValue(string(key), false))
+		}
+
+		result := make(setValue)
+		for member := range sets[0] {
+			result[member] = struct{}{}
+		}
+
+		for _, set := range sets[1:] {
+			switch operation {
+			case setUnion:
+				for member := range set {
+					result[member] = struct{}{}
+				}
+			case setInter:
+				for member := range result {
+					if _, isExists := set[member]; !isExists {
+						delete(result, member)
+					}
+				}
+			case setDiff:
+				for member := range set {
+					delete(result, member)
+				}
+			}
+		}
+
+		if !isStore {
+			return setReply(bulkStrings(result.members()))
+		}
+
+		destination := string(args[0])
+		c.remove(destination)
+
+		if len(result) > 0 {
+			c.set(destination, result)
+		}
+
+		return int64(len(result))
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * SINTERCARD numkeys key [key ...] [LIMIT limit]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func sInterCard(c *commandContext, args [][]byte) interface{} {
+	count := parseInt(args[0])
+	if count <= 0 {
+		panic(errorReply("ERR numkeys should be greater than 0"))
+	}
+
+	if count > int64(len(args)-1) {
+		panic(errorReply("ERR Number of keys can't be greater than number of args"))
+	}
+
+	keys, options := args[1:count+1], args[count+1:]
+
+	limit := int64(0)
+	switch {
+	case len(options) == 2 && isOption(options[0], "LIMIT"):
+		if limit = parseInt(options[1]); limit < 0 {
+			panic(errorReply("ERR LIMIT can't be negative"))
+		}
+	case len(options) != 0:
+		panic(errSyntax)
+	}
+
+	result := setOperation(setInter, false)(c, keys).(setReply)
+	if limit > 0 && int64(len(result)) > limit {
+		return limit
+	}
+
+	return int64(len(result))
+}
+
+func sMIsMember(c *commandContext, args [][]byte) interface{} {
+	set := c.setValue(string(args[0]), false)
+
+	replies := make([]interface{}, 0, len(args)-1)
+	for _, member := range args[1:] {
+		_, isExists := set[string(member)]
+		replies = append(replies, isExists)
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * SSCAN key cursor [MATCH pattern] [COUNT count]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func sScan(c *commandContext, args [][]byte) interface{} {
+	cursor, members := scanNames(args[1:], c.setValue(string(args[0]), false).members())
+
+	return []interface{}{strconv.FormatInt(cursor, 10), bulkStrings(members)}
+}
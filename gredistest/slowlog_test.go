@@ -0,0 +1,142 @@
+package gredistest_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+)
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 记录日志属性的 slog.Handler
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+type captureHandler struct {
+	mutex   sync.Mutex
+	records []map[string]string
+}
+
+func (h *captureHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (h *captureHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := map[string]string{"level": record.Level.String(), "message": record.Message}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+
+	h.mutex.Lock()
+	h.records = append(h.records, attrs)
+	h.mutex.Unlock()
+
+	return nil
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler { return h }
+func (h *captureHandler) WithGroup(name string) slog.Handler       { return h }
+
+func (h *captureHandler) take() []map[string]string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	records := h.records
+	h.records = nil
+
+	return records
+}
+
+func newSlowLogRedis(t *testing.T, option gredis.SlowLogOption) (gredis.IRedis, *captureHandler) {
+	server := newTestServer(t)
+	server.SetLatency("GET", 30*time.Millisecond)
+
+	handler := &captureHandler{}
+	option.Logger = slog.New(handler)
+
+	return newServerRedis(t, server, gredis.RedisOption{SlowLog: &option}), handler
+}
+
+func TestSlowLogThreshold(t *testing.T) {
+	redis, handler := newSlowLogRedis(t, gredis.SlowLogOption{Threshold: 20 * time.Millisecond})
+
+	redis.Set("name", "gredis")
+	if records := handler.take(); len(records) != 0 {
+		t.Errorf("fast command logged: %v", records)
+	}
+
+	_, file, line, _ := runtime.Caller(0)
+	redis.Get("name")
+
+	records := handler.take()
+	if len(records) != 1 {
+		t.Fatalf("slow command records = %v", records)
+	}
+
+	record := records[0]
+	if record["message"] != "gredis: slow command" || record["level"] != "WARN" || record["command"] != "GET" || record["key"] != "test:name" {
+		t.Errorf("record = %v", record)
+	}
+
+	// 调用位置为 gredis 包外的调用方，即本测试文件
+	if caller := record["caller"]; caller != fmt.Sprintf("%s:%d", file, line+1) {
+		t.Errorf("caller = %q, want line %d of this file", caller, line+1)
+	}
+
+	if duration, err := time.ParseDuration(record["duration"]); err != nil || duration < 20*time.Millisecond {
+		t.Errorf("duration = %q, %v", record["duration"], err)
+	}
+}
+
+func TestSlowLogSkipsBlocking(t *testing.T) {
+	redis, handler := newSlowLogRedis(t, gredis.SlowLogOption{Threshold: 10 * time.Millisecond})
+
+	// 阻塞命令的耗时主要是等待，不计为慢命令
+	redis.BLPop(50*time.Millisecond, "jobs")
+	if records := handler.take(); len(records) != 0 {
+		t.Errorf("blocking command logged: %v", records)
+	}
+
+	redis.Get("jobs")
+	if records := handler.take(); len(records) != 1 {
+		t.Errorf("slow command records = %v", records)
+	}
+}
+
+func TestSlowLogPreview(t *testing.T) {
+	redis, handler := newSlowLogRedis(t, gredis.SlowLogOption{PreviewArgs: 2})
+
+	redis.RPush("jobs", "secret-1", "secret-2", "secret-3")
+
+	// 默认隐藏参数值，超过 PreviewArgs 的参数只显示个数
+	record := handler.take()[0]
+	if record["command"] != "RPUSH" || record["preview"] != "? ? ...(+1)" || record["args"] != "4" || record["bytes"] != "33" {
+		t.Errorf("redacted record = %v", record)
+	}
+
+	// BITOP 的首个参数为运算符而非Key
+	redis.BitOp(gredis.REDIS_BITOP_AND, "dest", "a", "b")
+	if record := handler.take()[0]; record["key"] != "" || record["preview"] != "? ? ...(+2)" {
+		t.Errorf("BITOP record = %v", record)
+	}
+
+	redis, handler = newSlowLogRedis(t, gredis.SlowLogOption{ShowValues: true, PreviewArgLength: 4})
+
+	redis.Set("name", "gredis-value", 60)
+	record = handler.take()[0]
+	if record["preview"] != "60 gred..." {
+		t.Errorf("preview = %q, want values truncated to 4 bytes", record["preview"])
+	}
+
+	// 截断不拆分多字节字符
+	redis.Set("name", "日本語")
+	if record = handler.take()[0]; record["preview"] != "日..." {
+		t.Errorf("preview = %q, want truncation on a character boundary", record["preview"])
+	}
+}
@@ -0,0 +1,428 @@
+package gredistest
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+/* ================================================================================
+ * String commands
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+func init() {
+	register("GET", 2, get)
+	register("SET", -3, set)
+	register("SETEX", 4, setEx(time.Second, "setex"))
+	register("PSETEX", 4, setEx(time.Millisecond, "psetex"))
+	register("SETNX", 3, setNx)
+	register("SETRANGE", 4, setRange)
+	register("APPEND", 3, appendValue)
+	register("GETSET", 3, getSet)
+	register("GETRANGE", 4, getRange)
+	register("STRLEN", 2, strLen)
+	register("INCR", 2, incr(1, false))
+	register("DECR", 2, incr(-1, false))
+	register("INCRBY", 3, incr(1, true))
+	register("DECRBY", 3, incr(-1, true))
+	register("INCRBYFLOAT", 3, incrByFloat)
+	register("MGET", -2, mGet)
+	register("MSET", -3, mSet("mset", false))
+	register("MSETNX", -3, mSet("msetnx", true))
+	register("GETEX", -2, getEx)
+	register("GETDEL", 2, getDel)
+	register("LCS", -3, lcs)
+}
+
+func get(c *commandContext, args [][]byte) interface{} {
+	value, isExists := c.stringValue(string(args[0]))
+	if !isExists {
+		return nil
+	}
+
+	return value
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * SET key value [NX | XX] [GET] [EX seconds | PX milliseconds | EXAT | PXAT | KEEPTTL]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func set(c *commandContext, args [][]byte) interface{} {
+	key, value := string(args[0]), args[1]
+
+	isNx, isXx, isGet, isKeepTtl := false, false, false, false
+	var expireAt time.Time
+
+	for index := 2; index < len(args); index++ {
+		arg := args[index]
+
+		switch {
+		case isOption(arg, "NX") && !isXx:
+			isNx = true
+		case isOption(arg, "XX") && !isNx:
+			isXx = true
+		case isOption(arg, "GET"):
+			isGet = true
+		case isOption(arg, "KEEPTTL") && expireAt.IsZero():
+			isKeepTtl = true
+		case (isOption(arg, "EX") || isOption(arg, "PX") || isOption(arg, "EXAT") || isOption(arg, "PXAT")) &&
+			!isKeepTtl && expireAt.IsZero() && index+1 < len(args):
+			index++
+			amount := parseInt(args[index])
+			if amount <= 0 {
+				panic(errorReply("ERR invalid expire time in 'set' command"))
+			}
+
+			switch {
+			case isOption(arg, "EX"):
+				expireAt = c.now.Add(time.Duration(amount) * time.Second)
+			case isOption(arg, "PX"):
+				expireAt = c.now.Add(time.Duration(amount) * time.Millisecond)
+			case isOption(arg, "EXAT"):
+				expireAt = time.Unix(amount, 0)
+			default:
+				expireAt = time.Unix(0, 0).Add(time.Duration(amount) * time.Millisecond)
+			}
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	var previous interface{}
+	if isGet {
+		if oldValue, isExists := c.stringValue(key); isExists {
+			previous = oldValue
+		}
+	}
+
+	item := c.lookup(key)
+	if (isNx && item != nil) || (isXx && item == nil) {
+		if isGet {
+			return previous
+		}
+		return nil
+	}
+
+	if isKeepTtl && item != nil {
+		c.put(key, value)
+	} else {
+		c.set(key, value)
+		c.db.keys[key].expireAt = expireAt
+	}
+
+	if isGet {
+		return previous
+	}
+
+	return statusReply("OK")
+}
+
+func setEx(unit time.Duration, name string) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		amount := parseInt(args[1])
+		if amount <= 0 {
+			panic(errorReply("ERR invalid expire time in '" + name + "' command"))
+		}
+
+		key := string(args[0])
+		c.set(key, args[2])
+		c.db.keys[key].expireAt = c.now.Add(time.Duration(amount) * unit)
+
+		return statusReply("OK")
+	}
+}
+
+func setNx(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	if c.lookup(key) != nil {
+		return int64(0)
+	}
+
+	c.set(key, args[1])
+
+	return int64(1)
+}
+
+func setRange(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	offset := parseInt(args[1])
+	if offset < 0 {
+		panic(errorReply("ERR offset is out of range"))
+	}
+
+	value, isExists := c.stringValue(key)
+	if len(args[2]) == 0 {
+		return int64(len(value))
+	}
+
+	end := int(offset) + len(args[2])
+	data := make([]byte, max(end, len(value)))
+	copy(data, value)
+	copy(data[offset:], args[2])
+
+	if isExists {
+		c.put(key, data)
+	} else {
+		c.set(key, data)
+	}
+
+	return int64(len(data))
+}
+
+func appendValue(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	value, isExists := c.stringValue(key)
+
+	data := make([]byte, 0, len(value)+len(args[1]))
+	data = append(append(data, value...), args[1]...)
+
+	if isExists {
+		c.put(key, data)
+	} else {
+		c.set(key, data)
+	}
+
+	return int64(len(data))
+}
+
+func getSet(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	value, isExists := c.stringValue(key)
+
+	c.set(key, args[1])
+
+	if !isExists {
+		return nil
+	}
+
+	return value
+}
+
+func getRange(c *commandContext, args [][]byte) interface{} {
+	value, _ := c.stringValue(string(args[0]))
+
+	start, stop, isOk := normalizeRange(parseInt(args[1]), parseInt(args[2]), len(value))
+	if !isOk {
+		return []byte{}
+	}
+
+	return value[start : stop+1]
+}
+
+func strLen(c *commandContext, args [][]byte) interface{} {
+	value, _ := c.stringValue(string(args[0]))
+
+	return int64(len(value))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * INCR | DECR | INCRBY | DECRBY
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func incr(sign int64, isBy bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		step := sign
+		if isBy {
+			step = parseInt(args[1])
+			if sign < 0 {
+				if step == math.MinInt64 {
+					panic(errIncrOverflow)
+				}
+				step = -step
+			}
+		}
+
+		return incrBy(c, string(args[0]), step)
+	}
+}
+
+func incrBy(c *commandContext, key string, step int64) int64 {
+	value, isExists := c.stringValue(key)
+
+	current := int64(0)
+	if isExists {
+		current = parseInt(value)
+	}
+
+	if (step > 0 && current > math.MaxInt64-step) || (step < 0 && current < math.MinInt64-step) {
+		panic(errIncrOverflow)
+	}
+
+	current += step
+	c.put(key, []byte(strconv.FormatInt(current, 10)))
+
+	return current
+}
+
+func incrByFloat(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	value, isExists := c.stringValue(key)
+
+	current := float64(0)
+	if isExists {
+		current = parseFloat(value)
+	}
+
+	current += parseFloat(args[1])
+	if math.IsInf(current, 0) || math.IsNaN(current) {
+		panic(errorReply("ERR increment would produce NaN or Infinity"))
+	}
+
+	result := []byte(formatFloat(current))
+	c.put(key, result)
+
+	return result
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * MGET key [key ...]：不存在或非字符串类型的键返回 nil
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func mGet(c *commandContext, args [][]byte) interface{} {
+	replies := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		var reply interface{}
+		if item := c.lookup(string(arg)); item != nil {
+			if value, isOk := item.value.([]byte); isOk {
+				reply = value
+			}
+		}
+
+		replies = append(replies, reply)
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * MSET | MSETNX key value [key value ...]
+ * MSETNX 仅当全部键都不存在时才设置
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func mSet(name string, isNx bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		if len(args)%2 != 0 {
+			panic(errorReply("ERR wrong number of arguments for '" + name + "' command"))
+		}
+
+		if isNx {
+			for index := 0; index < len(args); index += 2 {
+				if c.lookup(string(args[index])) != nil {
+					return int64(0)
+				}
+			}
+		}
+
+		for index := 0; index < len(args); index += 2 {
+			c.set(string(args[index]), args[index+1])
+		}
+
+		if isNx {
+			return int64(1)
+		}
+
+		return statusReply("OK")
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * GETEX key [EX seconds | PX milliseconds | EXAT timestamp | PXAT timestamp | PERSIST]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func getEx(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	rest := args[1:]
+
+	expireAt, consumed := parseExpireOption(c, rest)
+	isPersist := consumed == 0 && len(rest) == 1 && isOption(rest[0], "PERSIST")
+	if isPersist {
+		consumed = 1
+	}
+
+	if consumed != len(rest) {
+		panic(errSyntax)
+	}
+
+	value, isExists := c.stringValue(key)
+	if !isExists {
+		return nil
+	}
+
+	switch {
+	case isPersist:
+		c.lookup(key).expireAt = time.Time{}
+	case consumed > 0 && !expireAt.After(c.now):
+		c.remove(key)
+		return value
+	case consumed > 0:
+		c.lookup(key).expireAt = expireAt
+	}
+
+	if consumed > 0 {
+		c.db.touch(key)
+	}
+
+	return value
+}
+
+func getDel(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	value, isExists := c.stringValue(key)
+	if !isExists {
+		return nil
+	}
+
+	c.remove(key)
+
+	return value
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * LCS key1 key2 [LEN]：返回最长公共子序列或其长度，不支持 IDX
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func lcs(c *commandContext, args [][]byte) interface{} {
+	isLen := false
+	for _, arg := range args[2:] {
+		if !isOption(arg, "LEN") {
+			panic(errSyntax)
+		}
+		isLen = true
+	}
+
+	first, _ := c.stringValue(string(args[0]))
+	second, _ := c.stringValue(string(args[1]))
+
+	lengths := make([][]int, len(first)+1)
+	for index := range lengths {
+		lengths[index] = make([]int, len(second)+1)
+	}
+
+	for i := len(first) - 1; i >= 0; i-- {
+		for j := len(second) - 1; j >= 0; j-- {
+			if first[i] == second[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	if isLen {
+		return int64(lengths[0][0])
+	}
+
+	result := make([]byte, 0, lengths[0][0])
+	for i, j := 0, 0; i < len(first) && j < len(second); {
+		switch {
+		case first[i] == second[j]:
+			result = append(result, first[i])
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,50 @@
+package gredistest_test
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+func TestUniqueCounter(t *testing.T) {
+	fake := gredistest.NewFake()
+	redis := newFakeRedis(t, fake, gredis.RedisOption{})
+
+	counter := gredis.NewUniqueCounter(redis, "uv", gredis.UniqueCounterOption{Retention: 30, WindowTtl: 10 * time.Second})
+
+	today := time.Now()
+	yesterday := today.AddDate(0, 0, -1)
+
+	if isChanged, err := counter.Add(yesterday, "alice", "bob"); err != nil || !isChanged {
+		t.Errorf("Add = %v, %v, want true", isChanged, err)
+	}
+
+	counter.Add(today, "bob", "carol")
+
+	if isChanged, _ := counter.Add(today, "carol"); isChanged {
+		t.Error("Add of a seen element = true")
+	}
+
+	if count, err := counter.Count(today); err != nil || count != 2 {
+		t.Errorf("Count = %d, %v, want 2", count, err)
+	}
+
+	if count, err := counter.CountWindow(7, today); err != nil || count != 3 {
+		t.Errorf("CountWindow = %d, %v, want 3", count, err)
+	}
+
+	windowKey := counter.WindowKey(7, today)
+	if count, err := redis.PfCount(windowKey); err != nil || count != 3 {
+		t.Errorf("PfCount(WindowKey) = %d, %v, want 3", count, err)
+	}
+
+	fake.Clock().Advance(11 * time.Second)
+
+	if isExists, _ := redis.Exists(windowKey); isExists {
+		t.Error("window key still exists after WindowTtl")
+	}
+}
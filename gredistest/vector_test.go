@@ -0,0 +1,96 @@
+package gredistest_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+import (
+	"github.com/sanxia/gredis"
+	"github.com/sanxia/gredis/gredistest"
+)
+
+func TestVectorEncoding(t *testing.T) {
+	vector := []float32{1.5, -2, 0, 3.25}
+
+	data := gredis.EncodeVector(vector)
+	if len(data) != 16 {
+		t.Fatalf("EncodeVector length = %d, want 16", len(data))
+	}
+
+	if decoded, err := gredis.DecodeVector(data); err != nil || !reflect.DeepEqual(decoded, vector) {
+		t.Errorf("DecodeVector = %v, %v", decoded, err)
+	}
+
+	if _, err := gredis.DecodeVector(data[:3]); !errors.Is(err, gredis.ErrUnexpectedReply) {
+		t.Errorf("DecodeVector of truncated blob err = %v, want ErrUnexpectedReply", err)
+	}
+}
+
+func TestVectorStore(t *testing.T) {
+	redis := newFakeRedis(t, gredistest.NewFake(), gredis.RedisOption{})
+
+	store := gredis.NewVectorStore(redis, "docs", 3, gredis.VectorStoreOption{
+		Fields: []gredis.SearchField{{Name: "category", Type: gredis.REDIS_SEARCH_FIELD_TAG}},
+	})
+
+	if err := store.CreateIndex(); err != nil {
+		t.Fatalf("CreateIndex = %v", err)
+	}
+
+	store.Add("a", []float32{1, 0, 0}, "title", "alpha", "category", "news")
+	store.Add("b", []float32{0.8, 0.6, 0}, "title", "beta", "category", "blog")
+	store.Add("c", []float32{0, 0, 1}, "title", "gamma", "category", "news")
+
+	if err := store.Add("d", []float32{1, 0}); err == nil {
+		t.Error("Add of wrong dimension err = nil")
+	}
+
+	if vector, err := store.Get("b"); err != nil || !reflect.DeepEqual(vector, []float32{0.8, 0.6, 0}) {
+		t.Errorf("Get = %v, %v", vector, err)
+	}
+
+	if _, err := store.Get("missing"); !errors.Is(err, gredis.ErrNotFound) {
+		t.Errorf("Get of missing id err = %v, want ErrNotFound", err)
+	}
+
+	matches, err := store.Search([]float32{1, 0, 0}, 2)
+	if err != nil || len(matches) != 2 {
+		t.Fatalf("Search = %+v, %v", matches, err)
+	}
+
+	if matches[0].Id != "a" || matches[0].Distance != 0 || matches[1].Id != "b" || math.Abs(matches[1].Distance-0.2) > 1e-6 {
+		t.Errorf("Search = %+v", matches)
+	}
+
+	if !reflect.DeepEqual(matches[0].Vector, []float32{1, 0, 0}) || !reflect.DeepEqual(matches[0].Fields, map[string]string{"title": "alpha", "category": "news"}) {
+		t.Errorf("Search[0] = %+v", matches[0])
+	}
+
+	var document struct {
+		Title string `redis:"title"`
+	}
+	if err := matches[1].Scan(&document); err != nil || document.Title != "beta" {
+		t.Errorf("Scan = %+v, %v", document, err)
+	}
+
+	filter := gredis.NewSearchQuery().Tag("category", "news")
+	filtered, err := store.Search([]float32{0.8, 0.6, 0}, 1, filter)
+	if err != nil || len(filtered) != 1 || filtered[0].Id != "a" {
+		t.Errorf("Search with filter = %+v, %v", filtered, err)
+	}
+
+	if query := filter.String(); query != "@category:{news}" {
+		t.Errorf("filter after Search = %q, want unchanged", query)
+	}
+
+	if err := store.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if matches, _ := store.Search([]float32{1, 0, 0}, 1); len(matches) != 1 || matches[0].Id != "b" {
+		t.Errorf("Search after Remove = %+v", matches)
+	}
+}
@@ -0,0 +1,856 @@
+package gredistest
+
+import (
+	"math"
+	"sort"
+)
+
+/* ================================================================================
+ * Sorted set commands
+ * qq group: 582452342
+ * email   : 2091938785@qq.com
+ * author  : 美丽的地球啊
+ * ================================================================================ */
+type (
+	zmember struct {
+		member string
+		score  float64
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 分数区间边界，支持 -inf | +inf | (score 开区间
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	scoreBound struct {
+		value       float64
+		isExclusive bool
+	}
+
+	/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+	 * 字典序区间边界，支持 - | + | [member 闭区间 | (member 开区间
+	 * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+	lexBound struct {
+		value       string
+		isExclusive bool
+		isMin       bool
+		isMax       bool
+	}
+)
+
+func init() {
+	register("ZADD", -4, zAdd)
+	register("ZINCRBY", 4, zIncrBy)
+	register("ZRANGE", -4, zRange)
+	register("ZREVRANGE", -4, zRangeByRank(true))
+	register("ZRANGEBYSCORE", -4, zRangeByScore(false))
+	register("ZREVRANGEBYSCORE", -4, zRangeByScore(true))
+	register("ZREM", -3, zRem)
+	register("ZREMRANGEBYSCORE", 4, zRemRangeByScore)
+	register("ZREMRANGEBYRANK", 4, zRemRangeByRank)
+	register("ZCARD", 2, zCard)
+	register("ZSCORE", 3, zScore)
+	register("ZRANK", 3, zRank(false))
+	register("ZREVRANK", 3, zRank(true))
+	register("ZCOUNT", 4, zCount)
+	register("ZRANGEBYLEX", -4, zRangeByLex(false))
+	register("ZREVRANGEBYLEX", -4, zRangeByLex(true))
+	register("ZPOPMIN", -2, zPop(false))
+	register("ZPOPMAX", -2, zPop(true))
+	register("BZPOPMIN", -3, blocking(false, bzPop(false)))
+	register("BZPOPMAX", -3, blocking(false, bzPop(true)))
+	register("ZUNIONSTORE", -4, zStore(setUnion))
+	register("ZINTERSTORE", -4, zStore(setInter))
+	register("ZDIFFSTORE", -4, zStore(setDiff))
+	register("ZDIFF", -3, zDiff)
+	register("ZMSCORE", -3, zMScore)
+	register("ZRANDMEMBER", -2, zRandMember)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 按分数、成员排序
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (z zsetValue) sorted(isReverse bool) []zmember {
+	members := make([]zmember, 0, len(z))
+	for member, score := range z {
+		members = append(members, zmember{member: member, score: score})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		isLess := members[i].score < members[j].score ||
+			(members[i].score == members[j].score && members[i].member < members[j].member)
+		if isReverse {
+			return !isLess
+		}
+		return isLess
+	})
+
+	return members
+}
+
+func parseScoreBound(arg []byte) scoreBound {
+	value := string(arg)
+
+	bound := scoreBound{}
+	if len(value) > 0 && value[0] == '(' {
+		bound.isExclusive = true
+		value = value[1:]
+	}
+
+	score, err := parseFloatString(value)
+	if err != nil {
+		panic(errNotMinMax)
+	}
+
+	bound.value = score
+
+	return bound
+}
+
+func parseLexBound(arg []byte) lexBound {
+	value := string(arg)
+
+	switch {
+	case value == "-":
+		return lexBound{isMin: true}
+	case value == "+":
+		return lexBound{isMax: true}
+	case len(value) > 0 && value[0] == '[':
+		return lexBound{value: value[1:]}
+	case len(value) > 0 && value[0] == '(':
+		return lexBound{value: value[1:], isExclusive: true}
+	}
+
+	panic(errNotLexRange)
+}
+
+func (b lexBound) isAbove(member string) bool {
+	switch {
+	case b.isMin:
+		return true
+	case b.isMax:
+		return false
+	case b.isExclusive:
+		return member > b.value
+	}
+
+	return member >= b.value
+}
+
+func (b lexBound) isBelow(member string) bool {
+	switch {
+	case b.isMax:
+		return true
+	case b.isMin:
+		return false
+	case b.isExclusive:
+		return member < b.value
+	}
+
+	return member <= b.value
+}
+
+func (b scoreBound) isAbove(score float64) bool {
+	if b.isExclusive {
+		return score > b.value
+	}
+
+	return score >= b.value
+}
+
+func (b scoreBound) isBelow(score float64) bool {
+	if b.isExclusive {
+		return score < b.value
+	}
+
+	return score <= b.value
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZADD key [NX | XX] [GT | LT] [CH] [INCR] score member [score member ...]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zAdd(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	isNx, isXx, isGt, isLt, isCh, isIncr := false, false, false, false, false, false
+
+	index := 1
+	for isFlag := true; isFlag && index < len(args); index++ {
+		switch {
+		case isOption(args[index], "NX"):
+			isNx = true
+		case isOption(args[index], "XX"):
+			isXx = true
+		case isOption(args[index], "GT"):
+			isGt = true
+		case isOption(args[index], "LT"):
+			isLt = true
+		case isOption(args[index], "CH"):
+			isCh = true
+		case isOption(args[index], "INCR"):
+			isIncr = true
+		default:
+			isFlag = false
+			index--
+		}
+	}
+
+	pairs := args[index:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		panic(errSyntax)
+	}
+
+	if isNx && isXx {
+		panic(errorReply("ERR XX and NX options at the same time are not compatible"))
+	}
+
+	if (isGt && isLt) || (isNx && (isGt || isLt)) {
+		panic(errorReply("ERR GT, LT, and/or NX options at the same time are not compatible"))
+	}
+
+	if isIncr && len(pairs) != 2 {
+		panic(errorReply("ERR INCR option supports a single increment-element pair"))
+	}
+
+	scores := make([]float64, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		scores = append(scores, parseFloat(pairs[i]))
+	}
+
+	zset := c.zsetValue(key, true)
+
+	added, changed := int64(0), int64(0)
+	var result interface{}
+
+	for i := 0; i < len(pairs); i += 2 {
+		member, score := string(pairs[i+1]), scores[i/2]
+		current, isExists := zset[member]
+
+		if (isNx && isExists) || (isXx && !isExists) {
+			continue
+		}
+
+		if isIncr && isExists {
+			score += current
+			if math.IsNaN(score) {
+				c.modified(key, len(zset))
+				panic(errorReply("ERR resulting score is not a number (NaN)"))
+			}
+		}
+
+		if isExists && ((isGt && score <= current) || (isLt && score >= current)) {
+			continue
+		}
+
+		zset[member] = score
+		result = score
+
+		if !isExists {
+			added++
+		} else if score != current {
+			changed++
+		}
+	}
+
+	c.modified(key, len(zset))
+
+	if isIncr {
+		return result
+	}
+
+	if isCh {
+		return added + changed
+	}
+
+	return added
+}
+
+func zIncrBy(c *commandContext, args [][]byte) interface{} {
+	key, member := string(args[0]), string(args[2])
+	step := parseFloat(args[1])
+
+	zset := c.zsetValue(key, true)
+
+	score := zset[member] + step
+	if math.IsNaN(score) {
+		c.modified(key, len(zset))
+		panic(errorReply("ERR resulting score is not a number (NaN)"))
+	}
+
+	zset[member] = score
+	c.modified(key, len(zset))
+
+	return score
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZRANGE key start stop [BYSCORE | BYLEX] [REV] [LIMIT offset count] [WITHSCORES]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zRange(c *commandContext, args [][]byte) interface{} {
+	isByScore, isByLex, isReverse, isWithScores := false, false, false, false
+	offset, count := int64(0), int64(-1)
+	isLimit := false
+
+	for index := 3; index < len(args); index++ {
+		switch {
+		case isOption(args[index], "BYSCORE"):
+			isByScore = true
+		case isOption(args[index], "BYLEX"):
+			isByLex = true
+		case isOption(args[index], "REV"):
+			isReverse = true
+		case isOption(args[index], "WITHSCORES"):
+			isWithScores = true
+		case isOption(args[index], "LIMIT") && index+2 < len(args):
+			offset, count = parseInt(args[index+1]), parseInt(args[index+2])
+			isLimit = true
+			index += 2
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	if isByScore && isByLex {
+		panic(errSyntax)
+	}
+
+	if isLimit && !isByScore && !isByLex {
+		panic(errorReply("ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"))
+	}
+
+	if isByLex && isWithScores {
+		panic(errorReply("ERR syntax error, WITHSCORES not supported in combination with BYLEX"))
+	}
+
+	zset := c.zsetValue(string(args[0]), false)
+
+	if isByLex {
+		min, max := parseLexBound(args[1]), parseLexBound(args[2])
+		if isReverse {
+			min, max = max, min
+		}
+		return zReply(rangeByLex(zset, min, max, isReverse, offset, count), false)
+	}
+
+	if isByScore {
+		min, max := parseScoreBound(args[1]), parseScoreBound(args[2])
+		if isReverse {
+			min, max = max, min
+		}
+		return zReply(rangeByScore(zset, min, max, isReverse, offset, count), isWithScores)
+	}
+
+	return zReply(rangeByRank(zset, parseInt(args[1]), parseInt(args[2]), isReverse), isWithScores)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZREVRANGE key start stop [WITHSCORES]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zRangeByRank(isReverse bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		isWithScores := false
+		for _, arg := range args[3:] {
+			if !isOption(arg, "WITHSCORES") {
+				panic(errSyntax)
+			}
+			isWithScores = true
+		}
+
+		zset := c.zsetValue(string(args[0]), false)
+
+		return zReply(rangeByRank(zset, parseInt(args[1]), parseInt(args[2]), isReverse), isWithScores)
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZRANGEBYSCORE key min max | ZREVRANGEBYSCORE key max min [WITHSCORES] [LIMIT offset count]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zRangeByScore(isReverse bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		isWithScores := false
+		offset, count := int64(0), int64(-1)
+
+		for index := 3; index < len(args); index++ {
+			switch {
+			case isOption(args[index], "WITHSCORES"):
+				isWithScores = true
+			case isOption(args[index], "LIMIT") && index+2 < len(args):
+				offset, count = parseInt(args[index+1]), parseInt(args[index+2])
+				index += 2
+			default:
+				panic(errSyntax)
+			}
+		}
+
+		min, max := parseScoreBound(args[1]), parseScoreBound(args[2])
+		if isReverse {
+			min, max = max, min
+		}
+
+		zset := c.zsetValue(string(args[0]), false)
+
+		return zReply(rangeByScore(zset, min, max, isReverse, offset, count), isWithScores)
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZRANGEBYLEX key min max | ZREVRANGEBYLEX key max min [LIMIT offset count]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zRangeByLex(isReverse bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		offset, count := int64(0), int64(-1)
+
+		switch {
+		case len(args) == 6 && isOption(args[3], "LIMIT"):
+			offset, count = parseInt(args[4]), parseInt(args[5])
+		case len(args) != 3:
+			panic(errSyntax)
+		}
+
+		min, max := parseLexBound(args[1]), parseLexBound(args[2])
+		if isReverse {
+			min, max = max, min
+		}
+
+		zset := c.zsetValue(string(args[0]), false)
+
+		return zReply(rangeByLex(zset, min, max, isReverse, offset, count), false)
+	}
+}
+
+func rangeByRank(zset zsetValue, start, stop int64, isReverse bool) []zmember {
+	members := zset.sorted(isReverse)
+
+	from, to, isOk := normalizeRange(start, stop, len(members))
+	if !isOk {
+		return nil
+	}
+
+	return members[from : to+1]
+}
+
+func rangeByScore(zset zsetValue, min, max scoreBound, isReverse bool, offset, count int64) []zmember {
+	return rangeByFilter(zset, isReverse, offset, count, func(item zmember) bool {
+		return min.isAbove(item.score) && max.isBelow(item.score)
+	})
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 字典序区间仅在全部成员分数相同时有意义，与 Redis 一致不做校验
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func rangeByLex(zset zsetValue, min, max lexBound, isReverse bool, offset, count int64) []zmember {
+	return rangeByFilter(zset, isReverse, offset, count, func(item zmember) bool {
+		return min.isAbove(item.member) && max.isBelow(item.member)
+	})
+}
+
+func rangeByFilter(zset zsetValue, isReverse bool, offset, count int64, isMatch func(item zmember) bool) []zmember {
+	result := make([]zmember, 0)
+
+	if offset < 0 {
+		return result
+	}
+
+	for _, item := range zset.sorted(isReverse) {
+		if !isMatch(item) {
+			continue
+		}
+
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		if count == 0 {
+			break
+		}
+
+		result = append(result, item)
+		count--
+	}
+
+	return result
+}
+
+func zReply(members []zmember, isWithScores bool) interface{} {
+	replies := make([]interface{}, 0, len(members)*2)
+	for _, item := range members {
+		replies = append(replies, item.member)
+		if isWithScores {
+			replies = append(replies, item.score)
+		}
+	}
+
+	if isWithScores {
+		return pairsReply(replies)
+	}
+
+	return replies
+}
+
+func zRem(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+
+	zset := c.zsetValue(key, false)
+	if zset == nil {
+		return int64(0)
+	}
+
+	removed := int64(0)
+	for _, member := range args[1:] {
+		if _, isExists := zset[string(member)]; isExists {
+			delete(zset, string(member))
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		c.modified(key, len(zset))
+	}
+
+	return removed
+}
+
+func zRemRangeByScore(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	min, max := parseScoreBound(args[1]), parseScoreBound(args[2])
+
+	zset := c.zsetValue(key, false)
+
+	return zRemMembers(c, key, zset, rangeByScore(zset, min, max, false, 0, -1))
+}
+
+func zRemRangeByRank(c *commandContext, args [][]byte) interface{} {
+	key := string(args[0])
+	start, stop := parseInt(args[1]), parseInt(args[2])
+
+	zset := c.zsetValue(key, false)
+
+	return zRemMembers(c, key, zset, rangeByRank(zset, start, stop, false))
+}
+
+func zRemMembers(c *commandContext, key string, zset zsetValue, members []zmember) int64 {
+	for _, item := range members {
+		delete(zset, item.member)
+	}
+
+	if len(members) > 0 {
+		c.modified(key, len(zset))
+	}
+
+	return int64(len(members))
+}
+
+func zCard(c *commandContext, args [][]byte) interface{} {
+	return int64(len(c.zsetValue(string(args[0]), false)))
+}
+
+func zScore(c *commandContext, args [][]byte) interface{} {
+	score, isExists := c.zsetValue(string(args[0]), false)[string(args[1])]
+	if !isExists {
+		return nil
+	}
+
+	return score
+}
+
+func zRank(isReverse bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		zset := c.zsetValue(string(args[0]), false)
+
+		member := string(args[1])
+		if _, isExists := zset[member]; !isExists {
+			return nil
+		}
+
+		for rank, item := range zset.sorted(isReverse) {
+			if item.member == member {
+				return int64(rank)
+			}
+		}
+
+		return nil
+	}
+}
+
+func zCount(c *commandContext, args [][]byte) interface{} {
+	min, max := parseScoreBound(args[1]), parseScoreBound(args[2])
+
+	return int64(len(rangeByScore(c.zsetValue(string(args[0]), false), min, max, false, 0, -1)))
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZPOPMIN | ZPOPMAX key [count]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zPop(isMax bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		if len(args) > 2 {
+			panic(errSyntax)
+		}
+
+		count := int64(1)
+		if len(args) == 2 {
+			if count = parseInt(args[1]); count < 0 {
+				panic(errNotPositive)
+			}
+		}
+
+		key := string(args[0])
+		zset := c.zsetValue(key, false)
+
+		members := zset.sorted(isMax)
+		if count < int64(len(members)) {
+			members = members[:count]
+		}
+
+		zRemMembers(c, key, zset, members)
+
+		return zReply(members, true)
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * BZPOPMIN | BZPOPMAX key [key ...] timeout
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func bzPop(isMax bool) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		for _, key := range args {
+			zset := c.zsetValue(string(key), false)
+			if len(zset) == 0 {
+				continue
+			}
+
+			item := zset.sorted(isMax)[0]
+			zRemMembers(c, string(key), zset, []zmember{item})
+
+			return []interface{}{key, item.member, item.score}
+		}
+
+		return nullArray{}
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZUNIONSTORE | ZINTERSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]]
+ * [AGGREGATE SUM | MIN | MAX]，ZDIFFSTORE destination numkeys key [key ...]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zStore(operation int) func(c *commandContext, args [][]byte) interface{} {
+	return func(c *commandContext, args [][]byte) interface{} {
+		result := zsetOperation(c, operation, args[1:], operation != setDiff)
+
+		destination := string(args[0])
+		c.remove(destination)
+
+		if len(result) > 0 {
+			c.set(destination, result)
+		}
+
+		return int64(len(result))
+	}
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZDIFF numkeys key [key ...] [WITHSCORES]
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zDiff(c *commandContext, args [][]byte) interface{} {
+	isWithScores := false
+	if last := args[len(args)-1]; isOption(last, "WITHSCORES") {
+		isWithScores, args = true, args[:len(args)-1]
+	}
+
+	return zReply(zsetOperation(c, setDiff, args, false).sorted(false), isWithScores)
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 解析 numkeys key [key ...] 及可选的 WEIGHTS | AGGREGATE 并计算结果，
+ * 与 Redis 一致，普通集合作为分数为1的有序集合参与计算
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zsetOperation(c *commandContext, operation int, args [][]byte, isAggregate bool) zsetValue {
+	count := parseInt(args[0])
+	if count <= 0 {
+		panic(errorReply("ERR at least 1 input key is needed for this command"))
+	}
+
+	if count > int64(len(args)-1) {
+		panic(errSyntax)
+	}
+
+	keys, options := args[1:count+1], args[count+1:]
+
+	weights := make([]float64, count)
+	for index := range weights {
+		weights[index] = 1
+	}
+
+	aggregate := "SUM"
+
+	for index := 0; index < len(options); index++ {
+		switch {
+		case isAggregate && isOption(options[index], "WEIGHTS") && index+int(count) < len(options):
+			for offset := range weights {
+				weight, err := parseFloatString(string(options[index+1+offset]))
+				if err != nil {
+					panic(errorReply("ERR weight value is not a float"))
+				}
+				weights[offset] = weight
+			}
+			index += int(count)
+		case isAggregate && isOption(options[index], "AGGREGATE") && index+1 < len(options):
+			switch {
+			case isOption(options[index+1], "SUM"), isOption(options[index+1], "MIN"), isOption(options[index+1], "MAX"):
+				aggregate = string(options[index+1])
+			default:
+				panic(errSyntax)
+			}
+			index++
+		default:
+			panic(errSyntax)
+		}
+	}
+
+	sources := make([]zsetValue, 0, len(keys))
+	for _, key := range keys {
+		sources = append(sources, c.zsetOrSetValue(string(key)))
+	}
+
+	result := make(zsetValue)
+	for member, score := range sources[0] {
+		result[member] = weightedScore(score, weights[0])
+	}
+
+	for index, source := range sources[1:] {
+		weight := weights[index+1]
+
+		switch operation {
+		case setUnion:
+			for member, score := range source {
+				score = weightedScore(score, weight)
+				if current, isExists := result[member]; isExists {
+					score = aggregateScore(aggregate, current, score)
+				}
+				result[member] = score
+			}
+		case setInter:
+			for member, current := range result {
+				score, isExists := source[member]
+				if !isExists {
+					delete(result, member)
+					continue
+				}
+				result[member] = aggregateScore(aggregate, current, weightedScore(score, weight))
+			}
+		case setDiff:
+			for member := range source {
+				delete(result, member)
+			}
+		}
+	}
+
+	return result
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * 读取有序集合，普通集合转换为分数为1的有序集合
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func (c *commandContext) zsetOrSetValue(key string) zsetValue {
+	item := c.lookup(key)
+	if item == nil {
+		return nil
+	}
+
+	switch value := item.value.(type) {
+	case zsetValue:
+		return value
+	case setValue:
+		zset := make(zsetValue, len(value))
+		for member := range value {
+			zset[member] = 1
+		}
+		return zset
+	}
+
+	panic(errWrongType)
+}
+
+func weightedScore(score, weight float64) float64 {
+	// 与 Redis 一致，inf * 0 视为 0
+	if score == 0 || weight == 0 {
+		return 0
+	}
+
+	return score * weight
+}
+
+func aggregateScore(aggregate string, left, right float64) float64 {
+	switch {
+	case isOption([]byte(aggregate), "MIN"):
+		return math.Min(left, right)
+	case isOption([]byte(aggregate), "MAX"):
+		return math.Max(left, right)
+	}
+
+	// 与 Redis 一致，inf + -inf 视为 0
+	if sum := left + right; !math.IsNaN(sum) {
+		return sum
+	}
+
+	return 0
+}
+
+func zMScore(c *commandContext, args [][]byte) interface{} {
+	zset := c.zsetValue(string(args[0]), false)
+
+	replies := make([]interface{}, 0, len(args)-1)
+	for _, member := range args[1:] {
+		if score, isExists := zset[string(member)]; isExists {
+			replies = append(replies, score)
+		} else {
+			replies = append(replies, nil)
+		}
+	}
+
+	return replies
+}
+
+/* ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+ * ZRANDMEMBER key [count [WITHSCORES]]：count 为负数时允许重复
+ * ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++ */
+func zRandMember(c *commandContext, args [][]byte) interface{} {
+	isWithScores := len(args) == 3
+	if len(args) > 3 || (isWithScores && !isOption(args[2], "WITHSCORES")) {
+		panic(errSyntax)
+	}
+
+	count, isCount := int64(1), len(args) >= 2
+	if isCount {
+		count = parseInt(args[1])
+	}
+
+	zset := c.zsetValue(string(args[0]), false)
+	if len(zset) == 0 {
+		if isCount {
+			return []interface{}{}
+		}
+		return nil
+	}
+
+	isRepeat := count < 0
+	if isRepeat {
+		count = -count
+	}
+
+	candidates := make(setValue, len(zset))
+	for member := range zset {
+		candidates[member] = struct{}{}
+	}
+
+	members := c.randomMembers(candidates, count, isRepeat)
+	if !isCount {
+		return members[0]
+	}
+
+	items := make([]zmember, 0, len(members))
+	for _, member := range members {
+		items = append(items, zmember{member: member, score: zset[member]})
+	}
+
+	return zReply(items, isWithScores)
+}